@@ -1,11 +1,19 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math"
+	"os"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
 
@@ -18,10 +26,11 @@ type SmartContract struct {
 type Zakat struct {
 	ID             string  `json:"ID"`                     // Format: ZKT-{ORG}-{YYYY}{MM}-{COUNTER}
 	ProgramID      string  `json:"programID,omitempty"`    // Which donation program
-	Muzakki        string  `json:"muzakki"`                // Donor's name
+	Muzakki        string  `json:"muzakki"`                // Donor's name, or display alias for anonymous donations
+	IsAnonymous    bool    `json:"isAnonymous,omitempty"`  // Donor asked to be shown publicly by alias only
 	Amount         float64 `json:"amount"`                 // Amount in IDR
 	Type           string  `json:"type"`                   // "fitrah" or "maal"
-	PaymentMethod  string  `json:"paymentMethod"`          // "transfer", "ewallet", "credit_card"
+	PaymentMethod  string  `json:"paymentMethod"`          // "transfer", "ewallet", "qris", "credit_card"
 	Status         string  `json:"status"`                 // "pending", "collected", "distributed"
 	Organization   string  `json:"organization"`           // Collecting organization
 	ReferralCode   string  `json:"referralCode,omitempty"` // Officer's referral code (optional)
@@ -30,12 +39,108 @@ type Zakat struct {
 	ValidatedBy    string  `json:"validatedBy"`            // Admin who validated
 	ValidationDate string  `json:"validationDate"`         // When payment was validated
 	Mustahik       string  `json:"mustahik"`               // Recipient's name (if distributed)
-	Distribution   float64 `json:"distribution"`           // Distributed amount
+	Distribution   float64 `json:"distribution"`           // Distributed amount, including program allocations
 	DistributedAt  string  `json:"distributedAt"`          // Distribution timestamp
 	DistributionID string  `json:"distributionID"`         // Unique ID for the distribution event
 	DistributedBy  string  `json:"distributedBy"`          // Admin/Officer who performed the distribution
+
+	Reallocations []ZakatReallocation `json:"reallocations,omitempty"` // History of program reallocations
+	Allocations   []ZakatAllocation   `json:"allocations,omitempty"`   // Shares of program distributions paid from this zakat
+
+	ReceiptHash    string `json:"receiptHash,omitempty"`    // SHA-256 of the receipt fields, set on validation
+	ValidationTxID string `json:"validationTxID,omitempty"` // Transaction that validated the payment
+	RequestKey     string `json:"requestKey,omitempty"`     // Client request key the zakat was submitted under
+
+	// Zakat fitrah only
+	Souls        int      `json:"souls,omitempty"`        // Number of people (jiwa) the fitrah is paid for
+	SoulNames    []string `json:"soulNames,omitempty"`    // Names of those people (optional)
+	FitrahRegion string   `json:"fitrahRegion,omitempty"` // Region whose rate was applied
+	HijriYear    int      `json:"hijriYear,omitempty"`    // Hijri year whose rate was applied
+	FitrahRate   float64  `json:"fitrahRate,omitempty"`   // Rate per soul in IDR at submission
+
+	// Donations in a foreign currency or in gold/silver; Amount holds the IDR equivalent
+	Denomination   string  `json:"denomination,omitempty"`   // e.g. "USD", "SAR", "XAU_G" (grams of gold)
+	OriginalAmount float64 `json:"originalAmount,omitempty"` // Amount in Denomination units
+	ExchangeRate   float64 `json:"exchangeRate,omitempty"`   // IDR per unit applied at submission
+	ExchangeRateID string  `json:"exchangeRateID,omitempty"` // Ledger key of the rate entry applied
+}
+
+// ZakatAllocation records the part of a program distribution paid from one zakat
+type ZakatAllocation struct {
+	DistributionID string  `json:"distributionID"` // Program distribution the share belongs to
+	Mustahik       string  `json:"mustahik"`       // Recipient of the program distribution
+	Amount         float64 `json:"amount"`         // Share drawn from this zakat
+	DistributedAt  string  `json:"distributedAt"`  // Distribution timestamp
+}
+
+// ProgramDistribution is a distribution paid from a program's pooled funds and allocated
+// to the program's collected zakat oldest first
+type ProgramDistribution struct {
+	ID            string                   `json:"ID"`            // Distribution ID
+	ProgramID     string                   `json:"programID"`     // Program the funds came from
+	Mustahik      string                   `json:"mustahik"`      // Recipient's name
+	Amount        float64                  `json:"amount"`        // Total distributed amount
+	DistributedAt string                   `json:"distributedAt"` // Distribution timestamp
+	DistributedBy string                   `json:"distributedBy"` // Admin/Officer who performed the distribution
+	Allocations   []DistributionAllocation `json:"allocations"`   // Zakat the amount was drawn from
+}
+
+// DistributionAllocation records how much of a program distribution was drawn from one zakat
+type DistributionAllocation struct {
+	ZakatID string  `json:"zakatID"`
+	Amount  float64 `json:"amount"`
+}
+
+// ZakatReallocation records a move of a zakat donation from one program to another
+type ZakatReallocation struct {
+	FromProgramID string `json:"fromProgramID"` // Program the zakat was moved from (empty if none)
+	ToProgramID   string `json:"toProgramID"`   // Program the zakat was moved to
+	Reason        string `json:"reason"`        // Why the zakat was reallocated
+	ApprovedBy    string `json:"approvedBy"`    // Admin who approved the reallocation
+	ReallocatedAt string `json:"reallocatedAt"` // Reallocation timestamp
+}
+
+// ZakatSubmission is the input for SubmitZakat. It carries the same fields as AddZakat
+// plus optional attributes that do not fit AddZakat's positional signature.
+type ZakatSubmission struct {
+	ID            string  `json:"ID"`
+	ProgramID     string  `json:"programID,omitempty"`
+	Muzakki       string  `json:"muzakki,omitempty"` // Donor's name, or display alias when Anonymous is set
+	Amount        float64 `json:"amount"`
+	Type          string  `json:"type"`
+	PaymentMethod string  `json:"paymentMethod"`
+	Organization  string  `json:"organization"`
+	ReferralCode  string  `json:"referralCode,omitempty"`
+	Anonymous     bool    `json:"anonymous,omitempty"` // Hide the donor's name publicly
+
+	// Zakat fitrah only. Region defaults to the organization's region and HijriYear to the current year.
+	// When Souls is 0 it is derived from the amount, which must then be an exact multiple of the rate.
+	Souls     int      `json:"souls,omitempty"`
+	SoulNames []string `json:"soulNames,omitempty"`
+	Region    string   `json:"region,omitempty"`
+	HijriYear int      `json:"hijriYear,omitempty"`
+
+	// Zakat maal paid in another currency or in gold/silver. Amount is then derived from the latest
+	// published exchange rate; if it is also given it must match the derived IDR amount.
+	Denomination   string  `json:"denomination,omitempty"`
+	OriginalAmount float64 `json:"originalAmount,omitempty"`
+
+	// Optional client-chosen key that makes the submission idempotent: resubmitting the same
+	// payload under the same key returns the original zakat, whatever the ID of the retry.
+	RequestKey string `json:"requestKey,omitempty"`
+}
+
+// ZakatRequest records the zakat created under a client request key
+type ZakatRequest struct {
+	RequestKey  string `json:"requestKey"`
+	ZakatID     string `json:"zakatID"`
+	PayloadHash string `json:"payloadHash"` // SHA-256 of the submission without its ID
+	CreatedAt   string `json:"createdAt"`
 }
 
+// defaultAnonymousAlias is the public name used for anonymous donations without a chosen alias
+const defaultAnonymousAlias = "Hamba Allah"
+
 // DonationProgram describes a donation campaign/program
 type DonationProgram struct {
 	ID          string  `json:"ID"`          // Format: PROG-{YYYY}-{COUNTER}
@@ -51,6 +156,34 @@ type DonationProgram struct {
 	CreatedAt   string  `json:"createdAt"`   // Creation timestamp
 }
 
+// ProgramSummary is the campaign view of a program's progress, computed from its zakat
+type ProgramSummary struct {
+	ProgramID         string            `json:"programID"`
+	Name              string            `json:"name"`
+	Status            string            `json:"status"`
+	Target            float64           `json:"target"`
+	Collected         float64           `json:"collected"`
+	Distributed       float64           `json:"distributed"`
+	StartDate         string            `json:"startDate"`
+	EndDate           string            `json:"endDate"`
+	ProgressPercent   float64           `json:"progressPercent"`   // Collected as a percentage of Target
+	DistributionRatio float64           `json:"distributionRatio"` // Share of Collected already distributed (0-1)
+	DaysRemaining     int               `json:"daysRemaining"`     // Whole days until EndDate, 0 once it has passed
+	DonorCount        int               `json:"donorCount"`        // Distinct donors of paid zakat
+	DonationCount     int               `json:"donationCount"`     // Paid (collected or distributed) zakat
+	CountByType       map[string]int    `json:"countByType"`       // Paid zakat per type ("fitrah", "maal")
+	PendingCount      int               `json:"pendingCount"`      // Zakat awaiting payment validation
+	DailyCollections  []DailyCollection `json:"dailyCollections"`  // Paid zakat per validation day, oldest first
+	AsOf              string            `json:"asOf"`              // Transaction timestamp the summary was computed at
+}
+
+// DailyCollection is the paid zakat of one day (UTC) in a program summary
+type DailyCollection struct {
+	Date   string  `json:"date"`   // YYYY-MM-DD
+	Amount float64 `json:"amount"` // IDR
+	Count  int     `json:"count"`
+}
+
 // Officer describes a petugas/officer with referral tracking
 type Officer struct {
 	ID             string  `json:"ID"`             // Format: OFF-{YYYY}-{COUNTER}
@@ -60,30 +193,187 @@ type Officer struct {
 	CommissionRate float64 `json:"commissionRate"` // Commission percentage
 	Status         string  `json:"status"`         // "active", "inactive"
 	CreatedAt      string  `json:"createdAt"`      // Registration timestamp
+
+	Phone                 string             `json:"phone,omitempty"`                 // Contact phone number
+	Email                 string             `json:"email,omitempty"`                 // Contact email address
+	PreviousReferralCodes []string           `json:"previousReferralCodes,omitempty"` // Codes retired by RotateReferralCode
+	CommissionHistory     []CommissionChange `json:"commissionHistory,omitempty"`     // Commission rate changes, oldest first
+	UpdatedAt             string             `json:"updatedAt,omitempty"`             // Last profile/commission/referral update
+}
+
+// OfficerPerformance is an officer's referral activity for one organization over a period
+type OfficerPerformance struct {
+	OfficerID       string  `json:"officerID"`
+	Name            string  `json:"name"`
+	ReferralCode    string  `json:"referralCode"` // Current code; referrals under previous codes are included
+	Organization    string  `json:"organization"`
+	StartDate       string  `json:"startDate"`       // YYYY-MM-DD, inclusive
+	EndDate         string  `json:"endDate"`         // YYYY-MM-DD, inclusive
+	DonationCount   int     `json:"donationCount"`   // Referred zakat submitted in the period
+	CollectedCount  int     `json:"collectedCount"`  // Of which paid (collected or distributed)
+	PendingCount    int     `json:"pendingCount"`    // Of which still awaiting payment validation
+	AmountReferred  float64 `json:"amountReferred"`  // IDR, all referred zakat
+	AmountCollected float64 `json:"amountCollected"` // IDR, paid referred zakat
+	ConversionRate  float64 `json:"conversionRate"`  // CollectedCount / DonationCount (0-1)
+	AverageTicket   float64 `json:"averageTicket"`   // AmountCollected / CollectedCount
+	Rank            int     `json:"rank"`            // 1 = highest AmountCollected; 0 without referrals
+	RankedOfficers  int     `json:"rankedOfficers"`  // Officers with referrals in the period
+}
+
+// CommissionChange records a commission rate that applies from EffectiveDate onwards
+type CommissionChange struct {
+	Rate          float64 `json:"rate"`          // Commission rate (e.g. 0.05 for 5%)
+	EffectiveDate string  `json:"effectiveDate"` // When the rate starts to apply
+	ChangedBy     string  `json:"changedBy"`     // Admin who recorded the change
+	ChangedAt     string  `json:"changedAt"`     // When the change was recorded
+}
+
+// FitrahRate is the zakat fitrah amount due per soul for a region in a Hijri year
+type FitrahRate struct {
+	ID            string  `json:"ID"`            // Format: FITRAH-{REGION}-{HIJRIYEAR}
+	Region        string  `json:"region"`        // Region code, e.g. "MALANG"
+	HijriYear     int     `json:"hijriYear"`     // e.g. 1446
+	RatePerSoul   float64 `json:"ratePerSoul"`   // Amount per soul in IDR
+	RiceKgPerSoul float64 `json:"riceKgPerSoul"` // Rice equivalent per soul in kg (e.g. 2.5)
+	SetBy         string  `json:"setBy"`         // Admin who set the rate
+	UpdatedAt     string  `json:"updatedAt"`     // When the rate was last set
+}
+
+// ExchangeRate is the IDR value of one unit of a currency or commodity, published by an authorized rate feed
+type ExchangeRate struct {
+	ID            string  `json:"ID"`            // Format: RATE-{DENOMINATION}-{YYYY-MM-DD}
+	Denomination  string  `json:"denomination"`  // ISO 4217 code (e.g. "USD") or commodity unit ("XAU_G", "XAG_G")
+	RateIDR       float64 `json:"rateIDR"`       // IDR per unit
+	EffectiveDate string  `json:"effectiveDate"` // Day the rate applies from (YYYY-MM-DD)
+	Source        string  `json:"source"`        // Feed the rate was taken from
+	PublishedBy   string  `json:"publishedBy"`   // Client identity of the publisher
+	PublishedAt   string  `json:"publishedAt"`   // When the rate was published
+}
+
+// ReceiptVerification is the result of checking a donation receipt against the ledger
+type ReceiptVerification struct {
+	ZakatID        string  `json:"zakatID"`
+	Valid          bool    `json:"valid"`
+	Reason         string  `json:"reason,omitempty"` // Why the receipt is not valid
+	Status         string  `json:"status,omitempty"`
+	Amount         float64 `json:"amount,omitempty"`
+	Organization   string  `json:"organization,omitempty"`
+	ValidationDate string  `json:"validationDate,omitempty"`
+	ValidationTxID string  `json:"validationTxID,omitempty"`
+}
+
+// DenominationTotal sums donations of one denomination in a report
+type DenominationTotal struct {
+	OriginalAmount float64 `json:"originalAmount"` // Total in the denomination's own units
+	AmountIDR      float64 `json:"amountIDR"`      // Total IDR equivalent
+	Count          int     `json:"count"`          // Number of donations
+}
+
+const (
+	// baseDenomination is the currency all zakat amounts and reports are kept in
+	baseDenomination = "IDR"
+	// ratePublisherAttribute is the client certificate attribute that authorizes publishing exchange rates
+	ratePublisherAttribute = "zakat.ratePublisher"
+	// maxExchangeRateAge is how old the latest rate may be before donations in that denomination are refused
+	maxExchangeRateAge = 7 * 24 * time.Hour
+)
+
+// Pledge describes a muzakki's commitment to pay a fixed zakat amount on a recurring schedule
+type Pledge struct {
+	ID             string             `json:"ID"`                    // Format: PLG-YDSF-{MLG|JTM}-{TIMESTAMP}-{SEQUENCE}
+	Muzakki        string             `json:"muzakki"`               // Donor's name
+	Amount         float64            `json:"amount"`                // Amount pledged per period in IDR
+	Type           string             `json:"type"`                  // "fitrah" or "maal"
+	Schedule       string             `json:"schedule"`              // "monthly", "yearly", "ramadan"
+	ProgramID      string             `json:"programID,omitempty"`   // Program the pledged zakat goes to (optional)
+	Organization   string             `json:"organization"`          // Collecting organization
+	Status         string             `json:"status"`                // "active", "cancelled"
+	StartDate      string             `json:"startDate"`             // First scheduled payment date
+	TotalFulfilled float64            `json:"totalFulfilled"`        // Sum of collected zakat recorded against the pledge
+	Fulfilments    []PledgeFulfilment `json:"fulfilments,omitempty"` // Paid periods, oldest first
+	CreatedAt      string             `json:"createdAt"`             // Creation timestamp
+	CancelledAt    string             `json:"cancelledAt,omitempty"` // Cancellation timestamp
+	CancelReason   string             `json:"cancelReason,omitempty"`
+}
+
+// PledgeFulfilment links a collected zakat to the pledge period it pays for
+type PledgeFulfilment struct {
+	ZakatID     string  `json:"zakatID"`     // Zakat transaction that paid the period
+	Period      string  `json:"period"`      // Period label, e.g. "2025-03", "2025" or "1446H"
+	Amount      float64 `json:"amount"`      // Amount of the zakat transaction
+	FulfilledAt string  `json:"fulfilledAt"` // When the fulfilment was recorded
+}
+
+// Error codes carried by ContractError
+const (
+	ErrCodeNotFound     = "NOT_FOUND"
+	ErrCodeInvalidInput = "INVALID_INPUT"
+	ErrCodeInvalidState = "INVALID_STATE"
+	ErrCodeConflict     = "CONFLICT"
+	ErrCodeUnauthorized = "UNAUTHORIZED"
+)
+
+// ContractError is a typed contract failure. Its message is a JSON envelope so that
+// clients can recover the code and offending field from the transaction error
+type ContractError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"` // Argument or JSON field that failed validation
+}
+
+func (e *ContractError) Error() string {
+	var buf strings.Builder
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(e); err != nil {
+		return e.Message
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+func newInvalidInputError(field string, format string, args ...interface{}) error {
+	return &ContractError{Code: ErrCodeInvalidInput, Message: fmt.Sprintf(format, args...), Field: field}
+}
+
+func newNotFoundError(format string, args ...interface{}) error {
+	return &ContractError{Code: ErrCodeNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+func newInvalidStateError(format string, args ...interface{}) error {
+	return &ContractError{Code: ErrCodeInvalidState, Message: fmt.Sprintf(format, args...)}
+}
+
+func newConflictError(format string, args ...interface{}) error {
+	return &ContractError{Code: ErrCodeConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+func newUnauthorizedError(format string, args ...interface{}) error {
+	return &ContractError{Code: ErrCodeUnauthorized, Message: fmt.Sprintf(format, args...)}
 }
 
 // Enhanced validation functions supporting nanosecond timestamp-based IDs for true uniqueness
 func validateZakatID(id string) error {
 	if len(id) == 0 {
-		return fmt.Errorf("zakat ID cannot be empty")
+		return newInvalidInputError("zakatID", "zakat ID cannot be empty")
 	}
 	
-	// New format: ZKT-YDSF-{MLG|JTM}-{UNIXTIMESTAMPNANO}-{SEQUENCE}
-	// Also supports legacy format for backward compatibility
+	// Numbered format: ZKT-YDSF-{MLG|JTM}-{YYYYMM}-{NUMBER}
+	// Also supports the timestamped format ZKT-YDSF-{MLG|JTM}-{UNIXTIMESTAMPNANO}-{SEQUENCE}
+	// and legacy formats for backward compatibility
 	pattern := `^ZKT-YDSF-(MLG|JTM)-\d+-\d+$`
 	matched, err := regexp.MatchString(pattern, id)
 	if err != nil {
 		return fmt.Errorf("error validating zakat ID format: %v", err)
 	}
 	if !matched {
-		return fmt.Errorf("invalid zakat ID format. Expected format: ZKT-YDSF-{MLG|JTM}-{TIMESTAMP}-{SEQUENCE} (example: ZKT-YDSF-MLG-1735689000000000000-0001)")
+		return newInvalidInputError("zakatID", "invalid zakat ID format. Expected format: ZKT-YDSF-{MLG|JTM}-{YYYYMM}-{NUMBER} (example: ZKT-YDSF-MLG-202610-000123)")
 	}
 	return nil
 }
 
 func validateProgramID(id string) error {
 	if len(id) == 0 {
-		return fmt.Errorf("program ID cannot be empty")
+		return newInvalidInputError("programID", "program ID cannot be empty")
 	}
 	
 	// New format: PROG-{TYPE}-{UNIXTIMESTAMPNANO}-{SEQUENCE}
@@ -94,14 +384,14 @@ func validateProgramID(id string) error {
 		return fmt.Errorf("error validating program ID format: %v", err)
 	}
 	if !matched {
-		return fmt.Errorf("invalid program ID format. Expected format: PROG-{TYPE}-{TIMESTAMP}-{SEQUENCE} (example: PROG-2024-1735689000000000000-0001)")
+		return newInvalidInputError("programID", "invalid program ID format. Expected format: PROG-{TYPE}-{TIMESTAMP}-{SEQUENCE} (example: PROG-2024-1735689000000000000-0001)")
 	}
 	return nil
 }
 
 func validateOfficerID(id string) error {
 	if len(id) == 0 {
-		return fmt.Errorf("officer ID cannot be empty")
+		return newInvalidInputError("officerID", "officer ID cannot be empty")
 	}
 	
 	// New format: OFF-{TYPE}-{UNIXTIMESTAMPNANO}-{SEQUENCE}
@@ -112,7 +402,40 @@ func validateOfficerID(id string) error {
 		return fmt.Errorf("error validating officer ID format: %v", err)
 	}
 	if !matched {
-		return fmt.Errorf("invalid officer ID format. Expected format: OFF-{TYPE}-{TIMESTAMP}-{SEQUENCE} (example: OFF-2024-1735689000000000000-0001)")
+		return newInvalidInputError("officerID", "invalid officer ID format. Expected format: OFF-{TYPE}-{TIMESTAMP}-{SEQUENCE} (example: OFF-2024-1735689000000000000-0001)")
+	}
+	return nil
+}
+
+func validatePledgeID(id string) error {
+	if len(id) == 0 {
+		return newInvalidInputError("pledgeID", "pledge ID cannot be empty")
+	}
+
+	// Format: PLG-YDSF-{MLG|JTM}-{UNIXTIMESTAMPNANO}-{SEQUENCE}
+	pattern := `^PLG-YDSF-(MLG|JTM)-\d+-\d+$`
+	matched, err := regexp.MatchString(pattern, id)
+	if err != nil {
+		return fmt.Errorf("error validating pledge ID format: %v", err)
+	}
+	if !matched {
+		return newInvalidInputError("pledgeID", "invalid pledge ID format. Expected format: PLG-YDSF-{MLG|JTM}-{TIMESTAMP}-{SEQUENCE} (example: PLG-YDSF-MLG-1735689000000000000-0001)")
+	}
+	return nil
+}
+
+func validateReferralCode(code string) error {
+	if len(code) == 0 {
+		return newInvalidInputError("referralCode", "referral code cannot be empty")
+	}
+
+	// Referral codes are embedded in rich queries, so only plain identifiers are allowed
+	matched, err := regexp.MatchString(`^[A-Za-z0-9_-]{3,32}$`, code)
+	if err != nil {
+		return fmt.Errorf("error validating referral code format: %v", err)
+	}
+	if !matched {
+		return newInvalidInputError("referralCode", "invalid referral code format. Use 3-32 letters, digits, '-' or '_' (example: REF001)")
 	}
 	return nil
 }
@@ -120,63 +443,189 @@ func validateOfficerID(id string) error {
 func validateTimestamp(timestamp string) error {
 	_, err := time.Parse(time.RFC3339, timestamp)
 	if err != nil {
-		return fmt.Errorf("invalid timestamp format. Expected ISO 8601 format")
+		return newInvalidInputError("timestamp", "invalid timestamp format. Expected ISO 8601 format")
 	}
 	return nil
 }
 
 func validateZakatType(zakatType string) error {
 	if zakatType != "fitrah" && zakatType != "maal" {
-		return fmt.Errorf("invalid zakat type. Must be either 'fitrah' or 'maal'")
+		return newInvalidInputError("type", "invalid zakat type. Must be either 'fitrah' or 'maal'")
 	}
 	return nil
 }
 
 func validatePaymentMethod(method string) error {
-	validMethods := []string{"transfer", "ewallet", "credit_card", "debit_card", "cash"}
+	validMethods := []string{"transfer", "ewallet", "qris", "credit_card", "debit_card", "cash"}
 	for _, valid := range validMethods {
 		if method == valid {
 			return nil
 		}
 	}
-	return fmt.Errorf("invalid payment method. Must be one of: transfer, ewallet, credit_card, debit_card, cash")
+	return newInvalidInputError("paymentMethod", "invalid payment method. Must be one of: transfer, ewallet, qris, credit_card, debit_card, cash")
 }
 
 func validateStatus(status string) error {
 	if status != "pending" && status != "collected" && status != "distributed" {
-		return fmt.Errorf("invalid status. Must be 'pending', 'collected', or 'distributed'")
+		return newInvalidInputError("status", "invalid status. Must be 'pending', 'collected', or 'distributed'")
 	}
 	return nil
 }
 
 func validateProgramStatus(status string) error {
 	if status != "active" && status != "completed" && status != "suspended" {
-		return fmt.Errorf("invalid program status. Must be 'active', 'completed', or 'suspended'")
+		return newInvalidInputError("status", "invalid program status. Must be 'active', 'completed', or 'suspended'")
 	}
 	return nil
 }
 
 func validateOfficerStatus(status string) error {
 	if status != "active" && status != "inactive" {
-		return fmt.Errorf("invalid officer status. Must be 'active' or 'inactive'")
+		return newInvalidInputError("status", "invalid officer status. Must be 'active' or 'inactive'")
+	}
+	return nil
+}
+
+func validatePledgeSchedule(schedule string) error {
+	if schedule != "monthly" && schedule != "yearly" && schedule != "ramadan" {
+		return newInvalidInputError("schedule", "invalid pledge schedule. Must be 'monthly', 'yearly', or 'ramadan'")
+	}
+	return nil
+}
+
+func validateFitrahRegion(region string) error {
+	matched, err := regexp.MatchString(`^[A-Z][A-Z0-9_]{1,31}$`, region)
+	if err != nil {
+		return fmt.Errorf("error validating region format: %v", err)
+	}
+	if !matched {
+		return newInvalidInputError("region", "invalid region '%s'. Use 2-32 uppercase letters, digits or '_' (example: MALANG)", region)
+	}
+	return nil
+}
+
+func validateHijriYear(year int) error {
+	if year < 1400 || year > 1600 {
+		return newInvalidInputError("hijriYear", "invalid Hijri year %d", year)
+	}
+	return nil
+}
+
+func validateDenomination(denomination string) error {
+	// ISO 4217 currency codes, or grams of gold/silver
+	matched, err := regexp.MatchString(`^([A-Z]{3}|XAU_G|XAG_G)$`, denomination)
+	if err != nil {
+		return fmt.Errorf("error validating denomination format: %v", err)
+	}
+	if !matched {
+		return newInvalidInputError("denomination", "invalid denomination '%s'. Use an ISO 4217 currency code (example: USD) or XAU_G/XAG_G for grams of gold/silver", denomination)
 	}
 	return nil
 }
 
 func validateAmount(amount float64) error {
 	if amount <= 0 {
-		return fmt.Errorf("invalid amount. Must be greater than 0")
+		return newInvalidInputError("amount", "invalid amount. Must be greater than 0")
 	}
 	return nil
 }
 
 func validateOrganization(org string) error {
 	if org != "YDSF Malang" && org != "YDSF Jatim" {
-		return fmt.Errorf("invalid organization. Must be either 'YDSF Malang' or 'YDSF Jatim'")
+		return newInvalidInputError("organization", "invalid organization. Must be either 'YDSF Malang' or 'YDSF Jatim'")
+	}
+	return nil
+}
+
+func validateRequestKey(requestKey string) error {
+	matched, err := regexp.MatchString(`^[A-Za-z0-9._:-]{1,128}$`, requestKey)
+	if err != nil {
+		return fmt.Errorf("error validating request key format: %v", err)
+	}
+	if !matched {
+		return newInvalidInputError("requestKey", "invalid request key '%s'. Use up to 128 letters, digits, '.', '_', ':' or '-'", requestKey)
 	}
 	return nil
 }
 
+// requestKeyIndex returns the world state key recording which zakat a request key created
+func requestKeyIndex(requestKey string) string {
+	return "REQKEY-" + requestKey
+}
+
+// referralCodeIndex returns the world state key recording which officer a referral code belongs to.
+// Reading it before claiming a code makes concurrent claims conflict at commit, which a rich query
+// cannot, since rich queries are not re-executed at validation.
+func referralCodeIndex(referralCode string) string {
+	return "REFCODE-" + referralCode
+}
+
+// submissionHash returns the hex SHA-256 of a submission without its ID, so retries that
+// generated a fresh ID still match the original payload
+func submissionHash(submission ZakatSubmission) (string, error) {
+	submission.ID = ""
+	submissionJSON, err := json.Marshal(submission)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal zakat submission: %w", err)
+	}
+	sum := sha256.Sum256(submissionJSON)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// fitrahRateKey returns the world state key of a region's fitrah rate for a Hijri year
+func fitrahRateKey(region string, hijriYear int) string {
+	return fmt.Sprintf("FITRAH-%s-%d", region, hijriYear)
+}
+
+// exchangeRateKey returns the world state key of a denomination's rate for a day
+func exchangeRateKey(denomination string, effectiveDate string) string {
+	return fmt.Sprintf("RATE-%s-%s", denomination, effectiveDate)
+}
+
+// programDistributionKey returns the world state key of a program distribution
+func programDistributionKey(programID string, distributionID string) string {
+	return fmt.Sprintf("PDIST-%s-%s", programID, distributionID)
+}
+
+// computeReceiptHash returns the hex SHA-256 of the fields printed on a zakat's receipt:
+// ID|amount|validationDate|organization|receiptNumber, with the amount in IDR to 2 decimals
+func computeReceiptHash(zakat Zakat) string {
+	receipt := fmt.Sprintf("%s|%.2f|%s|%s|%s", zakat.ID, zakat.Amount, zakat.ValidationDate, zakat.Organization, zakat.ReceiptNumber)
+	sum := sha256.Sum256([]byte(receipt))
+	return hex.EncodeToString(sum[:])
+}
+
+// regionForOrganization returns the fitrah rate region an organization collects in
+func regionForOrganization(org string) string {
+	switch org {
+	case "YDSF Jatim":
+		return "JATIM"
+	default:
+		return "MALANG"
+	}
+}
+
+// hijriYearOf returns the year of the tabular Islamic calendar for a Gregorian date.
+// It can differ by a day around 1 Muharram from the sighting-based calendar.
+func hijriYearOf(t time.Time) int {
+	y, m, d := t.Date()
+	a := (14 - int(m)) / 12
+	yy := y + 4800 - a
+	mm := int(m) + 12*a - 3
+	jdn := d + (153*mm+2)/5 + 365*yy + yy/4 - yy/100 + yy/400 - 32045 // Julian day number
+	return (30*(jdn-1948440) + 10646) / 10631                         // 1948440: 1 Muharram 1 AH
+}
+
+// txTime returns the transaction's timestamp. Every endorsing peer sees the same one, unlike its
+// own clock, so anything written or checked against the current time must use it.
+func txTime(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	return txTimestamp.AsTime().UTC(), nil
+}
+
 // ID Generation functions with nanosecond timestamp for true uniqueness
 func generateZakatID(orgCode string, sequence int) string {
 	nanoTimestamp := time.Now().UnixNano()
@@ -265,6 +714,9 @@ func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface)
 	if err != nil {
 		return fmt.Errorf("failed to put sample officer %s: %w", officer.ID, err)
 	}
+	if err := claimReferralCode(ctx, officer.ReferralCode, officer.ID); err != nil {
+		return err
+	}
 	fmt.Printf("Successfully created sample officer: %s\n", officer.ID)
 
 	fmt.Println("Ledger initialization complete.")
@@ -293,7 +745,7 @@ func (s *SmartContract) CreateProgram(ctx contractapi.TransactionContextInterfac
 		return fmt.Errorf("failed to check program existence: %v", err)
 	}
 	if exists != nil {
-		return fmt.Errorf("program %s already exists", id)
+		return newConflictError("program %s already exists", id)
 	}
 
 	program := DonationProgram{
@@ -325,7 +777,7 @@ func (s *SmartContract) GetProgram(ctx contractapi.TransactionContextInterface,
 		return DonationProgram{}, fmt.Errorf("failed to read program: %v", err)
 	}
 	if programJSON == nil {
-		return DonationProgram{}, fmt.Errorf("program %s does not exist", id)
+		return DonationProgram{}, newNotFoundError("program %s does not exist", id)
 	}
 
 	var program DonationProgram
@@ -367,29 +819,126 @@ func (s *SmartContract) GetAllPrograms(ctx contractapi.TransactionContextInterfa
 	return programs, nil
 }
 
+// GetProgramSummary returns a program's campaign progress: donors, donations by type,
+// daily collections and how much of the collected funds has been distributed.
+// Only paid (collected or distributed) zakat is counted; days remaining are measured
+// from the transaction timestamp so every peer computes the same summary.
+func (s *SmartContract) GetProgramSummary(ctx contractapi.TransactionContextInterface, programID string) (ProgramSummary, error) {
+	if err := validateProgramID(programID); err != nil {
+		return ProgramSummary{}, err
+	}
+
+	program, err := s.GetProgram(ctx, programID)
+	if err != nil {
+		return ProgramSummary{}, err
+	}
+
+	asOf, err := txTime(ctx)
+	if err != nil {
+		return ProgramSummary{}, err
+	}
+
+	zakats, err := s.GetZakatByProgram(ctx, programID)
+	if err != nil {
+		return ProgramSummary{}, err
+	}
+
+	summary := ProgramSummary{
+		ProgramID:        program.ID,
+		Name:             program.Name,
+		Status:           program.Status,
+		Target:           program.Target,
+		Collected:        program.Collected,
+		Distributed:      program.Distributed,
+		StartDate:        program.StartDate,
+		EndDate:          program.EndDate,
+		CountByType:      map[string]int{},
+		DailyCollections: []DailyCollection{},
+		AsOf:             asOf.Format(time.RFC3339),
+	}
+	if program.Target > 0 {
+		summary.ProgressPercent = math.Round(program.Collected/program.Target*10000) / 100
+	}
+	if program.Collected > 0 {
+		summary.DistributionRatio = math.Round(program.Distributed/program.Collected*10000) / 10000
+	}
+	if endDate, err := time.Parse(time.RFC3339, program.EndDate); err == nil && endDate.After(asOf) {
+		summary.DaysRemaining = int(math.Ceil(endDate.Sub(asOf).Hours() / 24))
+	}
+
+	donors := make(map[string]bool)
+	daily := make(map[string]*DailyCollection)
+	for _, zakat := range zakats {
+		if zakat.Status == "pending" {
+			summary.PendingCount++
+			continue
+		}
+
+		summary.DonationCount++
+		summary.CountByType[zakat.Type]++
+
+		// Anonymous donations share an alias, so each one counts as a separate donor
+		if zakat.IsAnonymous {
+			summary.DonorCount++
+		} else if !donors[zakat.Muzakki] {
+			donors[zakat.Muzakki] = true
+			summary.DonorCount++
+		}
+
+		collectedAt := zakat.ValidationDate
+		if collectedAt == "" {
+			collectedAt = zakat.Timestamp
+		}
+		parsed, err := time.Parse(time.RFC3339, collectedAt)
+		if err != nil {
+			continue
+		}
+		day := parsed.UTC().Format("2006-01-02")
+		if daily[day] == nil {
+			daily[day] = &DailyCollection{Date: day}
+		}
+		daily[day].Amount += zakat.Amount
+		daily[day].Count++
+	}
+
+	for _, collection := range daily {
+		summary.DailyCollections = append(summary.DailyCollections, *collection)
+	}
+	sort.Slice(summary.DailyCollections, func(i, j int) bool {
+		return summary.DailyCollections[i].Date < summary.DailyCollections[j].Date
+	})
+
+	return summary, nil
+}
+
 // OFFICER MANAGEMENT FUNCTIONS
 
-// RegisterOfficer registers a new officer
+// RegisterOfficer registers a new officer.
+// The referral code must not be in use, currently or previously, by any other officer.
 func (s *SmartContract) RegisterOfficer(ctx contractapi.TransactionContextInterface, id string, name string, referralCode string) error {
 	if err := validateOfficerID(id); err != nil {
 		return err
 	}
+	if err := validateReferralCode(referralCode); err != nil {
+		return err
+	}
 
 	exists, err := ctx.GetStub().GetState(id)
 	if err != nil {
 		return fmt.Errorf("failed to check officer existence: %v", err)
 	}
 	if exists != nil {
-		return fmt.Errorf("officer %s already exists", id)
+		return newConflictError("officer %s already exists", id)
 	}
 
-	// Check if referral code already exists (should be unique)
-	// This requires a query, which can be expensive. Consider if this check is critical path or can be handled by UI/app layer.
-	// For now, let's assume referral codes are managed to be unique.
-	// officerByReferral, _ := s.GetOfficerByReferral(ctx, referralCode)
-	// if officerByReferral.ID != "" {
-	// 	return fmt.Errorf("referral code %s is already in use by officer %s", referralCode, officerByReferral.ID)
-	// }
+	// Referral codes identify the officer credited for a donation, so they must be unique
+	existing, err := s.findOfficerByReferral(ctx, referralCode)
+	if err != nil {
+		return fmt.Errorf("failed to check referral code uniqueness: %w", err)
+	}
+	if existing != nil {
+		return newConflictError("referral code %s is already in use by officer %s", referralCode, existing.ID)
+	}
 
 	officer := Officer{
 		ID:             id,
@@ -406,116 +955,515 @@ func (s *SmartContract) RegisterOfficer(ctx contractapi.TransactionContextInterf
 		return err
 	}
 
-	return ctx.GetStub().PutState(id, officerJSON)
+	if err := ctx.GetStub().PutState(id, officerJSON); err != nil {
+		return err
+	}
+	return claimReferralCode(ctx, referralCode, id)
 }
 
-// GetOfficerByReferral returns officer by referral code
-func (s *SmartContract) GetOfficerByReferral(ctx contractapi.TransactionContextInterface, referralCode string) (Officer, error) {
-	queryString := fmt.Sprintf("{\"selector\":{\"referralCode\":\"%s\"}}", referralCode)
-	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+// GetOfficer returns an officer by ID.
+// CommissionRate reflects the rate in effect at the transaction's timestamp, including scheduled changes whose effective date has passed.
+func (s *SmartContract) GetOfficer(ctx contractapi.TransactionContextInterface, id string) (Officer, error) {
+	officerJSON, err := ctx.GetStub().GetState(id)
 	if err != nil {
-		return Officer{}, fmt.Errorf("failed to query officer: %v", err)
-	}
-	defer resultsIterator.Close()
-
-	if !resultsIterator.HasNext() {
-		return Officer{}, fmt.Errorf("officer with referral code %s does not exist", referralCode)
+		return Officer{}, fmt.Errorf("failed to read officer: %v", err)
 	}
-
-	queryResponse, err := resultsIterator.Next()
-	if err != nil {
-		return Officer{}, err
+	if officerJSON == nil {
+		return Officer{}, newNotFoundError("officer %s does not exist", id)
 	}
 
 	var officer Officer
-	err = json.Unmarshal(queryResponse.Value, &officer)
+	err = json.Unmarshal(officerJSON, &officer)
 	if err != nil {
 		return Officer{}, fmt.Errorf("failed to unmarshal officer: %v", err)
 	}
 
-	// It's possible for multiple officers to have the same referral code if not enforced at RegisterOfficer.
-	// This query will return the first one found. If uniqueness is critical, RegisterOfficer should prevent duplicates.
-	if resultsIterator.HasNext() {
-		// Log a warning if multiple officers found with the same referral code
-		fmt.Printf("Warning: Multiple officers found with referral code %s. Returning the first one.\n", referralCode)
+	now, err := txTime(ctx)
+	if err != nil {
+		return Officer{}, err
 	}
-
+	officer.CommissionRate = effectiveCommissionRate(officer, now)
 	return officer, nil
 }
 
-// ZAKAT MANAGEMENT FUNCTIONS
-
-// AddZakat adds a new zakat donation with "pending" status.
-// programID and referralCode can be empty strings if not applicable.
-// If programID is provided, it validates that the program exists.
-// Zakat ID format is validated (e.g., ZKT-{ORG}-{YYYYMM}-{COUNTER}).
-func (s *SmartContract) AddZakat(ctx contractapi.TransactionContextInterface, id string, programID string, muzakki string, amount float64, zakatType string, paymentMethod string, organization string, referralCode string) error {
-	// Validate inputs
-	if err := validateZakatID(id); err != nil {
-		return err
+// GetOfficerByReferral returns officer by referral code.
+// Codes retired by RotateReferralCode still resolve to their officer, so past donations keep their attribution.
+func (s *SmartContract) GetOfficerByReferral(ctx contractapi.TransactionContextInterface, referralCode string) (Officer, error) {
+	officer, err := s.findOfficerByReferral(ctx, referralCode)
+	if err != nil {
+		return Officer{}, err
 	}
-	if err := validateAmount(amount); err != nil {
-		return err
+	if officer == nil {
+		return Officer{}, newNotFoundError("officer with referral code %s does not exist", referralCode)
 	}
-	if err := validateZakatType(zakatType); err != nil {
-		return err
+	return *officer, nil
+}
+
+// findOfficerByReferral looks up an officer by current or previously used referral code through the
+// REFCODE- index. Codes of officers registered before the index existed are found with rich queries.
+// It returns nil without error if no officer has ever used the code.
+func (s *SmartContract) findOfficerByReferral(ctx contractapi.TransactionContextInterface, referralCode string) (*Officer, error) {
+	officerID, err := ctx.GetStub().GetState(referralCodeIndex(referralCode))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read referral code index: %v", err)
 	}
-	if err := validatePaymentMethod(paymentMethod); err != nil {
-		return err
+	if officerID != nil {
+		officer, err := s.readOfficer(ctx, string(officerID))
+		if err != nil {
+			return nil, err
+		}
+		return &officer, nil
 	}
-	if err := validateOrganization(organization); err != nil {
-		return err
+
+	queryString := fmt.Sprintf("{\"selector\":{\"referralCode\":\"%s\"}}", referralCode)
+	officer, err := s.queryOfficer(ctx, queryString, referralCode)
+	if err != nil || officer != nil {
+		return officer, err
 	}
-	if muzakki == "" {
-		return fmt.Errorf("muzakki name cannot be empty")
+
+	queryString = fmt.Sprintf("{\"selector\":{\"previousReferralCodes\":{\"$elemMatch\":{\"$eq\":\"%s\"}}}}", referralCode)
+	return s.queryOfficer(ctx, queryString, referralCode)
+}
+
+// queryOfficer runs a rich query and returns the first matching officer, or nil if there is none
+func (s *SmartContract) queryOfficer(ctx contractapi.TransactionContextInterface, queryString string, referralCode string) (*Officer, error) {
+	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query officer: %v", err)
 	}
+	defer resultsIterator.Close()
 
-	// Check if program exists (if programID is provided and not an empty string)
-	if programID != "" {
-		if err := validateProgramID(programID); err != nil { // Also validate format of programID if provided
-			return fmt.Errorf("invalid program ID format for '%s': %w", programID, err)
-		}
-		program, err := s.GetProgram(ctx, programID)
+	var found *Officer
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
 		if err != nil {
-			return fmt.Errorf("failed to validate program ID '%s': %w", programID, err)
+			return nil, err
 		}
-		if program.ID == "" { // Should be redundant if GetProgram errors on not found
-			return fmt.Errorf("program with ID '%s' does not exist", programID)
+
+		// Zakat records carry the referral code of the officer too
+		if strings.HasPrefix(queryResponse.Key, "ZKT-") {
+			continue
 		}
-	}
 
-	// Check if officer exists by referral code (if referralCode is provided and not an empty string)
-	if referralCode != "" {
-		officer, err := s.GetOfficerByReferral(ctx, referralCode)
-		if err != nil {
-			return fmt.Errorf("failed to validate referral code '%s': %w", referralCode, err)
+		// RegisterOfficer and RotateReferralCode keep codes unique, but ledgers written before
+		// uniqueness was enforced may still contain duplicates.
+		if found != nil {
+			fmt.Printf("Warning: Multiple officers found with referral code %s. Returning the first one.\n", referralCode)
+			break
 		}
-		if officer.ID == "" { // Should be redundant if GetOfficerByReferral errors on not found
-			return fmt.Errorf("officer with referral code '%s' does not exist", referralCode)
+
+		var officer Officer
+		err = json.Unmarshal(queryResponse.Value, &officer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal officer: %v", err)
 		}
+		found = &officer
 	}
 
-	// Check if zakat already exists
-	exists, err := s.ZakatExists(ctx, id)
+	return found, nil
+}
+
+// UpdateOfficer updates an officer's name and contact details
+func (s *SmartContract) UpdateOfficer(ctx contractapi.TransactionContextInterface, officerID string, name string, phone string, email string) error {
+	if name == "" {
+		return newInvalidInputError("name", "officer name cannot be empty")
+	}
+	if email != "" && !strings.Contains(email, "@") {
+		return newInvalidInputError("email", "invalid officer email address: %s", email)
+	}
+
+	officer, err := s.readOfficer(ctx, officerID)
 	if err != nil {
-		return fmt.Errorf("failed to check zakat existence for ID '%s': %w", id, err)
+		return err
 	}
-	if exists {
-		return fmt.Errorf("zakat %s already exists", id)
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
 	}
 
-	// Create zakat with pending status
-	zakat := Zakat{
-		ID:            id,
-		ProgramID:     programID, // Will be empty if not provided
-		Muzakki:       muzakki,
-		Amount:        amount,
-		Type:          zakatType,
-		PaymentMethod: paymentMethod,
-		Status:        "pending", // Initial status
-		Organization:  organization,
-		ReferralCode:  referralCode, // Will be empty if not provided
-		Timestamp:     time.Now().Format(time.RFC3339),
+	officer.Name = name
+	officer.Phone = phone
+	officer.Email = email
+	officer.UpdatedAt = now.Format(time.RFC3339)
+
+	if err := s.putOfficer(ctx, officer); err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully updated officer %s profile\n", officerID)
+	return nil
+}
+
+// UpdateOfficerCommission records a commission rate change for an officer, effective from effectiveDate.
+// Every change is kept in the officer's commission history. Changes must be recorded in chronological
+// order; a future-dated change leaves the current rate untouched until its effective date.
+func (s *SmartContract) UpdateOfficerCommission(ctx contractapi.TransactionContextInterface, officerID string, newRate float64, effectiveDate string, changedBy string) error {
+	if newRate < 0 || newRate > 1 {
+		return newInvalidInputError("commissionRate", "invalid commission rate. Must be between 0 and 1 (e.g. 0.05 for 5%%)")
+	}
+	if err := validateTimestamp(effectiveDate); err != nil {
+		return fmt.Errorf("invalid effective date: %w", err)
+	}
+	if changedBy == "" {
+		return newInvalidInputError("changedBy", "changedBy (admin user) cannot be empty")
+	}
+
+	officer, err := s.readOfficer(ctx, officerID)
+	if err != nil {
+		return err
+	}
+
+	// Seed the history with the rate the officer had before the first recorded change
+	if len(officer.CommissionHistory) == 0 {
+		officer.CommissionHistory = append(officer.CommissionHistory, CommissionChange{
+			Rate:          officer.CommissionRate,
+			EffectiveDate: officer.CreatedAt,
+			ChangedBy:     "system",
+			ChangedAt:     officer.CreatedAt,
+		})
+	}
+
+	effective, _ := time.Parse(time.RFC3339, effectiveDate)
+	last := officer.CommissionHistory[len(officer.CommissionHistory)-1]
+	if lastEffective, err := time.Parse(time.RFC3339, last.EffectiveDate); err == nil && effective.Before(lastEffective) {
+		return newInvalidInputError("effectiveDate", "effective date %s is before the latest recorded commission change (%s)", effectiveDate, last.EffectiveDate)
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	officer.CommissionHistory = append(officer.CommissionHistory, CommissionChange{
+		Rate:          newRate,
+		EffectiveDate: effectiveDate,
+		ChangedBy:     changedBy,
+		ChangedAt:     now.Format(time.RFC3339),
+	})
+	officer.CommissionRate = effectiveCommissionRate(officer, now)
+	officer.UpdatedAt = now.Format(time.RFC3339)
+
+	if err := s.putOfficer(ctx, officer); err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully recorded commission rate %.4f for officer %s effective %s\n", newRate, officerID, effectiveDate)
+	return nil
+}
+
+// GetOfficerCommissionRate returns the commission rate in effect for an officer at the given date
+func (s *SmartContract) GetOfficerCommissionRate(ctx contractapi.TransactionContextInterface, officerID string, date string) (float64, error) {
+	if err := validateTimestamp(date); err != nil {
+		return 0, err
+	}
+
+	officer, err := s.readOfficer(ctx, officerID)
+	if err != nil {
+		return 0, err
+	}
+
+	at, _ := time.Parse(time.RFC3339, date)
+	return effectiveCommissionRate(officer, at), nil
+}
+
+// RotateReferralCode replaces an officer's referral code. The old code is kept in PreviousReferralCodes,
+// so donations made with it are still attributed to the officer.
+func (s *SmartContract) RotateReferralCode(ctx contractapi.TransactionContextInterface, officerID string, newReferralCode string) error {
+	if err := validateReferralCode(newReferralCode); err != nil {
+		return err
+	}
+
+	officer, err := s.readOfficer(ctx, officerID)
+	if err != nil {
+		return err
+	}
+
+	existing, err := s.findOfficerByReferral(ctx, newReferralCode)
+	if err != nil {
+		return fmt.Errorf("failed to check referral code uniqueness: %w", err)
+	}
+	if existing != nil {
+		return newConflictError("referral code %s is already in use by officer %s", newReferralCode, existing.ID)
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	oldReferralCode := officer.ReferralCode
+	if oldReferralCode != "" {
+		officer.PreviousReferralCodes = append(officer.PreviousReferralCodes, oldReferralCode)
+	}
+	officer.ReferralCode = newReferralCode
+	officer.UpdatedAt = now.Format(time.RFC3339)
+
+	if err := s.putOfficer(ctx, officer); err != nil {
+		return err
+	}
+	if err := claimReferralCode(ctx, newReferralCode, officerID); err != nil {
+		return err
+	}
+	// The retired code stays the officer's; it is indexed here if it predates the index
+	if oldReferralCode != "" {
+		if err := claimReferralCode(ctx, oldReferralCode, officerID); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Successfully rotated referral code for officer %s from %s to %s\n", officerID, oldReferralCode, newReferralCode)
+	return nil
+}
+
+// claimReferralCode records a referral code as an officer's in the REFCODE- index
+func claimReferralCode(ctx contractapi.TransactionContextInterface, referralCode string, officerID string) error {
+	if err := ctx.GetStub().PutState(referralCodeIndex(referralCode), []byte(officerID)); err != nil {
+		return fmt.Errorf("failed to index referral code %s: %v", referralCode, err)
+	}
+	return nil
+}
+
+// readOfficer loads an officer record as stored, without resolving scheduled commission changes
+func (s *SmartContract) readOfficer(ctx contractapi.TransactionContextInterface, officerID string) (Officer, error) {
+	officerJSON, err := ctx.GetStub().GetState(officerID)
+	if err != nil {
+		return Officer{}, fmt.Errorf("failed to read officer: %v", err)
+	}
+	if officerJSON == nil {
+		return Officer{}, newNotFoundError("officer %s does not exist", officerID)
+	}
+
+	var officer Officer
+	err = json.Unmarshal(officerJSON, &officer)
+	if err != nil {
+		return Officer{}, fmt.Errorf("failed to unmarshal officer: %v", err)
+	}
+	return officer, nil
+}
+
+// putOfficer writes an officer record to the world state
+func (s *SmartContract) putOfficer(ctx contractapi.TransactionContextInterface, officer Officer) error {
+	officerJSON, err := json.Marshal(officer)
+	if err != nil {
+		return fmt.Errorf("failed to marshal updated officer: %w", err)
+	}
+
+	err = ctx.GetStub().PutState(officer.ID, officerJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update officer %s: %w", officer.ID, err)
+	}
+	return nil
+}
+
+// effectiveCommissionRate returns the rate from the latest commission change effective at the given time.
+// Officers without a recorded history keep their registered CommissionRate.
+func effectiveCommissionRate(officer Officer, at time.Time) float64 {
+	rate := officer.CommissionRate
+	if len(officer.CommissionHistory) == 0 {
+		return rate
+	}
+	for _, change := range officer.CommissionHistory {
+		effective, err := time.Parse(time.RFC3339, change.EffectiveDate)
+		if err != nil || effective.After(at) {
+			continue
+		}
+		rate = change.Rate
+	}
+	return rate
+}
+
+// ZAKAT MANAGEMENT FUNCTIONS
+
+// AddZakat adds a new zakat donation with "pending" status.
+// programID and referralCode can be empty strings if not applicable.
+// If programID is provided, it validates that the program exists.
+// Zakat ID format is validated (e.g., ZKT-{ORG}-{YYYYMM}-{COUNTER}).
+func (s *SmartContract) AddZakat(ctx contractapi.TransactionContextInterface, id string, programID string, muzakki string, amount float64, zakatType string, paymentMethod string, organization string, referralCode string) error {
+	_, err := s.addZakat(ctx, ZakatSubmission{
+		ID:            id,
+		ProgramID:     programID,
+		Muzakki:       muzakki,
+		Amount:        amount,
+		Type:          zakatType,
+		PaymentMethod: paymentMethod,
+		Organization:  organization,
+		ReferralCode:  referralCode,
+	})
+	return err
+}
+
+// SubmitZakat adds a new zakat donation described by a ZakatSubmission and returns the stored record.
+// It applies the same validation as AddZakat. Anonymous submissions are stored under their
+// display alias ("Hamba Allah" if none is given), so the donor's real name never reaches the ledger.
+// A submission replayed under the same request key returns the zakat it first created; a different
+// submission under a used request key is rejected.
+func (s *SmartContract) SubmitZakat(ctx contractapi.TransactionContextInterface, submission ZakatSubmission) (*Zakat, error) {
+	if submission.RequestKey == "" {
+		return s.addZakat(ctx, submission)
+	}
+
+	if err := validateRequestKey(submission.RequestKey); err != nil {
+		return nil, err
+	}
+	payloadHash, err := submissionHash(submission)
+	if err != nil {
+		return nil, err
+	}
+
+	indexKey := requestKeyIndex(submission.RequestKey)
+	requestJSON, err := ctx.GetStub().GetState(indexKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request key %s: %w", submission.RequestKey, err)
+	}
+	if requestJSON != nil {
+		var request ZakatRequest
+		if err := json.Unmarshal(requestJSON, &request); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal request key %s: %w", submission.RequestKey, err)
+		}
+		if request.PayloadHash != payloadHash {
+			// The field tells clients it is the request key that conflicts, not the zakat
+			return nil, &ContractError{
+				Code:    ErrCodeConflict,
+				Message: fmt.Sprintf("request key %s was already used for zakat %s with a different submission", submission.RequestKey, request.ZakatID),
+				Field:   "requestKey",
+			}
+		}
+		fmt.Printf("Request key %s replayed, returning Zakat: %s\n", submission.RequestKey, request.ZakatID)
+		zakat, err := s.QueryZakat(ctx, request.ZakatID)
+		if err != nil {
+			return nil, err
+		}
+		return &zakat, nil
+	}
+
+	zakat, err := s.addZakat(ctx, submission)
+	if err != nil {
+		return nil, err
+	}
+
+	request := ZakatRequest{
+		RequestKey:  submission.RequestKey,
+		ZakatID:     zakat.ID,
+		PayloadHash: payloadHash,
+		CreatedAt:   zakat.Timestamp,
+	}
+	requestJSON, err = json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request key data: %w", err)
+	}
+	if err := ctx.GetStub().PutState(indexKey, requestJSON); err != nil {
+		return nil, fmt.Errorf("failed to put request key %s to state: %w", submission.RequestKey, err)
+	}
+	return zakat, nil
+}
+
+// addZakat validates a submission and stores it as a new zakat with "pending" status.
+func (s *SmartContract) addZakat(ctx contractapi.TransactionContextInterface, submission ZakatSubmission) (*Zakat, error) {
+	id := submission.ID
+	programID := submission.ProgramID
+	referralCode := submission.ReferralCode
+	if submission.Anonymous && submission.Muzakki == "" {
+		submission.Muzakki = defaultAnonymousAlias
+	}
+
+	// Validate inputs
+	if err := validateZakatID(id); err != nil {
+		return nil, err
+	}
+
+	// Foreign currency and gold/silver donations are recorded in IDR at the latest published rate
+	var exchangeRate ExchangeRate
+	submission.Denomination = strings.ToUpper(submission.Denomination)
+	if submission.Denomination == baseDenomination {
+		submission.Denomination = ""
+	}
+	if submission.Denomination != "" {
+		rate, amount, err := s.convertToIDR(ctx, submission)
+		if err != nil {
+			return nil, err
+		}
+		exchangeRate = rate
+		submission.Amount = amount
+	} else if submission.OriginalAmount != 0 {
+		return nil, newInvalidInputError("denomination", "original amount requires a denomination other than %s", baseDenomination)
+	}
+
+	if err := validateAmount(submission.Amount); err != nil {
+		return nil, err
+	}
+	if err := validateZakatType(submission.Type); err != nil {
+		return nil, err
+	}
+	if err := validatePaymentMethod(submission.PaymentMethod); err != nil {
+		return nil, err
+	}
+	if err := validateOrganization(submission.Organization); err != nil {
+		return nil, err
+	}
+	if submission.Muzakki == "" {
+		return nil, newInvalidInputError("muzakki", "muzakki name cannot be empty")
+	}
+
+	// Check if program exists (if programID is provided and not an empty string)
+	if programID != "" {
+		if err := validateProgramID(programID); err != nil { // Also validate format of programID if provided
+			return nil, fmt.Errorf("invalid program ID format for '%s': %w", programID, err)
+		}
+		program, err := s.GetProgram(ctx, programID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to validate program ID '%s': %w", programID, err)
+		}
+		if program.ID == "" { // Should be redundant if GetProgram errors on not found
+			return nil, newNotFoundError("program with ID '%s' does not exist", programID)
+		}
+	}
+
+	// Check if officer exists by referral code (if referralCode is provided and not an empty string)
+	if referralCode != "" {
+		officer, err := s.GetOfficerByReferral(ctx, referralCode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to validate referral code '%s': %w", referralCode, err)
+		}
+		if officer.ID == "" { // Should be redundant if GetOfficerByReferral errors on not found
+			return nil, newNotFoundError("officer with referral code '%s' does not exist", referralCode)
+		}
+	}
+
+	// Zakat fitrah must be exactly the yearly rate for each soul it is paid for
+	var fitrahRate FitrahRate
+	if submission.Type == "fitrah" {
+		rate, souls, err := s.checkFitrahAmount(ctx, submission)
+		if err != nil {
+			return nil, err
+		}
+		fitrahRate = rate
+		submission.Souls = souls
+	} else if submission.Souls != 0 || len(submission.SoulNames) > 0 {
+		return nil, newInvalidInputError("souls", "souls can only be recorded for zakat fitrah")
+	}
+
+	// Check if zakat already exists
+	exists, err := s.ZakatExists(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check zakat existence for ID '%s': %w", id, err)
+	}
+	if exists {
+		return nil, newConflictError("zakat %s already exists", id)
+	}
+
+	// Create zakat with pending status
+	zakat := Zakat{
+		ID:            id,
+		ProgramID:     programID, // Will be empty if not provided
+		Muzakki:       submission.Muzakki,
+		IsAnonymous:   submission.Anonymous,
+		Amount:        submission.Amount,
+		Type:          submission.Type,
+		PaymentMethod: submission.PaymentMethod,
+		Status:        "pending", // Initial status
+		Organization:  submission.Organization,
+		ReferralCode:  referralCode, // Will be empty if not provided
+		Timestamp:     time.Now().Format(time.RFC3339),
 		// Initialize distribution fields with defaults for schema validation
 		ReceiptNumber:  "",
 		ValidatedBy:    "",
@@ -525,19 +1473,104 @@ func (s *SmartContract) AddZakat(ctx contractapi.TransactionContextInterface, id
 		DistributedAt:  "",
 		DistributionID: "",
 		DistributedBy:  "",
+		Souls:          submission.Souls,
+		SoulNames:      submission.SoulNames,
+		FitrahRegion:   fitrahRate.Region,
+		HijriYear:      fitrahRate.HijriYear,
+		FitrahRate:     fitrahRate.RatePerSoul,
+		Denomination:   submission.Denomination,
+		OriginalAmount: submission.OriginalAmount,
+		ExchangeRate:   exchangeRate.RateIDR,
+		ExchangeRateID: exchangeRate.ID,
+		RequestKey:     submission.RequestKey,
 	}
 
 	zakatJSON, err := json.Marshal(zakat)
 	if err != nil {
-		return fmt.Errorf("failed to marshal zakat data: %w", err)
+		return nil, fmt.Errorf("failed to marshal zakat data: %w", err)
 	}
 
 	err = ctx.GetStub().PutState(id, zakatJSON)
 	if err != nil {
-		return fmt.Errorf("failed to put zakat %s to state: %w", id, err)
+		return nil, fmt.Errorf("failed to put zakat %s to state: %w", id, err)
 	}
 	fmt.Printf("Successfully added Zakat: %s\n", id)
-	return nil
+	return &zakat, nil
+}
+
+// convertToIDR returns the IDR amount of a submission made in another denomination, along with the
+// exchange rate applied. Zakat fitrah is always paid in IDR at the yearly per-soul rate.
+func (s *SmartContract) convertToIDR(ctx contractapi.TransactionContextInterface, submission ZakatSubmission) (ExchangeRate, float64, error) {
+	if err := validateDenomination(submission.Denomination); err != nil {
+		return ExchangeRate{}, 0, err
+	}
+	if submission.Type == "fitrah" {
+		return ExchangeRate{}, 0, newInvalidInputError("denomination", "zakat fitrah must be paid in %s", baseDenomination)
+	}
+	if err := validateAmount(submission.OriginalAmount); err != nil {
+		return ExchangeRate{}, 0, fmt.Errorf("invalid original amount: %w", err)
+	}
+
+	rate, err := s.GetExchangeRate(ctx, submission.Denomination, "")
+	if err != nil {
+		return ExchangeRate{}, 0, err
+	}
+
+	amount := math.Round(submission.OriginalAmount*rate.RateIDR*100) / 100
+	if submission.Amount != 0 && math.Abs(submission.Amount-amount) > 0.01 {
+		return ExchangeRate{}, 0, newInvalidInputError("amount", "amount %.2f does not match %g %s at %.2f IDR per unit (%.2f)",
+			submission.Amount, submission.OriginalAmount, submission.Denomination, rate.RateIDR, amount)
+	}
+	return rate, amount, nil
+}
+
+// checkFitrahAmount verifies that a fitrah submission's amount equals the applicable rate times
+// the number of souls. It returns the rate used and the number of souls, derived from the amount
+// when the submission does not state it.
+func (s *SmartContract) checkFitrahAmount(ctx contractapi.TransactionContextInterface, submission ZakatSubmission) (FitrahRate, int, error) {
+	if submission.Souls < 0 {
+		return FitrahRate{}, 0, newInvalidInputError("souls", "number of souls cannot be negative")
+	}
+	if submission.Anonymous && len(submission.SoulNames) > 0 {
+		return FitrahRate{}, 0, newInvalidInputError("soulNames", "soul names cannot be recorded for anonymous donations")
+	}
+	for _, name := range submission.SoulNames {
+		if strings.TrimSpace(name) == "" {
+			return FitrahRate{}, 0, newInvalidInputError("soulNames", "soul names cannot be empty")
+		}
+	}
+
+	region := strings.ToUpper(submission.Region)
+	if region == "" {
+		region = regionForOrganization(submission.Organization)
+	}
+	rate, err := s.GetFitrahRate(ctx, region, submission.HijriYear)
+	if err != nil {
+		return FitrahRate{}, 0, err
+	}
+
+	souls := submission.Souls
+	if souls == 0 {
+		souls = len(submission.SoulNames)
+	}
+	if souls == 0 {
+		// Legacy callers only send the amount; accept it if it covers a whole number of souls
+		souls = int(math.Round(submission.Amount / rate.RatePerSoul))
+		if souls < 1 || math.Abs(float64(souls)*rate.RatePerSoul-submission.Amount) > 0.01 {
+			return FitrahRate{}, 0, newInvalidInputError("amount", "fitrah amount %.2f is not a multiple of the %s %dH rate of %.2f per soul", submission.Amount, rate.Region, rate.HijriYear, rate.RatePerSoul)
+		}
+	}
+
+	if len(submission.SoulNames) > 0 && len(submission.SoulNames) != souls {
+		return FitrahRate{}, 0, newInvalidInputError("soulNames", "got %d soul names for %d souls", len(submission.SoulNames), souls)
+	}
+
+	expected := float64(souls) * rate.RatePerSoul
+	if math.Abs(expected-submission.Amount) > 0.01 {
+		return FitrahRate{}, 0, newInvalidInputError("amount", "fitrah amount %.2f does not match %d soul(s) x %.2f = %.2f (%s %dH rate)", submission.Amount, souls, rate.RatePerSoul, expected, rate.Region, rate.HijriYear)
+	}
+
+	return rate, souls, nil
 }
 
 // AutoValidatePayment automatically validates a pending payment with system-generated receipt.
@@ -545,7 +1578,7 @@ func (s *SmartContract) AddZakat(ctx contractapi.TransactionContextInterface, id
 // Used by the auto-validation system for mock payments.
 func (s *SmartContract) AutoValidatePayment(ctx contractapi.TransactionContextInterface, zakatID string, paymentGatewayRef string) error {
 	if zakatID == "" {
-		return fmt.Errorf("zakat ID cannot be empty")
+		return newInvalidInputError("zakatID", "zakat ID cannot be empty")
 	}
 
 	zakat, err := s.QueryZakat(ctx, zakatID)
@@ -554,7 +1587,7 @@ func (s *SmartContract) AutoValidatePayment(ctx contractapi.TransactionContextIn
 	}
 
 	if zakat.Status != "pending" {
-		return fmt.Errorf("zakat %s is not in pending status, current status: %s", zakatID, zakat.Status)
+		return newInvalidStateError("zakat %s is not in pending status, current status: %s", zakatID, zakat.Status)
 	}
 
 	// Auto-validate with system-generated receipt
@@ -572,13 +1605,13 @@ func (s *SmartContract) AutoValidatePayment(ctx contractapi.TransactionContextIn
 // associated program and officer records if applicable.
 func (s *SmartContract) ValidatePayment(ctx contractapi.TransactionContextInterface, zakatID string, receiptNumber string, validatedBy string) error {
 	if zakatID == "" {
-		return fmt.Errorf("zakat ID cannot be empty")
+		return newInvalidInputError("zakatID", "zakat ID cannot be empty")
 	}
 	if receiptNumber == "" {
-		return fmt.Errorf("receipt number cannot be empty")
+		return newInvalidInputError("receiptNumber", "receipt number cannot be empty")
 	}
 	if validatedBy == "" {
-		return fmt.Errorf("validatedBy (admin user) cannot be empty")
+		return newInvalidInputError("validatedBy", "validatedBy (admin user) cannot be empty")
 	}
 
 	zakat, err := s.QueryZakat(ctx, zakatID)
@@ -587,20 +1620,27 @@ func (s *SmartContract) ValidatePayment(ctx contractapi.TransactionContextInterf
 	}
 
 	if zakat.Status != "pending" {
-		return fmt.Errorf("zakat %s is not in pending status, current status: %s", zakatID, zakat.Status)
+		return newInvalidStateError("zakat %s is not in pending status, current status: %s", zakatID, zakat.Status)
+	}
+
+	// The receipt hash covers the validation date, so every endorser must stamp the same one
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
 	}
 
 	// Update Zakat details
 	zakat.Status = "collected"
 	zakat.ReceiptNumber = receiptNumber
 	zakat.ValidatedBy = validatedBy
-	zakat.ValidationDate = time.Now().Format(time.RFC3339)
+	zakat.ValidationDate = now.Format(time.RFC3339)
 	// Ensure distribution fields are initialized for schema compliance
 	zakat.Mustahik = ""
 	zakat.Distribution = 0
 	zakat.DistributedAt = ""
 	zakat.DistributionID = ""
 	zakat.DistributedBy = ""
+	zakat.ReceiptHash = computeReceiptHash(zakat)
 
 	// Update program collected amount if ProgramID is present
 	if zakat.ProgramID != "" {
@@ -638,6 +1678,9 @@ func (s *SmartContract) ValidatePayment(ctx contractapi.TransactionContextInterf
 		fmt.Printf("Successfully updated officer %s total referred amount.\n", officer.ID)
 	}
 
+	// Receipts quote the validating transaction so it can be looked up on the ledger
+	zakat.ValidationTxID = ctx.GetStub().GetTxID()
+
 	zakatJSON, err := json.Marshal(zakat)
 	if err != nil {
 		return fmt.Errorf("failed to marshal updated zakat %s: %w", zakatID, err)
@@ -651,6 +1694,53 @@ func (s *SmartContract) ValidatePayment(ctx contractapi.TransactionContextInterf
 	return nil
 }
 
+// VerifyReceipt checks a receipt hash against a zakat on the ledger. A receipt is valid only if the
+// zakat has been validated and the hash matches the one recorded at validation; zakat validated
+// before receipt hashes were recorded are checked against a hash of their current receipt fields.
+func (s *SmartContract) VerifyReceipt(ctx contractapi.TransactionContextInterface, zakatID string, receiptHash string) (ReceiptVerification, error) {
+	if err := validateZakatID(zakatID); err != nil {
+		return ReceiptVerification{}, err
+	}
+
+	zakatJSON, err := ctx.GetStub().GetState(zakatID)
+	if err != nil {
+		return ReceiptVerification{}, fmt.Errorf("failed to read zakat %s: %v", zakatID, err)
+	}
+	if zakatJSON == nil {
+		return ReceiptVerification{ZakatID: zakatID, Reason: "zakat not found"}, nil
+	}
+
+	var zakat Zakat
+	if err := json.Unmarshal(zakatJSON, &zakat); err != nil {
+		return ReceiptVerification{}, fmt.Errorf("failed to unmarshal zakat %s: %v", zakatID, err)
+	}
+
+	verification := ReceiptVerification{
+		ZakatID:        zakat.ID,
+		Status:         zakat.Status,
+		Amount:         zakat.Amount,
+		Organization:   zakat.Organization,
+		ValidationDate: zakat.ValidationDate,
+		ValidationTxID: zakat.ValidationTxID,
+	}
+
+	expected := zakat.ReceiptHash
+	if expected == "" {
+		expected = computeReceiptHash(zakat)
+	}
+
+	switch {
+	case zakat.Status == "pending":
+		verification.Reason = "payment has not been validated"
+	case !strings.EqualFold(receiptHash, expected):
+		verification.Reason = "receipt hash does not match the ledger"
+	default:
+		verification.Valid = true
+	}
+
+	return verification, nil
+}
+
 // QueryZakat returns zakat by ID
 func (s *SmartContract) QueryZakat(ctx contractapi.TransactionContextInterface, id string) (Zakat, error) {
 	zakatJSON, err := ctx.GetStub().GetState(id)
@@ -658,7 +1748,7 @@ func (s *SmartContract) QueryZakat(ctx contractapi.TransactionContextInterface,
 		return Zakat{}, fmt.Errorf("failed to read zakat: %v", err)
 	}
 	if zakatJSON == nil {
-		return Zakat{}, fmt.Errorf("zakat %s does not exist", id)
+		return Zakat{}, newNotFoundError("zakat %s does not exist", id)
 	}
 
 	var zakat Zakat
@@ -700,7 +1790,50 @@ func (s *SmartContract) GetAllZakat(ctx contractapi.TransactionContextInterface)
 	return zakats, nil
 }
 
-// GetZakatByStatus returns zakat transactions by status
+// maxZakatPageSize caps the records returned by one GetZakatPage call
+const maxZakatPageSize = 1000
+
+// ZakatPage is one page of zakat records in ID order
+type ZakatPage struct {
+	Records  []Zakat `json:"records"`
+	Bookmark string  `json:"bookmark"` // Pass to the next call; empty on the last page
+}
+
+// GetZakatPage returns up to pageSize zakat records, starting after bookmark (empty for the
+// first page). Unlike GetAllZakat it can walk a large ledger without one huge response.
+func (s *SmartContract) GetZakatPage(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*ZakatPage, error) {
+	if pageSize < 1 || pageSize > maxZakatPageSize {
+		return nil, newInvalidInputError("pageSize", "page size must be between 1 and %d", maxZakatPageSize)
+	}
+
+	resultsIterator, metadata, err := ctx.GetStub().GetStateByRangeWithPagination("ZKT-", "ZKT-\uffff", pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get zakat page: %w", err)
+	}
+	defer resultsIterator.Close()
+
+	page := &ZakatPage{Records: []Zakat{}}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate zakat page: %w", err)
+		}
+
+		var zakat Zakat
+		if err := json.Unmarshal(queryResponse.Value, &zakat); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal zakat %s: %w", queryResponse.Key, err)
+		}
+		page.Records = append(page.Records, zakat)
+	}
+
+	// A short page is the last one
+	if metadata != nil && len(page.Records) == int(pageSize) {
+		page.Bookmark = metadata.Bookmark
+	}
+	return page, nil
+}
+
+// GetZakatByStatus returns zakat transactions by status
 func (s *SmartContract) GetZakatByStatus(ctx contractapi.TransactionContextInterface, status string) ([]Zakat, error) {
 	if err := validateStatus(status); err != nil {
 		return nil, err
@@ -747,169 +1880,847 @@ func (s *SmartContract) GetZakatByProgram(ctx contractapi.TransactionContextInte
 			return nil, err
 		}
 
-		var zakat Zakat
-		err = json.Unmarshal(queryResponse.Value, &zakat)
+		// Pledges and program distributions also carry the program ID
+		if strings.HasPrefix(queryResponse.Key, "PLG-") || strings.HasPrefix(queryResponse.Key, "PDIST-") {
+			continue
+		}
+
+		var zakat Zakat
+		err = json.Unmarshal(queryResponse.Value, &zakat)
+		if err != nil {
+			return nil, err
+		}
+		zakats = append(zakats, zakat)
+	}
+
+	return zakats, nil
+}
+
+// GetZakatByOfficer returns zakat transactions referred by an officer
+func (s *SmartContract) GetZakatByOfficer(ctx contractapi.TransactionContextInterface, referralCode string) ([]Zakat, error) {
+	queryString := fmt.Sprintf("{\"selector\":{\"referralCode\":\"%s\"}}", referralCode)
+	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query zakat by officer: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	var zakats []Zakat
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		// The officer record itself also matches the referral code selector
+		if strings.HasPrefix(queryResponse.Key, "OFF-") {
+			continue
+		}
+
+		var zakat Zakat
+		err = json.Unmarshal(queryResponse.Value, &zakat)
+		if err != nil {
+			return nil, err
+		}
+		zakats = append(zakats, zakat)
+	}
+
+	return zakats, nil
+}
+
+// GetZakatByOfficerID returns zakat transactions referred by an officer under any of their
+// current or previous referral codes
+func (s *SmartContract) GetZakatByOfficerID(ctx contractapi.TransactionContextInterface, officerID string) ([]Zakat, error) {
+	officer, err := s.readOfficer(ctx, officerID)
+	if err != nil {
+		return nil, err
+	}
+
+	zakats := []Zakat{}
+	codes := append([]string{officer.ReferralCode}, officer.PreviousReferralCodes...)
+	for _, code := range codes {
+		referred, err := s.GetZakatByOfficer(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+		zakats = append(zakats, referred...)
+	}
+
+	return zakats, nil
+}
+
+// GetZakatByMuzakki returns zakat transactions by muzakki name
+func (s *SmartContract) GetZakatByMuzakki(ctx contractapi.TransactionContextInterface, muzakkiName string) ([]Zakat, error) {
+	if muzakkiName == "" {
+		return nil, newInvalidInputError("muzakki", "muzakki name cannot be empty")
+	}
+
+	queryString := fmt.Sprintf("{\"selector\":{\"muzakki\":\"%s\"}}", muzakkiName)
+	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query zakat by muzakki: %w", err)
+	}
+	defer resultsIterator.Close()
+
+	var zakats []Zakat
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("error iterating over zakat by muzakki results: %w", err)
+		}
+
+		// Pledges also carry the muzakki
+		if strings.HasPrefix(queryResponse.Key, "PLG-") {
+			continue
+		}
+
+		var zakat Zakat
+		err = json.Unmarshal(queryResponse.Value, &zakat)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal zakat data for muzakki query: %w", err)
+		}
+		zakats = append(zakats, zakat)
+	}
+
+	if len(zakats) == 0 {
+		fmt.Printf("No zakat records found for muzakki: %s\n", muzakkiName)
+	} else {
+		fmt.Printf("Found %d zakat records for muzakki: %s\n", len(zakats), muzakkiName)
+	}
+
+	return zakats, nil
+}
+
+// DistributeZakat distributes collected zakat.
+// It updates the Zakat status to "distributed", records distribution details,
+// and updates the associated program's distributed amount.
+func (s *SmartContract) DistributeZakat(ctx contractapi.TransactionContextInterface, zakatID string, distributionID string, recipientName string, amount float64, distributionTimestamp string, distributedBy string) error {
+	// Validate inputs
+	if zakatID == "" {
+		return newInvalidInputError("zakatID", "zakat ID cannot be empty")
+	}
+	if distributionID == "" {
+		return newInvalidInputError("distributionID", "distribution ID cannot be empty")
+	}
+	if recipientName == "" {
+		return newInvalidInputError("mustahik", "recipient name (mustahik) cannot be empty")
+	}
+	if err := validateAmount(amount); err != nil { // amount must be > 0
+		return fmt.Errorf("invalid distribution amount: %w", err)
+	}
+	if err := validateTimestamp(distributionTimestamp); err != nil {
+		return fmt.Errorf("invalid distribution timestamp: %w", err)
+	}
+	if distributedBy == "" {
+		return newInvalidInputError("distributedBy", "distributedBy (admin/officer) cannot be empty")
+	}
+
+	zakat, err := s.QueryZakat(ctx, zakatID)
+	if err != nil {
+		return fmt.Errorf("failed to query zakat %s for distribution: %w", zakatID, err)
+	}
+
+	if zakat.Status != "collected" {
+		return newInvalidStateError("zakat %s must be in 'collected' status before distribution. Current status: %s", zakatID, zakat.Status)
+	}
+
+	// A single distribution event marks the Zakat "distributed". Program distributions may already
+	// have drawn part of it, in which case only the remaining balance can be distributed.
+	if amount > zakat.Amount {
+		return newInvalidStateError("distribution amount %.2f exceeds original zakat amount %.2f for Zakat ID %s", amount, zakat.Amount, zakatID)
+	}
+	if remaining := zakat.Amount - zakat.Distribution; amount > remaining+0.005 {
+		return newInvalidStateError("distribution amount %.2f exceeds remaining undistributed amount %.2f for Zakat ID %s", amount, remaining, zakatID)
+	}
+
+	// Update Zakat details for distribution
+	zakat.Status = "distributed" // Mark as fully distributed by this action
+	zakat.Mustahik = recipientName
+	zakat.Distribution += amount // Add this event to any amount already drawn by program distributions
+	zakat.DistributedAt = distributionTimestamp
+	zakat.DistributionID = distributionID
+	zakat.DistributedBy = distributedBy
+
+	// Update program distributed amount if ProgramID is present
+	if zakat.ProgramID != "" {
+		program, err := s.GetProgram(ctx, zakat.ProgramID)
+		if err != nil {
+			return fmt.Errorf("failed to get program %s for Zakat %s distribution update: %w", zakat.ProgramID, zakatID, err)
+		}
+		program.Distributed += amount // Add this distribution's amount to program's total distributed
+		programJSON, err := json.Marshal(program)
+		if err != nil {
+			return fmt.Errorf("failed to marshal updated program %s after distribution: %w", zakat.ProgramID, err)
+		}
+		err = ctx.GetStub().PutState(program.ID, programJSON)
+		if err != nil {
+			return fmt.Errorf("failed to put updated program %s to state after distribution: %w", program.ID, err)
+		}
+		fmt.Printf("Successfully updated program %s distributed amount.\n", program.ID)
+	}
+
+	zakatJSON, err := json.Marshal(zakat)
+	if err != nil {
+		return fmt.Errorf("failed to marshal updated zakat %s for distribution: %w", zakatID, err)
+	}
+
+	err = ctx.GetStub().PutState(zakatID, zakatJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put updated zakat %s to state after distribution: %w", zakatID, err)
+	}
+	fmt.Printf("Successfully distributed Zakat: %s (Distribution ID: %s) to Recipient: %s, Amount: %.2f by %s\n", zakatID, distributionID, recipientName, amount, distributedBy)
+	return nil
+}
+
+// DistributeFromProgram distributes an amount from a program's pooled funds to a mustahik.
+// The amount cannot exceed the program's Collected - Distributed balance and is allocated to the
+// program's collected zakat oldest first (by validation date), so each donor can see which
+// distributions their zakat paid for. A zakat whose whole amount has been allocated becomes "distributed".
+func (s *SmartContract) DistributeFromProgram(ctx contractapi.TransactionContextInterface, programID string, distributionID string, recipientName string, amount float64, distributionTimestamp string, distributedBy string) (*ProgramDistribution, error) {
+	if err := validateProgramID(programID); err != nil {
+		return nil, err
+	}
+	if distributionID == "" {
+		return nil, newInvalidInputError("distributionID", "distribution ID cannot be empty")
+	}
+	if recipientName == "" {
+		return nil, newInvalidInputError("mustahik", "recipient name (mustahik) cannot be empty")
+	}
+	if err := validateAmount(amount); err != nil {
+		return nil, fmt.Errorf("invalid distribution amount: %w", err)
+	}
+	if err := validateTimestamp(distributionTimestamp); err != nil {
+		return nil, fmt.Errorf("invalid distribution timestamp: %w", err)
+	}
+	if distributedBy == "" {
+		return nil, newInvalidInputError("distributedBy", "distributedBy (admin/officer) cannot be empty")
+	}
+
+	distributionKey := programDistributionKey(programID, distributionID)
+	existingJSON, err := ctx.GetStub().GetState(distributionKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read program distribution %s: %w", distributionID, err)
+	}
+	if existingJSON != nil {
+		return nil, newConflictError("program distribution %s already exists", distributionID)
+	}
+
+	program, err := s.GetProgram(ctx, programID)
+	if err != nil {
+		return nil, err
+	}
+	available := program.Collected - program.Distributed
+	if amount > available+0.005 {
+		return nil, newInvalidStateError("distribution amount %.2f exceeds available funds %.2f of program %s", amount, available, programID)
+	}
+
+	queryString := fmt.Sprintf("{\"selector\":{\"programID\":\"%s\",\"status\":\"collected\"}}", programID)
+	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query collected zakat of program %s: %v", programID, err)
+	}
+	defer resultsIterator.Close()
+
+	var zakats []Zakat
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		var zakat Zakat
+		if err := json.Unmarshal(queryResponse.Value, &zakat); err != nil {
+			return nil, err
+		}
+		zakats = append(zakats, zakat)
+	}
+
+	// Oldest collected first; RFC3339 timestamps in the same zone sort as strings
+	sort.SliceStable(zakats, func(i, j int) bool {
+		if zakats[i].ValidationDate != zakats[j].ValidationDate {
+			return zakats[i].ValidationDate < zakats[j].ValidationDate
+		}
+		if zakats[i].Timestamp != zakats[j].Timestamp {
+			return zakats[i].Timestamp < zakats[j].Timestamp
+		}
+		return zakats[i].ID < zakats[j].ID
+	})
+
+	distribution := ProgramDistribution{
+		ID:            distributionID,
+		ProgramID:     programID,
+		Mustahik:      recipientName,
+		Amount:        amount,
+		DistributedAt: distributionTimestamp,
+		DistributedBy: distributedBy,
+	}
+
+	left := amount
+	for _, zakat := range zakats {
+		if left < 0.005 {
+			break
+		}
+		remaining := math.Round((zakat.Amount-zakat.Distribution)*100) / 100
+		if remaining < 0.005 {
+			continue
+		}
+		share := math.Round(math.Min(remaining, left)*100) / 100
+
+		zakat.Distribution = math.Round((zakat.Distribution+share)*100) / 100
+		zakat.Allocations = append(zakat.Allocations, ZakatAllocation{
+			DistributionID: distributionID,
+			Mustahik:       recipientName,
+			Amount:         share,
+			DistributedAt:  distributionTimestamp,
+		})
+		if zakat.Amount-zakat.Distribution < 0.005 {
+			zakat.Status = "distributed"
+			zakat.DistributedAt = distributionTimestamp
+			zakat.DistributedBy = distributedBy
+		}
+
+		zakatJSON, err := json.Marshal(zakat)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal updated zakat %s for distribution: %w", zakat.ID, err)
+		}
+		if err := ctx.GetStub().PutState(zakat.ID, zakatJSON); err != nil {
+			return nil, fmt.Errorf("failed to put updated zakat %s to state after distribution: %w", zakat.ID, err)
+		}
+
+		distribution.Allocations = append(distribution.Allocations, DistributionAllocation{ZakatID: zakat.ID, Amount: share})
+		left = math.Round((left-share)*100) / 100
+	}
+	if left >= 0.005 {
+		return nil, newInvalidStateError("program %s has only %.2f undistributed on its collected zakat, %.2f short of the distribution", programID, amount-left, left)
+	}
+
+	program.Distributed += amount
+	programJSON, err := json.Marshal(program)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal updated program %s after distribution: %w", programID, err)
+	}
+	if err := ctx.GetStub().PutState(programID, programJSON); err != nil {
+		return nil, fmt.Errorf("failed to put updated program %s to state after distribution: %w", programID, err)
+	}
+
+	distributionJSON, err := json.Marshal(distribution)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal program distribution %s: %w", distributionID, err)
+	}
+	if err := ctx.GetStub().PutState(distributionKey, distributionJSON); err != nil {
+		return nil, fmt.Errorf("failed to put program distribution %s to state: %w", distributionID, err)
+	}
+
+	fmt.Printf("Successfully distributed %.2f from program %s (Distribution ID: %s) to Recipient: %s across %d zakat by %s\n",
+		amount, programID, distributionID, recipientName, len(distribution.Allocations), distributedBy)
+	return &distribution, nil
+}
+
+// GetProgramDistributions returns the distributions paid from a program's pooled funds
+func (s *SmartContract) GetProgramDistributions(ctx contractapi.TransactionContextInterface, programID string) ([]ProgramDistribution, error) {
+	prefix := programDistributionKey(programID, "")
+	resultsIterator, err := ctx.GetStub().GetStateByRange(prefix, prefix+"\uffff")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get distributions of program %s: %v", programID, err)
+	}
+	defer resultsIterator.Close()
+
+	distributions := []ProgramDistribution{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		var distribution ProgramDistribution
+		if err := json.Unmarshal(queryResponse.Value, &distribution); err != nil {
+			return nil, err
+		}
+		distributions = append(distributions, distribution)
+	}
+
+	return distributions, nil
+}
+
+// ReallocateZakat moves an undistributed zakat from its current program to another program.
+// Only "pending" and "collected" zakat can be moved. For collected zakat, the amount is
+// subtracted from the old program's Collected total and added to the new program's total
+// in the same transaction. The reason and approver are appended to the zakat's reallocation history.
+func (s *SmartContract) ReallocateZakat(ctx contractapi.TransactionContextInterface, zakatID string, newProgramID string, reason string, approvedBy string) error {
+	if zakatID == "" {
+		return newInvalidInputError("zakatID", "zakat ID cannot be empty")
+	}
+	if err := validateProgramID(newProgramID); err != nil {
+		return fmt.Errorf("invalid target program ID format for '%s': %w", newProgramID, err)
+	}
+	if reason == "" {
+		return newInvalidInputError("reason", "reallocation reason cannot be empty")
+	}
+	if approvedBy == "" {
+		return newInvalidInputError("approvedBy", "approvedBy (admin user) cannot be empty")
+	}
+
+	zakat, err := s.QueryZakat(ctx, zakatID)
+	if err != nil {
+		return fmt.Errorf("failed to query zakat %s for reallocation: %w", zakatID, err)
+	}
+
+	if zakat.Status != "pending" && zakat.Status != "collected" {
+		return newInvalidStateError("zakat %s cannot be reallocated in '%s' status. Only undistributed zakat can be moved", zakatID, zakat.Status)
+	}
+	if zakat.Distribution > 0 {
+		return newInvalidStateError("zakat %s has already been partially distributed and cannot be reallocated", zakatID)
+	}
+	if zakat.ProgramID == newProgramID {
+		return newInvalidStateError("zakat %s already belongs to program %s", zakatID, newProgramID)
+	}
+
+	newProgram, err := s.GetProgram(ctx, newProgramID)
+	if err != nil {
+		return fmt.Errorf("failed to get target program %s: %w", newProgramID, err)
+	}
+	if newProgram.Status != "active" {
+		return newInvalidStateError("target program %s is not active, current status: %s", newProgramID, newProgram.Status)
+	}
+
+	// Only collected zakat is counted in program totals; pending zakat just changes program.
+	if zakat.Status == "collected" {
+		if zakat.ProgramID != "" {
+			oldProgram, err := s.GetProgram(ctx, zakat.ProgramID)
+			if err != nil {
+				return fmt.Errorf("failed to get current program %s for Zakat %s: %w", zakat.ProgramID, zakatID, err)
+			}
+			oldProgram.Collected -= zakat.Amount
+			oldProgramJSON, err := json.Marshal(oldProgram)
+			if err != nil {
+				return fmt.Errorf("failed to marshal updated program %s: %w", oldProgram.ID, err)
+			}
+			err = ctx.GetStub().PutState(oldProgram.ID, oldProgramJSON)
+			if err != nil {
+				return fmt.Errorf("failed to put updated program %s to state: %w", oldProgram.ID, err)
+			}
+		}
+
+		newProgram.Collected += zakat.Amount
+		newProgramJSON, err := json.Marshal(newProgram)
+		if err != nil {
+			return fmt.Errorf("failed to marshal updated program %s: %w", newProgram.ID, err)
+		}
+		err = ctx.GetStub().PutState(newProgram.ID, newProgramJSON)
+		if err != nil {
+			return fmt.Errorf("failed to put updated program %s to state: %w", newProgram.ID, err)
+		}
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	zakat.Reallocations = append(zakat.Reallocations, ZakatReallocation{
+		FromProgramID: zakat.ProgramID,
+		ToProgramID:   newProgramID,
+		Reason:        reason,
+		ApprovedBy:    approvedBy,
+		ReallocatedAt: now.Format(time.RFC3339),
+	})
+	zakat.ProgramID = newProgramID
+
+	zakatJSON, err := json.Marshal(zakat)
+	if err != nil {
+		return fmt.Errorf("failed to marshal updated zakat %s: %w", zakatID, err)
+	}
+
+	err = ctx.GetStub().PutState(zakatID, zakatJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put updated zakat %s to state after reallocation: %w", zakatID, err)
+	}
+	fmt.Printf("Successfully reallocated Zakat: %s to program %s (approved by %s)\n", zakatID, newProgramID, approvedBy)
+	return nil
+}
+
+// ZakatExists checks if zakat exists
+func (s *SmartContract) ZakatExists(ctx contractapi.TransactionContextInterface, id string) (bool, error) {
+	zakatJSON, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return false, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	return zakatJSON != nil, nil
+}
+
+// FITRAH RATE MANAGEMENT FUNCTIONS
+
+// SetFitrahRate sets the zakat fitrah rate per soul for a region and Hijri year.
+// Setting a rate that already exists replaces it; zakat recorded earlier keeps the rate it was checked against.
+func (s *SmartContract) SetFitrahRate(ctx contractapi.TransactionContextInterface, region string, hijriYear int, ratePerSoul float64, riceKgPerSoul float64, setBy string) error {
+	region = strings.ToUpper(region)
+	if err := validateFitrahRegion(region); err != nil {
+		return err
+	}
+	if err := validateHijriYear(hijriYear); err != nil {
+		return err
+	}
+	if err := validateAmount(ratePerSoul); err != nil {
+		return err
+	}
+	if riceKgPerSoul < 0 {
+		return newInvalidInputError("riceKgPerSoul", "rice equivalent cannot be negative")
+	}
+	if setBy == "" {
+		return newInvalidInputError("setBy", "setBy (admin user) cannot be empty")
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	rate := FitrahRate{
+		ID:            fitrahRateKey(region, hijriYear),
+		Region:        region,
+		HijriYear:     hijriYear,
+		RatePerSoul:   ratePerSoul,
+		RiceKgPerSoul: riceKgPerSoul,
+		SetBy:         setBy,
+		UpdatedAt:     now.Format(time.RFC3339),
+	}
+
+	rateJSON, err := json.Marshal(rate)
+	if err != nil {
+		return fmt.Errorf("failed to marshal fitrah rate: %w", err)
+	}
+
+	err = ctx.GetStub().PutState(rate.ID, rateJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put fitrah rate %s to state: %w", rate.ID, err)
+	}
+	fmt.Printf("Successfully set fitrah rate %s: %.2f per soul\n", rate.ID, ratePerSoul)
+	return nil
+}
+
+// GetFitrahRate returns the zakat fitrah rate for a region and Hijri year.
+// A hijriYear of 0 means the year of the transaction's timestamp.
+func (s *SmartContract) GetFitrahRate(ctx contractapi.TransactionContextInterface, region string, hijriYear int) (FitrahRate, error) {
+	region = strings.ToUpper(region)
+	if hijriYear == 0 {
+		now, err := txTime(ctx)
+		if err != nil {
+			return FitrahRate{}, err
+		}
+		hijriYear = hijriYearOf(now)
+	}
+
+	rateJSON, err := ctx.GetStub().GetState(fitrahRateKey(region, hijriYear))
+	if err != nil {
+		return FitrahRate{}, fmt.Errorf("failed to read fitrah rate: %v", err)
+	}
+	if rateJSON == nil {
+		return FitrahRate{}, newNotFoundError("no fitrah rate set for region %s in %dH", region, hijriYear)
+	}
+
+	var rate FitrahRate
+	err = json.Unmarshal(rateJSON, &rate)
+	if err != nil {
+		return FitrahRate{}, fmt.Errorf("failed to unmarshal fitrah rate: %v", err)
+	}
+
+	return rate, nil
+}
+
+// EXCHANGE RATE MANAGEMENT FUNCTIONS
+
+// PublishExchangeRate records the IDR value of one unit of a currency or commodity from effectiveDate
+// (YYYY-MM-DD) onwards. Only clients whose certificate carries the zakat.ratePublisher=true attribute
+// may publish; a rate for the same day is overwritten, zakat already recorded keeps the rate it used.
+func (s *SmartContract) PublishExchangeRate(ctx contractapi.TransactionContextInterface, denomination string, rateIDR float64, effectiveDate string, source string) error {
+	if err := ctx.GetClientIdentity().AssertAttributeValue(ratePublisherAttribute, "true"); err != nil {
+		return newUnauthorizedError("client is not authorized to publish exchange rates: %v", err)
+	}
+	publisher, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to get publisher identity: %v", err)
+	}
+
+	denomination = strings.ToUpper(denomination)
+	if err := validateDenomination(denomination); err != nil {
+		return err
+	}
+	if denomination == baseDenomination {
+		return newInvalidInputError("denomination", "cannot publish a rate for %s", baseDenomination)
+	}
+	if err := validateAmount(rateIDR); err != nil {
+		return err
+	}
+	if _, err := time.Parse("2006-01-02", effectiveDate); err != nil {
+		return fmt.Errorf("invalid effective date. Use YYYY-MM-DD: %w", err)
+	}
+	if source == "" {
+		return newInvalidInputError("source", "rate source cannot be empty")
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	rate := ExchangeRate{
+		ID:            exchangeRateKey(denomination, effectiveDate),
+		Denomination:  denomination,
+		RateIDR:       rateIDR,
+		EffectiveDate: effectiveDate,
+		Source:        source,
+		PublishedBy:   publisher,
+		PublishedAt:   now.Format(time.RFC3339),
+	}
+
+	rateJSON, err := json.Marshal(rate)
+	if err != nil {
+		return fmt.Errorf("failed to marshal exchange rate: %w", err)
+	}
+
+	err = ctx.GetStub().PutState(rate.ID, rateJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put exchange rate %s to state: %w", rate.ID, err)
+	}
+
+	fmt.Printf("Successfully published exchange rate %s: %.2f IDR\n", rate.ID, rateIDR)
+	return nil
+}
+
+// GetExchangeRate returns the latest rate for a denomination effective on date (YYYY-MM-DD, empty for the
+// day of the transaction timestamp).
+// Rates more than 7 days older than date are considered stale and are not returned.
+func (s *SmartContract) GetExchangeRate(ctx contractapi.TransactionContextInterface, denomination string, date string) (ExchangeRate, error) {
+	denomination = strings.ToUpper(denomination)
+	if err := validateDenomination(denomination); err != nil {
+		return ExchangeRate{}, err
+	}
+
+	var day time.Time
+	if date != "" {
+		var err error
+		if day, err = time.Parse("2006-01-02", date); err != nil {
+			return ExchangeRate{}, fmt.Errorf("invalid date. Use YYYY-MM-DD: %w", err)
+		}
+	} else {
+		now, err := txTime(ctx)
 		if err != nil {
-			return nil, err
+			return ExchangeRate{}, err
 		}
-		zakats = append(zakats, zakat)
+		day = now.Truncate(24 * time.Hour)
 	}
 
-	return zakats, nil
-}
-
-// GetZakatByOfficer returns zakat transactions referred by an officer
-func (s *SmartContract) GetZakatByOfficer(ctx contractapi.TransactionContextInterface, referralCode string) ([]Zakat, error) {
-	queryString := fmt.Sprintf("{\"selector\":{\"referralCode\":\"%s\"}}", referralCode)
-	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	prefix := exchangeRateKey(denomination, "")
+	resultsIterator, err := ctx.GetStub().GetStateByRange(prefix, prefix+"\uffff")
 	if err != nil {
-		return nil, fmt.Errorf("failed to query zakat by officer: %v", err)
+		return ExchangeRate{}, fmt.Errorf("failed to read exchange rates: %v", err)
 	}
 	defer resultsIterator.Close()
 
-	var zakats []Zakat
+	// Keys sort by effective date, so the last one not after day is the rate in force
+	var latest *ExchangeRate
 	for resultsIterator.HasNext() {
 		queryResponse, err := resultsIterator.Next()
 		if err != nil {
-			return nil, err
+			return ExchangeRate{}, err
 		}
 
-		var zakat Zakat
-		err = json.Unmarshal(queryResponse.Value, &zakat)
-		if err != nil {
-			return nil, err
+		var rate ExchangeRate
+		if err := json.Unmarshal(queryResponse.Value, &rate); err != nil {
+			return ExchangeRate{}, err
 		}
-		zakats = append(zakats, zakat)
+		if rate.EffectiveDate > day.Format("2006-01-02") {
+			break
+		}
+		latest = &rate
 	}
 
-	return zakats, nil
+	if latest == nil {
+		return ExchangeRate{}, newNotFoundError("no %s exchange rate published as of %s", denomination, day.Format("2006-01-02"))
+	}
+	effective, _ := time.Parse("2006-01-02", latest.EffectiveDate)
+	if day.Sub(effective) > maxExchangeRateAge {
+		return ExchangeRate{}, newInvalidStateError("latest %s exchange rate is from %s and is too old to use on %s", denomination, latest.EffectiveDate, day.Format("2006-01-02"))
+	}
+
+	return *latest, nil
 }
 
-// GetZakatByMuzakki returns zakat transactions by muzakki name
-func (s *SmartContract) GetZakatByMuzakki(ctx contractapi.TransactionContextInterface, muzakkiName string) ([]Zakat, error) {
-	if muzakkiName == "" {
-		return nil, fmt.Errorf("muzakki name cannot be empty")
+// PLEDGE MANAGEMENT FUNCTIONS
+
+// CreatePledge records a muzakki's commitment to pay amount every period of the schedule, starting at startDate.
+// The pledge itself moves no funds; each scheduled payment is a regular zakat transaction that is
+// linked to the pledge with RecordPledgeFulfilment once collected.
+func (s *SmartContract) CreatePledge(ctx contractapi.TransactionContextInterface, id string, muzakki string, amount float64, zakatType string, schedule string, programID string, organization string, startDate string) error {
+	if err := validatePledgeID(id); err != nil {
+		return err
+	}
+	if muzakki == "" {
+		return newInvalidInputError("muzakki", "muzakki name cannot be empty")
+	}
+	if err := validateAmount(amount); err != nil {
+		return err
+	}
+	if err := validateZakatType(zakatType); err != nil {
+		return err
+	}
+	if err := validatePledgeSchedule(schedule); err != nil {
+		return err
+	}
+	if err := validateOrganization(organization); err != nil {
+		return err
+	}
+	if err := validateTimestamp(startDate); err != nil {
+		return err
 	}
 
-	queryString := fmt.Sprintf("{\"selector\":{\"muzakki\":\"%s\"}}", muzakkiName)
-	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	exists, err := ctx.GetStub().GetState(id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query zakat by muzakki: %w", err)
+		return fmt.Errorf("failed to check pledge existence: %v", err)
+	}
+	if exists != nil {
+		return newConflictError("pledge %s already exists", id)
 	}
-	defer resultsIterator.Close()
 
-	var zakats []Zakat
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
+	if programID != "" {
+		program, err := s.GetProgram(ctx, programID)
 		if err != nil {
-			return nil, fmt.Errorf("error iterating over zakat by muzakki results: %w", err)
+			return err
 		}
-
-		var zakat Zakat
-		err = json.Unmarshal(queryResponse.Value, &zakat)
-		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal zakat data for muzakki query: %w", err)
+		if program.Status != "active" {
+			return newInvalidStateError("program %s is not active", programID)
 		}
-		zakats = append(zakats, zakat)
 	}
 
-	if len(zakats) == 0 {
-		fmt.Printf("No zakat records found for muzakki: %s\n", muzakkiName)
-	} else {
-		fmt.Printf("Found %d zakat records for muzakki: %s\n", len(zakats), muzakkiName)
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	pledge := Pledge{
+		ID:             id,
+		Muzakki:        muzakki,
+		Amount:         amount,
+		Type:           zakatType,
+		Schedule:       schedule,
+		ProgramID:      programID,
+		Organization:   organization,
+		Status:         "active",
+		StartDate:      startDate,
+		TotalFulfilled: 0,
+		CreatedAt:      now.Format(time.RFC3339),
 	}
 
-	return zakats, nil
+	pledgeJSON, err := json.Marshal(pledge)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState(id, pledgeJSON)
 }
 
-// DistributeZakat distributes collected zakat.
-// It updates the Zakat status to "distributed", records distribution details,
-// and updates the associated program's distributed amount.
-func (s *SmartContract) DistributeZakat(ctx contractapi.TransactionContextInterface, zakatID string, distributionID string, recipientName string, amount float64, distributionTimestamp string, distributedBy string) error {
-	// Validate inputs
-	if zakatID == "" {
-		return fmt.Errorf("zakat ID cannot be empty")
+// GetPledge returns a pledge by ID
+func (s *SmartContract) GetPledge(ctx contractapi.TransactionContextInterface, id string) (Pledge, error) {
+	pledgeJSON, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return Pledge{}, fmt.Errorf("failed to read pledge: %v", err)
 	}
-	if distributionID == "" {
-		return fmt.Errorf("distribution ID cannot be empty")
+	if pledgeJSON == nil {
+		return Pledge{}, newNotFoundError("pledge %s does not exist", id)
 	}
-	if recipientName == "" {
-		return fmt.Errorf("recipient name (mustahik) cannot be empty")
+
+	var pledge Pledge
+	err = json.Unmarshal(pledgeJSON, &pledge)
+	if err != nil {
+		return Pledge{}, fmt.Errorf("failed to unmarshal pledge: %v", err)
 	}
-	if err := validateAmount(amount); err != nil { // amount must be > 0
-		return fmt.Errorf("invalid distribution amount: %w", err)
+
+	return pledge, nil
+}
+
+// RecordPledgeFulfilment links a collected zakat transaction to a pledge period.
+// Each period and each zakat can only be recorded once per pledge.
+func (s *SmartContract) RecordPledgeFulfilment(ctx contractapi.TransactionContextInterface, pledgeID string, zakatID string, period string) error {
+	if period == "" {
+		return newInvalidInputError("period", "pledge period cannot be empty")
 	}
-	if err := validateTimestamp(distributionTimestamp); err != nil {
-		return fmt.Errorf("invalid distribution timestamp: %w", err)
+
+	pledge, err := s.GetPledge(ctx, pledgeID)
+	if err != nil {
+		return err
 	}
-	if distributedBy == "" {
-		return fmt.Errorf("distributedBy (admin/officer) cannot be empty")
+	if pledge.Status != "active" {
+		return newInvalidStateError("pledge %s is not active, current status: %s", pledgeID, pledge.Status)
 	}
 
 	zakat, err := s.QueryZakat(ctx, zakatID)
 	if err != nil {
-		return fmt.Errorf("failed to query zakat %s for distribution: %w", zakatID, err)
+		return fmt.Errorf("failed to query zakat %s for pledge fulfilment: %w", zakatID, err)
 	}
-
-	if zakat.Status != "collected" {
-		return fmt.Errorf("zakat %s must be in 'collected' status before distribution. Current status: %s", zakatID, zakat.Status)
+	if zakat.Status != "collected" && zakat.Status != "distributed" {
+		return newInvalidStateError("zakat %s has not been collected yet, current status: %s", zakatID, zakat.Status)
 	}
-
-	// The current model assumes a single distribution event per Zakat record changes its status to "distributed".
-	// If partial distributions were allowed while keeping Zakat "collected", logic would need to track remaining balance.
-	// For now, this 'amount' is the amount of *this* distribution.
-	// We should ensure this distribution amount does not exceed the original Zakat amount.
-	if amount > zakat.Amount {
-		return fmt.Errorf("distribution amount %.2f exceeds original zakat amount %.2f for Zakat ID %s", amount, zakat.Amount, zakatID)
+	if zakat.Type != pledge.Type {
+		return newInvalidStateError("zakat %s is of type '%s' but pledge %s is for '%s'", zakatID, zakat.Type, pledgeID, pledge.Type)
 	}
-	// If there were previous distributions on this Zakat (not current model), this check would be more complex.
-
-	// Update Zakat details for distribution
-	zakat.Status = "distributed" // Mark as fully distributed by this action
-	zakat.Mustahik = recipientName
-	zakat.Distribution = amount // Record the amount that was distributed in this event
-	zakat.DistributedAt = distributionTimestamp
-	zakat.DistributionID = distributionID
-	zakat.DistributedBy = distributedBy
 
-	// Update program distributed amount if ProgramID is present
-	if zakat.ProgramID != "" {
-		program, err := s.GetProgram(ctx, zakat.ProgramID)
-		if err != nil {
-			return fmt.Errorf("failed to get program %s for Zakat %s distribution update: %w", zakat.ProgramID, zakatID, err)
-		}
-		program.Distributed += amount // Add this distribution's amount to program's total distributed
-		programJSON, err := json.Marshal(program)
-		if err != nil {
-			return fmt.Errorf("failed to marshal updated program %s after distribution: %w", zakat.ProgramID, err)
+	for _, fulfilment := range pledge.Fulfilments {
+		if fulfilment.ZakatID == zakatID {
+			return newConflictError("zakat %s is already recorded against pledge %s", zakatID, pledgeID)
 		}
-		err = ctx.GetStub().PutState(program.ID, programJSON)
-		if err != nil {
-			return fmt.Errorf("failed to put updated program %s to state after distribution: %w", program.ID, err)
+		if fulfilment.Period == period {
+			return newConflictError("period %s of pledge %s is already fulfilled by zakat %s", period, pledgeID, fulfilment.ZakatID)
 		}
-		fmt.Printf("Successfully updated program %s distributed amount.\n", program.ID)
 	}
 
-	zakatJSON, err := json.Marshal(zakat)
+	now, err := txTime(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to marshal updated zakat %s for distribution: %w", zakatID, err)
+		return err
 	}
+	pledge.Fulfilments = append(pledge.Fulfilments, PledgeFulfilment{
+		ZakatID:     zakatID,
+		Period:      period,
+		Amount:      zakat.Amount,
+		FulfilledAt: now.Format(time.RFC3339),
+	})
+	pledge.TotalFulfilled += zakat.Amount
 
-	err = ctx.GetStub().PutState(zakatID, zakatJSON)
+	pledgeJSON, err := json.Marshal(pledge)
 	if err != nil {
-		return fmt.Errorf("failed to put updated zakat %s to state after distribution: %w", zakatID, err)
+		return fmt.Errorf("failed to marshal updated pledge %s: %w", pledgeID, err)
 	}
-	fmt.Printf("Successfully distributed Zakat: %s (Distribution ID: %s) to Recipient: %s, Amount: %.2f by %s\n", zakatID, distributionID, recipientName, amount, distributedBy)
+
+	err = ctx.GetStub().PutState(pledgeID, pledgeJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put updated pledge %s to state: %w", pledgeID, err)
+	}
+	fmt.Printf("Successfully recorded zakat %s as period %s of pledge %s\n", zakatID, period, pledgeID)
 	return nil
 }
 
-// ZakatExists checks if zakat exists
-func (s *SmartContract) ZakatExists(ctx contractapi.TransactionContextInterface, id string) (bool, error) {
-	zakatJSON, err := ctx.GetStub().GetState(id)
+// CancelPledge stops an active pledge. Fulfilments recorded so far are kept.
+func (s *SmartContract) CancelPledge(ctx contractapi.TransactionContextInterface, pledgeID string, reason string) error {
+	pledge, err := s.GetPledge(ctx, pledgeID)
 	if err != nil {
-		return false, fmt.Errorf("failed to read from world state: %v", err)
+		return err
 	}
-	return zakatJSON != nil, nil
+	if pledge.Status != "active" {
+		return newInvalidStateError("pledge %s is not active, current status: %s", pledgeID, pledge.Status)
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	pledge.Status = "cancelled"
+	pledge.CancelledAt = now.Format(time.RFC3339)
+	pledge.CancelReason = reason
+
+	pledgeJSON, err := json.Marshal(pledge)
+	if err != nil {
+		return fmt.Errorf("failed to marshal updated pledge %s: %w", pledgeID, err)
+	}
+
+	err = ctx.GetStub().PutState(pledgeID, pledgeJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put updated pledge %s to state: %w", pledgeID, err)
+	}
+	fmt.Printf("Successfully cancelled pledge %s\n", pledgeID)
+	return nil
 }
 
 // REPORTING FUNCTIONS
@@ -946,6 +2757,7 @@ func (s *SmartContract) GetDailyReport(ctx contractapi.TransactionContextInterfa
 	var transactionCount int
 	var byType = make(map[string]float64)
 	var byProgram = make(map[string]float64) // Keyed by ProgramID, value is sum of amounts
+	var byDenomination = make(map[string]DenominationTotal)
 
 	for resultsIterator.HasNext() {
 		queryResponse, err := resultsIterator.Next()
@@ -970,6 +2782,17 @@ func (s *SmartContract) GetDailyReport(ctx contractapi.TransactionContextInterfa
 			} else {
 				byProgram["<No Program>"] += zakat.Amount // Group Zakat not tied to a program
 			}
+
+			// Totals above are in IDR; keep what donors actually gave per denomination alongside
+			denomination, original := zakat.Denomination, zakat.OriginalAmount
+			if denomination == "" {
+				denomination, original = baseDenomination, zakat.Amount
+			}
+			total := byDenomination[denomination]
+			total.OriginalAmount += original
+			total.AmountIDR += zakat.Amount
+			total.Count++
+			byDenomination[denomination] = total
 		}
 	}
 
@@ -979,11 +2802,161 @@ func (s *SmartContract) GetDailyReport(ctx contractapi.TransactionContextInterfa
 		"transactionCount": transactionCount,
 		"byType":           byType,
 		"byProgram":        byProgram,
+		"byDenomination":   byDenomination,
 	}
 
 	return report, nil
 }
 
+// GetOfficerLeaderboard ranks the officers who referred zakat to an organization between
+// startDate and endDate (YYYY-MM-DD, both inclusive) by the amount their referrals paid
+func (s *SmartContract) GetOfficerLeaderboard(ctx contractapi.TransactionContextInterface, organization string, startDate string, endDate string) ([]OfficerPerformance, error) {
+	return s.rankOfficers(ctx, organization, startDate, endDate)
+}
+
+// GetOfficerPerformance returns one officer's referral performance and rank within an
+// organization between startDate and endDate (YYYY-MM-DD, both inclusive). An officer without
+// referrals in the period gets zero figures and rank 0.
+func (s *SmartContract) GetOfficerPerformance(ctx contractapi.TransactionContextInterface, officerID string, organization string, startDate string, endDate string) (OfficerPerformance, error) {
+	if err := validateOfficerID(officerID); err != nil {
+		return OfficerPerformance{}, err
+	}
+	officer, err := s.readOfficer(ctx, officerID)
+	if err != nil {
+		return OfficerPerformance{}, err
+	}
+
+	leaderboard, err := s.rankOfficers(ctx, organization, startDate, endDate)
+	if err != nil {
+		return OfficerPerformance{}, err
+	}
+
+	for _, performance := range leaderboard {
+		if performance.OfficerID == officerID {
+			return performance, nil
+		}
+	}
+	return OfficerPerformance{
+		OfficerID:      officer.ID,
+		Name:           officer.Name,
+		ReferralCode:   officer.ReferralCode,
+		Organization:   organization,
+		StartDate:      startDate,
+		EndDate:        endDate,
+		RankedOfficers: len(leaderboard),
+	}, nil
+}
+
+// rankOfficers computes the performance of every officer with referrals submitted to an
+// organization in the period, ranked by amount collected, then collected count, then officer ID
+func (s *SmartContract) rankOfficers(ctx contractapi.TransactionContextInterface, organization string, startDate string, endDate string) ([]OfficerPerformance, error) {
+	if err := validateOrganization(organization); err != nil {
+		return nil, err
+	}
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, newInvalidInputError("startDate", "invalid start date %s. Use YYYY-MM-DD", startDate)
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, newInvalidInputError("endDate", "invalid end date %s. Use YYYY-MM-DD", endDate)
+	}
+	if end.Before(start) {
+		return nil, newInvalidInputError("endDate", "end date %s is before start date %s", endDate, startDate)
+	}
+	end = end.AddDate(0, 0, 1)
+
+	officers, err := s.GetAllOfficers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get officers: %w", err)
+	}
+	// Rotated referral codes keep crediting the officer who held them
+	officerByCode := make(map[string]Officer)
+	for _, officer := range officers {
+		officerByCode[officer.ReferralCode] = officer
+		for _, code := range officer.PreviousReferralCodes {
+			officerByCode[code] = officer
+		}
+	}
+
+	queryString := fmt.Sprintf("{\"selector\":{\"organization\":\"%s\",\"referralCode\":{\"$gt\":\"\"}}}", organization)
+	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query referred zakat: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	performances := make(map[string]*OfficerPerformance)
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(queryResponse.Key, "ZKT-") {
+			continue
+		}
+
+		var zakat Zakat
+		if err := json.Unmarshal(queryResponse.Value, &zakat); err != nil {
+			return nil, err
+		}
+		submitted, err := time.Parse(time.RFC3339, zakat.Timestamp)
+		if err != nil || submitted.Before(start) || !submitted.Before(end) {
+			continue
+		}
+		officer, ok := officerByCode[zakat.ReferralCode]
+		if !ok {
+			continue
+		}
+
+		performance := performances[officer.ID]
+		if performance == nil {
+			performance = &OfficerPerformance{
+				OfficerID:    officer.ID,
+				Name:         officer.Name,
+				ReferralCode: officer.ReferralCode,
+				Organization: organization,
+				StartDate:    startDate,
+				EndDate:      endDate,
+			}
+			performances[officer.ID] = performance
+		}
+		performance.DonationCount++
+		performance.AmountReferred += zakat.Amount
+		if zakat.Status == "pending" {
+			performance.PendingCount++
+		} else {
+			performance.CollectedCount++
+			performance.AmountCollected += zakat.Amount
+		}
+	}
+
+	leaderboard := []OfficerPerformance{}
+	for _, performance := range performances {
+		performance.ConversionRate = math.Round(float64(performance.CollectedCount)/float64(performance.DonationCount)*10000) / 10000
+		if performance.CollectedCount > 0 {
+			performance.AverageTicket = math.Round(performance.AmountCollected/float64(performance.CollectedCount)*100) / 100
+		}
+		leaderboard = append(leaderboard, *performance)
+	}
+	sort.Slice(leaderboard, func(i, j int) bool {
+		a, b := leaderboard[i], leaderboard[j]
+		if a.AmountCollected != b.AmountCollected {
+			return a.AmountCollected > b.AmountCollected
+		}
+		if a.CollectedCount != b.CollectedCount {
+			return a.CollectedCount > b.CollectedCount
+		}
+		return a.OfficerID < b.OfficerID
+	})
+	for i := range leaderboard {
+		leaderboard[i].Rank = i + 1
+		leaderboard[i].RankedOfficers = len(leaderboard)
+	}
+
+	return leaderboard, nil
+}
+
 // ClearAllZakat removes all Zakat records from the ledger
 func (s *SmartContract) ClearAllZakat(ctx contractapi.TransactionContextInterface) error {
 	resultsIterator, err := ctx.GetStub().GetStateByRange("ZKT-", "ZKT-\uffff")
@@ -1036,7 +3009,7 @@ func (s *SmartContract) ClearAllPrograms(ctx contractapi.TransactionContextInter
 	return nil
 }
 
-// ClearAllOfficers removes all Officer records from the ledger
+// ClearAllOfficers removes all Officer records and their referral code index from the ledger
 func (s *SmartContract) ClearAllOfficers(ctx contractapi.TransactionContextInterface) error {
 	resultsIterator, err := ctx.GetStub().GetStateByRange("OFF-", "OFF-\uffff")
 	if err != nil {
@@ -1058,6 +3031,25 @@ func (s *SmartContract) ClearAllOfficers(ctx contractapi.TransactionContextInter
 		deletedCount++
 	}
 
+	// Their referral codes can be used again
+	indexIterator, err := ctx.GetStub().GetStateByRange("REFCODE-", "REFCODE-\uffff")
+	if err != nil {
+		return fmt.Errorf("failed to get referral code index for deletion: %w", err)
+	}
+	defer indexIterator.Close()
+
+	for indexIterator.HasNext() {
+		queryResponse, err := indexIterator.Next()
+		if err != nil {
+			return fmt.Errorf("failed to iterate referral code index for deletion: %w", err)
+		}
+
+		err = ctx.GetStub().DelState(queryResponse.Key)
+		if err != nil {
+			return fmt.Errorf("failed to delete referral code index %s: %w", queryResponse.Key, err)
+		}
+	}
+
 	fmt.Printf("Successfully deleted %d Officer records\n", deletedCount)
 	return nil
 }
@@ -1128,7 +3120,7 @@ func (s *SmartContract) UpdateOfficerStatus(ctx contractapi.TransactionContextIn
 		return fmt.Errorf("failed to read officer: %v", err)
 	}
 	if officerJSON == nil {
-		return fmt.Errorf("officer %s does not exist", officerID)
+		return newNotFoundError("officer %s does not exist", officerID)
 	}
 
 	var officer Officer
@@ -1152,6 +3144,66 @@ func (s *SmartContract) UpdateOfficerStatus(ctx contractapi.TransactionContextIn
 	return nil
 }
 
+// CHAINCODE SERVER FUNCTIONS
+
+// newChaincodeServer configures chaincode-as-a-service mode from the environment.
+// It returns nil when CHAINCODE_SERVER_ADDRESS is unset, in which case the peer launches the chaincode.
+//
+//	CHAINCODE_SERVER_ADDRESS  listen address, e.g. 0.0.0.0:9999
+//	CHAINCODE_ID              package ID the chaincode was installed as, e.g. zakat_2.1:abc123...
+//	CHAINCODE_TLS_DISABLED    "true" to serve without TLS (development only)
+//	CHAINCODE_TLS_KEY         server private key file (PEM)
+//	CHAINCODE_TLS_CERT        server certificate file (PEM)
+//	CHAINCODE_CLIENT_CA_CERT  CA certificate file (PEM) used to require and verify peer client certificates (optional)
+func newChaincodeServer(cc shim.Chaincode, getenv func(string) string) (*shim.ChaincodeServer, error) {
+	address := getenv("CHAINCODE_SERVER_ADDRESS")
+	if address == "" {
+		return nil, nil
+	}
+
+	ccid := getenv("CHAINCODE_ID")
+	if ccid == "" {
+		return nil, fmt.Errorf("CHAINCODE_ID is required when CHAINCODE_SERVER_ADDRESS is set")
+	}
+
+	tlsDisabled := false
+	if value := getenv("CHAINCODE_TLS_DISABLED"); value != "" {
+		var err error
+		tlsDisabled, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CHAINCODE_TLS_DISABLED %s: %v", value, err)
+		}
+	}
+
+	tlsProps := shim.TLSProperties{Disabled: tlsDisabled}
+	if !tlsDisabled {
+		keyPath, certPath := getenv("CHAINCODE_TLS_KEY"), getenv("CHAINCODE_TLS_CERT")
+		if keyPath == "" || certPath == "" {
+			return nil, fmt.Errorf("CHAINCODE_TLS_KEY and CHAINCODE_TLS_CERT are required unless CHAINCODE_TLS_DISABLED is true")
+		}
+
+		var err error
+		if tlsProps.Key, err = os.ReadFile(keyPath); err != nil {
+			return nil, fmt.Errorf("failed to read TLS key: %v", err)
+		}
+		if tlsProps.Cert, err = os.ReadFile(certPath); err != nil {
+			return nil, fmt.Errorf("failed to read TLS certificate: %v", err)
+		}
+		if caPath := getenv("CHAINCODE_CLIENT_CA_CERT"); caPath != "" {
+			if tlsProps.ClientCACerts, err = os.ReadFile(caPath); err != nil {
+				return nil, fmt.Errorf("failed to read client CA certificate: %v", err)
+			}
+		}
+	}
+
+	return &shim.ChaincodeServer{
+		CCID:     ccid,
+		Address:  address,
+		CC:       cc,
+		TLSProps: tlsProps,
+	}, nil
+}
+
 func main() {
 	chaincode, err := contractapi.NewChaincode(&SmartContract{})
 	if err != nil {
@@ -1159,6 +3211,20 @@ func main() {
 		return
 	}
 
+	server, err := newChaincodeServer(chaincode, os.Getenv)
+	if err != nil {
+		fmt.Printf("Error configuring zakat chaincode server: %s", err.Error())
+		return
+	}
+
+	if server != nil {
+		fmt.Printf("Starting zakat chaincode server on %s (TLS disabled: %t)\n", server.Address, server.TLSProps.Disabled)
+		if err := server.Start(); err != nil {
+			fmt.Printf("Error starting zakat chaincode server: %s", err.Error())
+		}
+		return
+	}
+
 	if err := chaincode.Start(); err != nil {
 		fmt.Printf("Error starting zakat chaincode: %s", err.Error())
 	}
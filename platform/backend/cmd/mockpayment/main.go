@@ -0,0 +1,28 @@
+// Command mockpayment is a local stand-in for the Midtrans payment gateway. It accepts Snap
+// charges from the backend, settles each one after MOCK_PAYMENT_DELAY and posts a notification
+// signed with PAYMENT_SERVER_KEY to the backend's /api/payments/webhook.
+package main
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/izzuddinafif/fabric/platform/backend/internal/config"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/services"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+
+	cfg := config.Load()
+	gateway := services.NewMockGateway(cfg.Payment.ServerKey, cfg.MockPayment.PublicURL, cfg.MockPayment.NotificationURL, cfg.MockPayment.Delay)
+
+	log.Printf("💳 Mock payment gateway starting on port %s", cfg.MockPayment.Port)
+	log.Printf("📊 Payments settle after %s and are posted to %s", cfg.MockPayment.Delay, cfg.MockPayment.NotificationURL)
+	if err := http.ListenAndServe(":"+cfg.MockPayment.Port, gateway.Handler()); err != nil {
+		log.Fatalf("Failed to start mock payment gateway: %v", err)
+	}
+}
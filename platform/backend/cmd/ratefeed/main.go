@@ -0,0 +1,55 @@
+// Command ratefeed publishes exchange rates from a local JSON file to the ledger.
+// It is the development stand-in for a market data feed and runs under its own Fabric
+// identity, which must be enrolled with the zakat.ratePublisher=true attribute.
+package main
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/izzuddinafif/fabric/platform/backend/internal/config"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/services"
+	"github.com/izzuddinafif/fabric/platform/backend/pkg/fabric"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+
+	cfg := config.Load()
+	fabricConfig := fabric.FabricConfig{
+		ConfigPath: cfg.Fabric.ConfigPath,
+		WalletPath: cfg.Fabric.WalletPath,
+		Channel:    cfg.Fabric.Channel,
+		Chaincode:  cfg.Fabric.Chaincode,
+		User:       cfg.RateFeed.User,
+	}
+
+	log.Println("Connecting to Fabric network...")
+	fabric.NewClient(fabricConfig)
+	defer fabric.Close()
+
+	fabricService := services.NewFabricService(fabric.GetContract(fabricConfig))
+	rateFeed := services.NewRateFeedService(fabricService, cfg.RateFeed.File)
+
+	if cfg.RateFeed.Interval <= 0 {
+		if _, err := rateFeed.PublishRates(time.Now()); err != nil {
+			log.Printf("❌ Rate feed: %v", err)
+			fabric.Close()
+			os.Exit(1)
+		}
+		return
+	}
+
+	rateFeed.Start(cfg.RateFeed.Interval)
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	log.Println("Rate feed stopped")
+}
@@ -52,20 +52,119 @@ Chaincode:  cfg.Fabric.Chaincode,
 User:       cfg.Fabric.UserID,
 })
 
+	// Get the query system chaincode for ledger info
+	qsccContract := fabric.GetSystemContract(fabric.FabricConfig{
+		ConfigPath: cfg.Fabric.ConfigPath,
+		WalletPath: cfg.Fabric.WalletPath,
+		Channel:    cfg.Fabric.Channel,
+		Chaincode:  cfg.Fabric.Chaincode,
+		User:       cfg.Fabric.UserID,
+	}, "qscc")
+
 // Initialize services
 emailService := services.NewEmailService(cfg.Email)
-jwtService := services.NewJWTService(cfg.JWT.Secret, cfg.JWT.Expiry)
+	jwtService := services.NewJWTService(cfg.JWT.Secret, cfg.JWT.Expiry, cfg.JWT.DonorExpiry)
 fabricService := services.NewFabricService(fabricContract)
-validationService := services.NewValidationService(fabricContract, db, emailService, cfg.MockPayment.Delay)
-donationService := services.NewDonationService(fabricService, db, redis, validationService)
+	fabricService.SetZakatSequence(services.NewZakatSequence(redis, db))
+	validationService := services.NewValidationService(fabricContract, db, emailService)
+	jobQueue := services.NewJobQueue(redis, services.JobQueueOptions{
+		MaxAttempts: cfg.Jobs.MaxAttempts,
+		Backoff:     cfg.Jobs.Backoff,
+		MaxBackoff:  cfg.Jobs.MaxBackoff,
+		Lease:       cfg.Jobs.Lease,
+	})
+	validationService.SetJobQueue(jobQueue)
+	jobQueue.Start(cfg.Jobs.PollInterval)
+	paymentProvider := services.NewMidtransProvider(cfg.Payment.ServerKey, cfg.Payment.APIURL, cfg.Payment.SnapURL, cfg.Payment.Timeout)
+	paymentService := services.NewPaymentService(paymentProvider, validationService, db)
+	qrisService, err := services.NewQRISService(cfg.Payment.QRISMerchantPayload)
+	if err != nil {
+		log.Fatalf("Failed to initialize QRIS: %v", err)
+	}
+	paymentService.SetQRISService(qrisService)
+	outboxService := services.NewOutboxService(db, services.OutboxOptions{
+		MaxAttempts: cfg.Outbox.MaxAttempts,
+		Backoff:     cfg.Outbox.Backoff,
+		MaxBackoff:  cfg.Outbox.MaxBackoff,
+	})
+	donationService := services.NewDonationService(fabricService, db, redis, paymentService, outboxService)
 donationService.SetEmailService(emailService) // Set email service for donation notifications
 userService := services.NewUserService(db, redis)
-
+	ramadanStarts, err := services.ParseRamadanStarts(cfg.Pledge.RamadanStartDates)
+	if err != nil {
+		log.Fatalf("Failed to parse PLEDGE_RAMADAN_START_DATES: %v", err)
+	}
+	pledgeService := services.NewPledgeService(fabricService, donationService, db, emailService, userService, ramadanStarts)
+	donationService.SetPledgeService(pledgeService)
+	validationService.SetPledgeService(pledgeService)
+	pledgeService.Start(cfg.Pledge.CheckInterval)
+	receiptService, err := services.NewReceiptService(fabricService, cfg.Receipt.SigningKey, cfg.Receipt.PublicURL)
+	if err != nil {
+		log.Fatalf("Failed to initialize receipt service: %v", err)
+	}
+	idempotencyService := services.NewIdempotencyService(redis, cfg.Idempotency.TTL)
+	distributionService := services.NewDistributionService(fabricService, db)
+	programService := services.NewProgramService(fabricService, db, redis, cfg.Program.SummaryCacheTTL)
+	donationService.SetProgramService(programService)
+	validationService.SetProgramService(programService)
+	distributionService.SetProgramService(programService)
+	officerService := services.NewOfficerService(fabricService, userService)
+	networkService := services.NewNetworkService(qsccContract, fabricContract, cfg.Fabric.Channel, cfg.Fabric.OperationsEndpoints, cfg.Fabric.HealthTimeout)
+	dashboardService := services.NewDashboardService(donationService, networkService, redis, cfg.Dashboard.CacheTTL)
+	// Started once every service the donation outbox handlers use is set
+	outboxService.Start(cfg.Outbox.PollInterval)
+	blockIndexer := services.NewBlockIndexer(qsccContract, db, cfg.Fabric.Channel, cfg.Fabric.Chaincode)
+	if cfg.Indexer.PollInterval > 0 {
+		blockIndexer.Start(cfg.Indexer.PollInterval, cfg.Indexer.StartBlock)
+	}
+	reconciliationService := services.NewReconciliationService(fabricService, db, services.ReconciliationOptions{
+		PageSize: cfg.Reconciliation.PageSize,
+		Grace:    cfg.Reconciliation.Grace,
+	})
+	if cfg.Reconciliation.Interval > 0 {
+		reconciliationService.Start(cfg.Reconciliation.Interval)
+	}
+	donorService := services.NewDonorService(fabricService, db, userService)
+	otpService := services.NewOTPService(redis, userService, services.OTPOptions{
+		TTL:            cfg.OTP.TTL,
+		MaxAttempts:    cfg.OTP.MaxAttempts,
+		ResendCooldown: cfg.OTP.ResendCooldown,
+	})
+	if cfg.OTP.Sender == "live" {
+		otpService.SetSender(services.OTPChannelEmail, services.NewEmailOTPSender(emailService))
+		if cfg.OTP.GatewayURL != "" {
+			gateway := services.NewGatewayOTPSender(cfg.OTP.GatewayURL, cfg.OTP.GatewayToken, cfg.OTP.GatewayTimeout)
+			otpService.SetSender(services.OTPChannelSMS, gateway)
+			otpService.SetSender(services.OTPChannelWhatsApp, gateway)
+		}
+	} else {
+		if cfg.Server.Mode == "production" {
+			log.Println("⚠️  OTP_SENDER is not live: donor login codes are only logged")
+		}
+		for _, channel := range []string{services.OTPChannelSMS, services.OTPChannelWhatsApp, services.OTPChannelEmail} {
+			otpService.SetSender(channel, services.LogOTPSender{})
+		}
+	}
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(userService, jwtService, redis)
+	authHandler.SetOTPService(otpService)
+	donorHandler := handlers.NewDonorHandler(donorService, receiptService)
 	donationHandler := handlers.NewDonationHandler(donationService)
-	adminHandler := handlers.NewAdminHandler(donationService, userService, db)
+	donationHandler.SetIdempotencyService(idempotencyService)
+	pledgeHandler := handlers.NewPledgeHandler(pledgeService)
+	fitrahHandler := handlers.NewFitrahHandler(fabricService)
+	exchangeRateHandler := handlers.NewExchangeRateHandler(fabricService)
+	receiptHandler := handlers.NewReceiptHandler(receiptService)
+	paymentHandler := handlers.NewPaymentHandler(paymentService)
+	jobHandler := handlers.NewJobHandler(jobQueue)
+	outboxHandler := handlers.NewOutboxHandler(outboxService)
+	indexerHandler := handlers.NewIndexerHandler(blockIndexer)
+	reconciliationHandler := handlers.NewReconciliationHandler(reconciliationService)
+	distributionHandler := handlers.NewDistributionHandler(distributionService)
+	programHandler := handlers.NewProgramHandler(programService)
+	officerHandler := handlers.NewOfficerHandler(officerService)
+	adminHandler := handlers.NewAdminHandler(donationService, userService, dashboardService)
 
 	// Set up Gin router
 	if cfg.Server.Mode == "production" {
@@ -109,16 +208,54 @@ userService := services.NewUserService(db, redis)
 	api := r.Group("/api")
 	{
 		// Public donation endpoints
-		api.POST("/donations", donationHandler.CreateDonation)
+		api.POST("/donations", middleware.OptionalAuth(jwtService), donationHandler.CreateDonation)
 		api.GET("/donations/:id", donationHandler.GetDonation)
+		api.GET("/donations/:id/distributions", distributionHandler.GetDonationDistributions)
+		api.GET("/donations/:id/payment", paymentHandler.GetPaymentStatus)
+		api.GET("/organizations", donationHandler.GetOrganizations)
+
+		// Payment gateway notifications, verified by signature
+		api.POST("/payments/webhook", paymentHandler.Webhook)
+
+		// Programs
+		api.GET("/programs/:id/summary", programHandler.GetProgramSummary)
+		api.GET("/programs/:id/distributions", distributionHandler.GetProgramDistributions)
+
+		// Public receipt verification
+		api.GET("/receipts/verify", receiptHandler.VerifyReceipt)
+		api.GET("/receipts/public-key", receiptHandler.GetPublicKey)
+
+		// Recurring pledge endpoints; only the pledge's donor or an admin can cancel it
+		api.POST("/pledges", middleware.OptionalAuth(jwtService), pledgeHandler.CreatePledge)
+		api.GET("/pledges/:id", pledgeHandler.GetPledge)
+		api.POST("/pledges/:id/cancel", middleware.AuthRequired(jwtService), pledgeHandler.CancelPledge)
+
+		// Public zakat fitrah rate lookup
+		api.GET("/fitrah-rates/:region", fitrahHandler.GetFitrahRate)
+
+		// Public exchange rate lookup for donations in other currencies or gold/silver
+		api.GET("/exchange-rates/:denomination", exchangeRateHandler.GetExchangeRate)
 
 		// Authentication endpoints
 		auth := api.Group("/auth")
 		{
 			auth.POST("/admin/login", authHandler.AdminLogin)
+			auth.POST("/otp/send", authHandler.SendOTP)
+			auth.POST("/otp/verify", authHandler.VerifyOTP)
 			auth.POST("/logout", middleware.AuthRequired(jwtService), authHandler.Logout)
 		}
 
+		// Signed-in donor endpoints
+		me := api.Group("/me")
+		me.Use(middleware.AuthRequired(jwtService))
+		me.Use(middleware.DonorRequired())
+		{
+			me.GET("/donations", donorHandler.GetDonations)
+			me.GET("/donations/:id", donorHandler.GetDonation)
+			me.GET("/donations/:id/receipt", donorHandler.GetReceipt)
+			me.POST("/donations/claim", donorHandler.ClaimDonations)
+		}
+
 		// Admin endpoints (protected)
 		admin := api.Group("/admin")
 		admin.Use(middleware.AuthRequired(jwtService))
@@ -128,6 +265,27 @@ userService := services.NewUserService(db, redis)
 			admin.GET("/donations", adminHandler.GetDonations)
 			admin.POST("/donations/:id/validate", adminHandler.ValidateDonation)
 			admin.POST("/donations/:id/distribute", adminHandler.DistributeDonation)
+			admin.GET("/donations/:id/receipt", receiptHandler.GetReceipt)
+			admin.GET("/pledges", pledgeHandler.GetPledges)
+			admin.GET("/officers/performance", officerHandler.GetPerformance)
+		}
+
+		// Organization admin endpoints: moving funds, rates and ledger sync operations
+		orgAdmin := admin.Group("")
+		orgAdmin.Use(middleware.OrgAdminRequired())
+		{
+			orgAdmin.POST("/donations/:id/reallocate", adminHandler.ReallocateDonation)
+			orgAdmin.POST("/programs/:id/distributions", distributionHandler.DistributeFromProgram)
+			orgAdmin.PUT("/fitrah-rates", fitrahHandler.SetFitrahRate)
+			orgAdmin.GET("/jobs", jobHandler.GetJobs)
+			orgAdmin.POST("/jobs/:id/retry", jobHandler.RetryJob)
+			orgAdmin.GET("/outbox", outboxHandler.GetOutbox)
+			orgAdmin.POST("/outbox/:id/retry", outboxHandler.RetryOutboxEntry)
+			orgAdmin.GET("/indexer", indexerHandler.GetStatus)
+			orgAdmin.PUT("/indexer/checkpoint", indexerHandler.SetCheckpoint)
+			orgAdmin.GET("/reconciliation", reconciliationHandler.GetReconciliation)
+			orgAdmin.GET("/reconciliation/:id", reconciliationHandler.GetRun)
+			orgAdmin.POST("/reconciliation/run", reconciliationHandler.Run)
 		}
 	}
 
@@ -138,7 +296,8 @@ userService := services.NewUserService(db, redis)
 	}
 
 	log.Printf("🚀 Zakat Platform Backend starting on port %s", port)
-	log.Printf("📊 Mock payment validation delay: %s", cfg.MockPayment.Delay)
+	log.Printf("💳 Payment gateway: %s", cfg.Payment.APIURL)
+	log.Printf("🗓️ Pledge check interval: %s", cfg.Pledge.CheckInterval)
 	log.Printf("🔗 Fabric channel: %s", cfg.Fabric.Channel)
 	log.Printf("📦 Fabric chaincode: %s", cfg.Fabric.Chaincode)
 
@@ -3,18 +3,31 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // Config holds all configuration for the application
 type Config struct {
-	Server      ServerConfig
-	Database    DatabaseConfig
-	Redis       RedisConfig
-	Fabric      FabricConfig
-	Email       EmailConfig
-	JWT         JWTConfig
-	MockPayment MockPaymentConfig
+	Server         ServerConfig
+	Database       DatabaseConfig
+	Redis          RedisConfig
+	Fabric         FabricConfig
+	Email          EmailConfig
+	JWT            JWTConfig
+	Payment        PaymentConfig
+	MockPayment    MockPaymentConfig
+	Pledge         PledgeConfig
+	RateFeed       RateFeedConfig
+	Receipt        ReceiptConfig
+	Idempotency    IdempotencyConfig
+	Program        ProgramConfig
+	Dashboard      DashboardConfig
+	Jobs           JobsConfig
+	Outbox         OutboxConfig
+	Indexer        IndexerConfig
+	Reconciliation ReconciliationConfig
+	OTP            OTPConfig
 }
 
 // ServerConfig holds server configuration
@@ -49,6 +62,9 @@ type FabricConfig struct {
 	Chaincode  string
 	UserID     string
 	OrgName    string
+
+	OperationsEndpoints []string      // Peer and orderer operations base URLs, checked via /healthz
+	HealthTimeout       time.Duration // Per-endpoint health check timeout
 }
 
 // EmailConfig holds SMTP configuration
@@ -63,13 +79,102 @@ type EmailConfig struct {
 
 // JWTConfig holds JWT configuration
 type JWTConfig struct {
-	Secret string
-	Expiry time.Duration
+	Secret      string
+	Expiry      time.Duration
+	DonorExpiry time.Duration // Expiry of donor tokens issued on OTP sign-in
+}
+
+// OTPConfig holds donor one-time login code configuration
+type OTPConfig struct {
+	TTL            time.Duration // How long a code is valid
+	MaxAttempts    int           // Wrong entries before a code is discarded
+	ResendCooldown time.Duration // Minimum time between codes sent to one phone or email
+	Sender         string        // log (codes are only logged; development) or live
+	GatewayURL     string        // SMS/WhatsApp messaging gateway; phone codes cannot be sent live without it
+	GatewayToken   string
+	GatewayTimeout time.Duration
+}
+
+// PaymentConfig holds payment gateway configuration (Midtrans API)
+type PaymentConfig struct {
+	ServerKey string        // Merchant server key; authenticates API calls and signs notifications
+	APIURL    string        // Core API base URL, e.g. https://api.sandbox.midtrans.com
+	SnapURL   string        // Snap base URL, e.g. https://app.sandbox.midtrans.com
+	Timeout   time.Duration // Gateway request timeout
+
+	QRISMerchantPayload string // Static QRIS issued to the merchant; dynamic per-donation codes are derived from it
 }
 
-// MockPaymentConfig holds mock payment system configuration
+// MockPaymentConfig holds the local payment gateway stand-in configuration (cmd/mockpayment)
 type MockPaymentConfig struct {
-	Delay time.Duration
+	Port            string
+	PublicURL       string        // Base URL donors are redirected to
+	NotificationURL string        // Backend webhook settled payments are posted to
+	Delay           time.Duration // How long after a charge the payment settles
+}
+
+// PledgeConfig holds recurring pledge scheduler configuration
+type PledgeConfig struct {
+	CheckInterval     time.Duration // How often due pledges are turned into pending donations
+	RamadanStartDates []string      // 1 Ramadan per Hijri year as HIJRI_YEAR:YYYY-MM-DD, consecutive years
+}
+
+// RateFeedConfig holds the development exchange rate feed configuration (cmd/ratefeed)
+type RateFeedConfig struct {
+	File     string        // JSON file with the rates to publish
+	User     string        // Wallet identity with the zakat.ratePublisher=true attribute
+	Interval time.Duration // How often to republish; 0 publishes once and exits
+}
+
+// ReceiptConfig holds donation receipt signing configuration
+type ReceiptConfig struct {
+	SigningKey string // Base64 ed25519 seed (32 bytes); a temporary key is generated when empty
+	PublicURL  string // Public base URL of this API, used in receipt verification links
+}
+
+// IdempotencyConfig holds Idempotency-Key handling configuration
+type IdempotencyConfig struct {
+	TTL time.Duration // How long a key and its stored response are kept
+}
+
+// ProgramConfig holds program analytics configuration
+type ProgramConfig struct {
+	SummaryCacheTTL time.Duration // Upper bound on how long a cached program summary is served
+}
+
+// DashboardConfig holds admin dashboard configuration
+type DashboardConfig struct {
+	CacheTTL time.Duration // How long a computed dashboard is served before it is recomputed
+}
+
+// JobsConfig holds background job queue configuration
+type JobsConfig struct {
+	PollInterval time.Duration // How often the worker looks for due jobs
+	MaxAttempts  int           // Attempts before a job is moved to the dead list
+	Backoff      time.Duration // Delay before the first retry; doubles with every attempt
+	MaxBackoff   time.Duration
+	Lease        time.Duration // How long a running job may take before it is run again
+}
+
+// IndexerConfig holds ledger block indexer configuration
+type IndexerConfig struct {
+	PollInterval time.Duration // How often new blocks are indexed; 0 disables the indexer
+	StartBlock   int           // Block to (re)index from at startup; -1 resumes from the checkpoint
+}
+
+// ReconciliationConfig holds ledger reconciliation configuration
+type ReconciliationConfig struct {
+	Interval time.Duration // How often the ledger is reconciled with Postgres; 0 disables scheduled runs
+	PageSize int           // Records read per page from each side
+	Grace    time.Duration // Records created this recently are not reconciled yet
+}
+
+// OutboxConfig holds ledger outbox configuration
+type OutboxConfig struct {
+	PollInterval time.Duration // How often the worker retries pending ledger submissions
+	MaxAttempts  int           // Attempts before a submission is failed and its donation marked "error"
+	Backoff      time.Duration // Delay before the first retry; doubles with every attempt
+	MaxBackoff   time.Duration
 }
 
 // Load loads configuration from environment variables
@@ -100,6 +205,9 @@ func Load() *Config {
 			Chaincode:  getEnv("FABRIC_CHAINCODE", "zakat"),
 			UserID:     getEnv("FABRIC_USER", "appUserOrg1"),
 			OrgName:    getEnv("FABRIC_ORG_NAME", "Org1"),
+
+			OperationsEndpoints: getEnvAsList("FABRIC_OPERATIONS_ENDPOINTS", "http://localhost:8443,http://localhost:9443,http://localhost:9444"),
+			HealthTimeout:       getEnvAsDuration("FABRIC_HEALTH_TIMEOUT", "3s"),
 		},
 		Email: EmailConfig{
 			SMTPHost:  getEnv("EMAIL_SMTP_HOST", "smtp.gmail.com"),
@@ -110,11 +218,77 @@ func Load() *Config {
 			FromEmail: getEnv("EMAIL_FROM_EMAIL", "noreply@zakatplatform.org"),
 		},
 		JWT: JWTConfig{
-			Secret: getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
-			Expiry: getEnvAsDuration("JWT_EXPIRY", "720h"), // 30 days
+			Secret:      getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
+			Expiry:      getEnvAsDuration("JWT_EXPIRY", "720h"),       // 30 days
+			DonorExpiry: getEnvAsDuration("JWT_DONOR_EXPIRY", "168h"), // 7 days
+		},
+		Payment: PaymentConfig{
+			ServerKey: getEnv("PAYMENT_SERVER_KEY", "mock-server-key"),
+			APIURL:    getEnv("PAYMENT_API_URL", "http://localhost:3010"),
+			SnapURL:   getEnv("PAYMENT_SNAP_URL", "http://localhost:3010"),
+			Timeout:   getEnvAsDuration("PAYMENT_TIMEOUT", "15s"),
+
+			QRISMerchantPayload: getEnv("QRIS_MERCHANT_PAYLOAD", "00020101021126620014ID.CO.YDSF.WWW01189360000000000000010211YDSFMLG00010303UMI51440014ID.CO.QRIS.WWW0215ID10200000000010303UMI5204866153033605802ID5911YDSF MALANG6006MALANG61056511163046320"),
 		},
 		MockPayment: MockPaymentConfig{
-			Delay: getEnvAsDuration("MOCK_PAYMENT_DELAY", "30s"),
+			Port:            getEnv("MOCK_PAYMENT_PORT", "3010"),
+			PublicURL:       getEnv("MOCK_PAYMENT_PUBLIC_URL", "http://localhost:3010"),
+			NotificationURL: getEnv("MOCK_PAYMENT_NOTIFICATION_URL", "http://localhost:3002/api/payments/webhook"),
+			Delay:           getEnvAsDuration("MOCK_PAYMENT_DELAY", "30s"),
+		},
+		Pledge: PledgeConfig{
+			CheckInterval: getEnvAsDuration("PLEDGE_CHECK_INTERVAL", "1h"),
+			// Years without a sidang isbat yet are hisab projections; correct them once announced
+			RamadanStartDates: getEnvAsList("PLEDGE_RAMADAN_START_DATES", "1445:2024-03-12,1446:2025-03-01,1447:2026-02-19,1448:2027-02-08,1449:2028-01-28,1450:2029-01-16,1451:2030-01-06"),
+		},
+		Receipt: ReceiptConfig{
+			SigningKey: getEnv("RECEIPT_SIGNING_KEY", ""),
+			PublicURL:  getEnv("PUBLIC_API_URL", "http://localhost:3002"),
+		},
+		Idempotency: IdempotencyConfig{
+			TTL: getEnvAsDuration("IDEMPOTENCY_TTL", "24h"),
+		},
+		Program: ProgramConfig{
+			SummaryCacheTTL: getEnvAsDuration("PROGRAM_SUMMARY_CACHE_TTL", "10m"),
+		},
+		Dashboard: DashboardConfig{
+			CacheTTL: getEnvAsDuration("DASHBOARD_CACHE_TTL", "30s"),
+		},
+		Jobs: JobsConfig{
+			PollInterval: getEnvAsDuration("JOB_POLL_INTERVAL", "1s"),
+			MaxAttempts:  getEnvAsInt("JOB_MAX_ATTEMPTS", 8),
+			Backoff:      getEnvAsDuration("JOB_RETRY_BACKOFF", "5s"),
+			MaxBackoff:   getEnvAsDuration("JOB_MAX_BACKOFF", "10m"),
+			Lease:        getEnvAsDuration("JOB_LEASE", "2m"),
+		},
+		Outbox: OutboxConfig{
+			PollInterval: getEnvAsDuration("OUTBOX_POLL_INTERVAL", "5s"),
+			MaxAttempts:  getEnvAsInt("OUTBOX_MAX_ATTEMPTS", 10),
+			Backoff:      getEnvAsDuration("OUTBOX_RETRY_BACKOFF", "5s"),
+			MaxBackoff:   getEnvAsDuration("OUTBOX_MAX_BACKOFF", "10m"),
+		},
+		Indexer: IndexerConfig{
+			PollInterval: getEnvAsDuration("INDEXER_POLL_INTERVAL", "5s"),
+			StartBlock:   getEnvAsInt("INDEXER_START_BLOCK", -1),
+		},
+		Reconciliation: ReconciliationConfig{
+			Interval: getEnvAsDuration("RECONCILIATION_INTERVAL", "6h"),
+			PageSize: getEnvAsInt("RECONCILIATION_PAGE_SIZE", 200),
+			Grace:    getEnvAsDuration("RECONCILIATION_GRACE", "15m"),
+		},
+		OTP: OTPConfig{
+			TTL:            getEnvAsDuration("OTP_TTL", "5m"),
+			MaxAttempts:    getEnvAsInt("OTP_MAX_ATTEMPTS", 5),
+			ResendCooldown: getEnvAsDuration("OTP_RESEND_COOLDOWN", "60s"),
+			Sender:         getEnv("OTP_SENDER", "log"),
+			GatewayURL:     getEnv("OTP_GATEWAY_URL", ""),
+			GatewayToken:   getEnv("OTP_GATEWAY_TOKEN", ""),
+			GatewayTimeout: getEnvAsDuration("OTP_GATEWAY_TIMEOUT", "10s"),
+		},
+		RateFeed: RateFeedConfig{
+			File:     getEnv("RATE_FEED_FILE", "./rates.dev.json"),
+			User:     getEnv("RATE_FEED_USER", "ratePublisherOrg1"),
+			Interval: getEnvAsDuration("RATE_FEED_INTERVAL", "0s"),
 		},
 	}
 }
@@ -136,6 +310,17 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvAsList splits a comma-separated variable, dropping empty entries
+func getEnvAsList(key string, defaultValue string) []string {
+	var list []string
+	for _, item := range strings.Split(getEnv(key, defaultValue), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 func getEnvAsDuration(key string, defaultValue string) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
@@ -11,55 +11,32 @@ import (
 
 // AdminHandler handles admin endpoints
 type AdminHandler struct {
-	donationService *services.DonationService
-	userService     *services.UserService
+	donationService  *services.DonationService
+	userService      *services.UserService
+	dashboardService *services.DashboardService
 }
 
 // NewAdminHandler creates a new admin handler
-func NewAdminHandler(donationService *services.DonationService, userService *services.UserService) *AdminHandler {
+func NewAdminHandler(donationService *services.DonationService, userService *services.UserService, dashboardService *services.DashboardService) *AdminHandler {
 	return &AdminHandler{
-		donationService: donationService,
-		userService:     userService,
+		donationService:  donationService,
+		userService:      userService,
+		dashboardService: dashboardService,
 	}
 }
 
 // GetDashboard handles GET /api/admin/dashboard
 func (h *AdminHandler) GetDashboard(c *gin.Context) {
-	// This is a simplified dashboard - in a real implementation,
-	// you would query the database for actual metrics
-
-	metrics := models.DashboardMetrics{
-		PendingDonations:      5,
-		TodaysCollection:      1250000.0,
-		TotalCollected:        25000000.0,
-		TotalDistributed:      20000000.0,
-		NetworkHealth:         "healthy",
-		BlockchainHeight:      12345,
-		ChaincodeInstantiated: true,
-	}
-
-	recentActivity := []models.RecentActivity{
-		{
-			Type:      "donation",
-			Message:   "New donation received: Rp 500,000",
-			Timestamp: nil, // You would set actual timestamp
-		},
-		{
-			Type:      "validation",
-			Message:   "Payment validated for donation ZKT-YDSF-MLG-202406-0001",
-			Timestamp: nil,
-		},
-	}
-
-	response := models.DashboardResponse{
-		Metrics:        metrics,
-		RecentActivity: recentActivity,
+	dashboard, err := h.dashboardService.GetDashboard()
+	if err != nil {
+		respondError(c, err, "Failed to get dashboard")
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, dashboard)
 }
 
-// GetDonations handles GET /api/admin/donations
+// GetDonations handles GET /api/admin/donations?organization=&payment_method=
 func (h *AdminHandler) GetDonations(c *gin.Context) {
 	// Parse pagination parameters
 	limitStr := c.DefaultQuery("limit", "20")
@@ -75,7 +52,12 @@ func (h *AdminHandler) GetDonations(c *gin.Context) {
 		offset = 0
 	}
 
-	donations, err := h.donationService.GetDonations(limit, offset)
+	filter := services.DonationFilter{
+		Organization:  c.Query("organization"),
+		PaymentMethod: c.Query("payment_method"),
+	}
+
+	donations, err := h.donationService.GetDonations(filter, limit, offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get donations"})
 		return
@@ -83,6 +65,10 @@ func (h *AdminHandler) GetDonations(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"donations": donations,
+		"filters": gin.H{
+			"organization":   filter.Organization,
+			"payment_method": filter.PaymentMethod,
+		},
 		"pagination": gin.H{
 			"limit":  limit,
 			"offset": offset,
@@ -98,18 +84,36 @@ func (h *AdminHandler) ValidateDonation(c *gin.Context) {
 		return
 	}
 
+	var req models.ValidateDonationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "User ID not found"})
 		return
 	}
 
-	// For manual validation, we would need additional validation logic
-	// This is simplified for the MVP with mock payments
+	donation, err := h.donationService.ValidateDonation(donationID, req.ReceiptNumber, userID.(string))
+	if err != nil {
+		respondError(c, err, "Failed to validate donation")
+		return
+	}
+
+	// The ledger could not be reached; the outbox validates the donation later
+	if donation.SyncStatus == "pending_sync" {
+		c.JSON(http.StatusAccepted, gin.H{
+			"message":  "Validation recorded and waiting for the blockchain",
+			"donation": donation,
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message":      "Manual validation not available in MVP - using auto-validation",
-		"donation_id":  donationID,
-		"validated_by": userID,
+		"message":  "Donation validated successfully",
+		"donation": donation,
 	})
 }
 
@@ -121,17 +125,76 @@ func (h *AdminHandler) DistributeDonation(c *gin.Context) {
 		return
 	}
 
+	var req models.DistributeDonationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "User ID not found"})
 		return
 	}
 
-	// This would implement the distribution logic
-	// For MVP, this is simplified
+	donation, err := h.donationService.DistributeDonation(donationID, req, userID.(string))
+	if err != nil {
+		respondError(c, err, "Failed to distribute donation")
+		return
+	}
+
+	// The ledger could not be reached; the outbox distributes the donation later
+	if donation.SyncStatus == "pending_sync" {
+		c.JSON(http.StatusAccepted, gin.H{
+			"message":  "Distribution recorded and waiting for the blockchain",
+			"donation": donation,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Donation distributed successfully",
+		"donation": donation,
+	})
+}
+
+// ReallocateDonation handles POST /api/admin/donations/:id/reallocate (organization admins only)
+func (h *AdminHandler) ReallocateDonation(c *gin.Context) {
+	donationID := c.Param("id")
+	if donationID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Donation ID is required"})
+		return
+	}
+
+	var req models.ReallocateDonationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	donation, err := h.donationService.ReallocateDonation(donationID, req.ProgramID, req.Reason, userID.(string))
+	if err != nil {
+		respondError(c, err, "Failed to reallocate donation")
+		return
+	}
+
+	// The ledger could not be reached; the outbox reallocates the donation later
+	if donation.SyncStatus == "pending_sync" {
+		c.JSON(http.StatusAccepted, gin.H{
+			"message":  "Reallocation recorded and waiting for the blockchain",
+			"donation": donation,
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message":        "Distribution feature coming in Phase 2",
-		"donation_id":    donationID,
-		"distributed_by": userID,
+		"message":  "Donation reallocated successfully",
+		"donation": donation,
 	})
 }
@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -13,6 +16,7 @@ import (
 type AuthHandler struct {
 	userService *services.UserService
 	jwtService  *services.JWTService
+	otpService  *services.OTPService
 	redis       *redis.Client
 }
 
@@ -25,6 +29,11 @@ func NewAuthHandler(userService *services.UserService, jwtService *services.JWTS
 	}
 }
 
+// SetOTPService sets the service signing donors in with one-time codes
+func (h *AuthHandler) SetOTPService(otpService *services.OTPService) {
+	h.otpService = otpService
+}
+
 // AdminLogin handles admin login
 func (h *AuthHandler) AdminLogin(c *gin.Context) {
 	var req models.AdminLoginRequest
@@ -55,6 +64,70 @@ func (h *AuthHandler) AdminLogin(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// SendOTP handles POST /api/auth/otp/send, sending a donor a one-time login code
+func (h *AuthHandler) SendOTP(c *gin.Context) {
+	var req models.SendOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	delivery, err := h.otpService.SendCode(req.Phone, req.Email, req.Channel)
+	var cooldown *services.OTPCooldownError
+	if errors.As(err, &cooldown) {
+		retryAfter := int(math.Ceil(cooldown.RetryAfter.Seconds()))
+		c.Header("Retry-After", fmt.Sprint(retryAfter))
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":       cooldown.Error(),
+			"retry_after": retryAfter,
+		})
+		return
+	}
+	if err != nil {
+		respondError(c, err, "Failed to send code")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Code sent",
+		"delivery": delivery,
+	})
+}
+
+// VerifyOTP handles POST /api/auth/otp/verify, signing a donor in with a one-time code.
+// Donors signing in for the first time are registered.
+func (h *AuthHandler) VerifyOTP(c *gin.Context) {
+	var req models.VerifyOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.otpService.VerifyCode(req.Phone, req.Email, req.OTP)
+	switch {
+	case errors.Is(err, services.ErrOTPInvalid):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrOTPAttemptsExceeded):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		respondError(c, err, "Failed to verify code")
+		return
+	}
+
+	token, err := h.jwtService.GenerateDonorToken(user.ID.String())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.DonorLoginResponse{
+		Token: token,
+		User:  *user,
+	})
+}
+
 // Logout handles user logout
 func (h *AuthHandler) Logout(c *gin.Context) {
 	// In a stateless JWT system, logout is typically handled client-side
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/models"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/services"
+)
+
+// DistributionHandler handles program distribution endpoints
+type DistributionHandler struct {
+	distributionService *services.DistributionService
+}
+
+// NewDistributionHandler creates a new distribution handler
+func NewDistributionHandler(distributionService *services.DistributionService) *DistributionHandler {
+	return &DistributionHandler{
+		distributionService: distributionService,
+	}
+}
+
+// DistributeFromProgram handles POST /api/admin/programs/:id/distributions
+func (h *DistributionHandler) DistributeFromProgram(c *gin.Context) {
+	programID := c.Param("id")
+	if programID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Program ID is required"})
+		return
+	}
+
+	var req models.DistributeFromProgramRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	distribution, err := h.distributionService.DistributeFromProgram(programID, req, userID.(string))
+	if err != nil {
+		respondError(c, err, "Failed to distribute from program")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":      "Program distribution recorded successfully",
+		"distribution": distribution,
+	})
+}
+
+// GetProgramDistributions handles GET /api/programs/:id/distributions
+func (h *DistributionHandler) GetProgramDistributions(c *gin.Context) {
+	programID := c.Param("id")
+	if programID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Program ID is required"})
+		return
+	}
+
+	distributions, err := h.distributionService.GetProgramDistributions(programID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get program distributions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"distributions": distributions})
+}
+
+// GetDonationDistributions handles GET /api/donations/:id/distributions
+func (h *DistributionHandler) GetDonationDistributions(c *gin.Context) {
+	donationID := c.Param("id")
+	if donationID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Donation ID is required"})
+		return
+	}
+
+	distributions, err := h.distributionService.GetDonationDistributions(donationID)
+	if err != nil {
+		respondError(c, err, "Failed to get donation distributions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"distributions": distributions})
+}
@@ -1,16 +1,24 @@
 package handlers
 
 import (
+	"encoding/base64"
+	"errors"
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/izzuddinafif/fabric/platform/backend/internal/models"
 	"github.com/izzuddinafif/fabric/platform/backend/internal/services"
 )
 
+// idempotencyScope namespaces donation Idempotency-Key entries in Redis
+const idempotencyScope = "donations"
+
 // DonationHandler handles donation endpoints
 type DonationHandler struct {
-	donationService *services.DonationService
+	donationService    *services.DonationService
+	idempotencyService *services.IdempotencyService
 }
 
 // NewDonationHandler creates a new donation handler
@@ -20,24 +28,121 @@ func NewDonationHandler(donationService *services.DonationService) *DonationHand
 	}
 }
 
-// CreateDonation handles POST /api/donations
+// SetIdempotencyService sets the service storing responses under Idempotency-Key headers
+func (h *DonationHandler) SetIdempotencyService(idempotencyService *services.IdempotencyService) {
+	h.idempotencyService = idempotencyService
+}
+
+// CreateDonation handles POST /api/donations, from guests or signed-in donors.
+// With an Idempotency-Key header, a retry of the same request returns the original response
+// (marked Idempotent-Replayed: true) and a different request under the key is rejected with 422.
 func (h *DonationHandler) CreateDonation(c *gin.Context) {
 	var req models.CreateDonationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	// Donations made while signed in as a donor belong to their account
+	if c.GetString("user_role") == "donor" {
+		if donorID, err := uuid.Parse(c.GetString("user_id")); err == nil {
+			req.DonorID = donorID
+		}
+	}
+
+	key := c.GetHeader("Idempotency-Key")
+	requestHash := ""
+	if key != "" {
+		if !services.ValidIdempotencyKey(key) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must be up to 100 letters, digits, '.', '_', ':' or '-'"})
+			return
+		}
+
+		var err error
+		if requestHash, err = services.RequestHash(req); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create donation"})
+			return
+		}
+
+		stored, err := h.idempotencyService.Begin(idempotencyScope, key, requestHash)
+		switch {
+		case errors.Is(err, services.ErrIdempotencyKeyReused):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, services.ErrIdempotentRequestInProgress):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			// The ledger request key still prevents a duplicate donation
+			log.Printf("❌ Idempotency store unavailable for key %s: %v", key, err)
+			requestHash = ""
+		case stored != nil:
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(stored.StatusCode, "application/json; charset=utf-8", stored.Body)
+			return
+		}
+	}
 
-	donation, err := h.donationService.CreateDonation(req)
+	donation, err := h.donationService.CreateDonation(req, key)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create donation"})
+		if requestHash != "" {
+			if err := h.idempotencyService.Release(idempotencyScope, key); err != nil {
+				log.Printf("❌ Failed to release idempotency key %s: %v", key, err)
+			}
+		}
+		switch {
+		case errors.Is(err, services.ErrInvalidFitrahDonation) || errors.Is(err, services.ErrInvalidDenomination):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrIdempotencyKeyReused):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": services.ErrIdempotencyKeyReused.Error()})
+		default:
+			respondError(c, err, "Failed to create donation")
+		}
+		return
+	}
+
+	// The ledger could not be reached; the outbox submits the donation and opens its payment later.
+	// The key is released so a retry returns the donation as it is by then.
+	if donation.SyncStatus == "pending_sync" {
+		if requestHash != "" {
+			if err := h.idempotencyService.Release(idempotencyScope, key); err != nil {
+				log.Printf("❌ Failed to release idempotency key %s: %v", key, err)
+			}
+		}
+		c.JSON(http.StatusAccepted, gin.H{
+			"message":  "Donation recorded and waiting for the blockchain; payment opens once it is confirmed",
+			"donation": donation.PublicView(),
+		})
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
+	response := gin.H{
 		"message":  "Donation created successfully",
-		"donation": donation,
-	})
+		"donation": donation.PublicView(),
+	}
+	if donation.QRISPayload.Valid {
+		png, err := services.QRISImage(donation.QRISPayload.String)
+		if err != nil {
+			log.Printf("❌ %v", err)
+		} else {
+			response["qris"] = gin.H{
+				"payload": donation.QRISPayload.String,
+				"png":     base64.StdEncoding.EncodeToString(png),
+			}
+		}
+	}
+	if requestHash != "" {
+		if err := h.idempotencyService.Complete(idempotencyScope, key, requestHash, http.StatusCreated, response); err != nil {
+			log.Printf("❌ Failed to store response for idempotency key %s: %v", key, err)
+		}
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetOrganizations handles GET /api/organizations, listing where donations can be made to and
+// the payment methods each accepts
+func (h *DonationHandler) GetOrganizations(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"organizations": services.Organizations()})
 }
 
 // GetDonation handles GET /api/donations/:id
@@ -50,13 +155,9 @@ func (h *DonationHandler) GetDonation(c *gin.Context) {
 
 	donation, err := h.donationService.GetDonation(id)
 	if err != nil {
-		if err.Error() == "donation not found" {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Donation not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get donation"})
+		respondError(c, err, "Failed to get donation")
 		return
 	}
 
-	c.JSON(http.StatusOK, donation)
+	c.JSON(http.StatusOK, donation.PublicView())
 }
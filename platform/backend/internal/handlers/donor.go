@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/models"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/services"
+)
+
+// DonorHandler handles the signed-in donor's endpoints under /api/me
+type DonorHandler struct {
+	donorService   *services.DonorService
+	receiptService *services.ReceiptService
+}
+
+// NewDonorHandler creates a new donor handler
+func NewDonorHandler(donorService *services.DonorService, receiptService *services.ReceiptService) *DonorHandler {
+	return &DonorHandler{
+		donorService:   donorService,
+		receiptService: receiptService,
+	}
+}
+
+// GetDonations handles GET /api/me/donations?limit=&offset=
+func (h *DonorHandler) GetDonations(c *gin.Context) {
+	donorID, ok := donorIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	response, err := h.donorService.ListDonations(donorID, limit, offset)
+	if err != nil {
+		respondError(c, err, "Failed to get donations")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetDonation handles GET /api/me/donations/:id
+func (h *DonorHandler) GetDonation(c *gin.Context) {
+	donorID, ok := donorIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.donorService.GetDonation(donorID, c.Param("id"))
+	if err != nil {
+		respondError(c, err, "Failed to get donation")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetReceipt handles GET /api/me/donations/:id/receipt
+func (h *DonorHandler) GetReceipt(c *gin.Context) {
+	donorID, ok := donorIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	donation, err := h.donorService.OwnedDonation(donorID, c.Param("id"))
+	if err != nil {
+		respondError(c, err, "Failed to get receipt")
+		return
+	}
+
+	receipt, err := h.receiptService.IssueReceipt(donation.ID)
+	if err != nil {
+		if errors.Is(err, services.ErrReceiptNotAvailable) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		respondError(c, err, "Failed to issue receipt")
+		return
+	}
+
+	c.JSON(http.StatusOK, receipt)
+}
+
+// ClaimDonations handles POST /api/me/donations/claim
+func (h *DonorHandler) ClaimDonations(c *gin.Context) {
+	donorID, ok := donorIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	var req models.ClaimDonationsRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	claimed, err := h.donorService.ClaimDonations(donorID, req.DonationIDs)
+	if err != nil {
+		respondError(c, err, "Failed to claim donations")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"claimed": claimed})
+}
+
+// donorIDFromContext returns the signed-in donor's ID set by the auth middleware, responding
+// with an error when there is none
+func donorIDFromContext(c *gin.Context) (uuid.UUID, bool) {
+	donorID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "User ID not found"})
+		return uuid.Nil, false
+	}
+	return donorID, true
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/services"
+)
+
+// contractErrorStatus maps ContractError codes to HTTP status codes
+var contractErrorStatus = map[string]int{
+	services.CodeNotFound:     http.StatusNotFound,
+	services.CodeInvalidInput: http.StatusBadRequest,
+	services.CodeInvalidState: http.StatusConflict,
+	services.CodeConflict:     http.StatusConflict,
+	services.CodeUnauthorized: http.StatusForbidden,
+}
+
+// respondError writes err as {"error", "code", "field"} with the status its ContractError code maps to.
+// Any other error is logged and reported as a 500 with the given message.
+func respondError(c *gin.Context, err error, message string) {
+	var contractErr *services.ContractError
+	if !errors.As(err, &contractErr) {
+		log.Printf("❌ %s: %v", message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message, "code": "INTERNAL"})
+		return
+	}
+
+	status, ok := contractErrorStatus[contractErr.Code]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	body := gin.H{"error": contractErr.Message, "code": contractErr.Code}
+	if contractErr.Field != "" {
+		body["field"] = contractErr.Field
+	}
+	c.JSON(status, body)
+}
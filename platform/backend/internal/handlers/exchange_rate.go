@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/services"
+)
+
+// ExchangeRateHandler handles exchange rate endpoints
+type ExchangeRateHandler struct {
+	fabricService *services.FabricService
+}
+
+// NewExchangeRateHandler creates a new exchange rate handler
+func NewExchangeRateHandler(fabricService *services.FabricService) *ExchangeRateHandler {
+	return &ExchangeRateHandler{
+		fabricService: fabricService,
+	}
+}
+
+// GetExchangeRate handles GET /api/exchange-rates/:denomination
+// It returns the IDR rate currently used for donations in that currency or commodity.
+func (h *ExchangeRateHandler) GetExchangeRate(c *gin.Context) {
+	denomination := strings.ToUpper(c.Param("denomination"))
+
+	rate, err := h.fabricService.GetExchangeRate(denomination)
+	if err != nil {
+		// A stale rate is as unusable as a missing one
+		if errors.Is(err, services.ErrInvalidState) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No current exchange rate for " + denomination, "code": services.CodeNotFound})
+			return
+		}
+		respondError(c, err, "Failed to get exchange rate")
+		return
+	}
+
+	c.JSON(http.StatusOK, rate)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/models"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/services"
+)
+
+// FitrahHandler handles zakat fitrah rate endpoints
+type FitrahHandler struct {
+	fabricService *services.FabricService
+}
+
+// NewFitrahHandler creates a new fitrah rate handler
+func NewFitrahHandler(fabricService *services.FabricService) *FitrahHandler {
+	return &FitrahHandler{
+		fabricService: fabricService,
+	}
+}
+
+// GetFitrahRate handles GET /api/fitrah-rates/:region?hijri_year=1446
+// The current Hijri year is used when hijri_year is omitted.
+func (h *FitrahHandler) GetFitrahRate(c *gin.Context) {
+	region := strings.ToUpper(c.Param("region"))
+
+	hijriYear := 0
+	if year := c.Query("hijri_year"); year != "" {
+		var err error
+		if hijriYear, err = strconv.Atoi(year); err != nil || hijriYear <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid hijri_year"})
+			return
+		}
+	}
+
+	rate, err := h.fabricService.GetFitrahRate(region, hijriYear)
+	if err != nil {
+		respondError(c, err, "Failed to get fitrah rate")
+		return
+	}
+
+	c.JSON(http.StatusOK, rate)
+}
+
+// SetFitrahRate handles PUT /api/admin/fitrah-rates (organization admins only)
+func (h *FitrahHandler) SetFitrahRate(c *gin.Context) {
+	var req models.SetFitrahRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	region := strings.ToUpper(req.Region)
+	if err := h.fabricService.SetFitrahRate(region, req.HijriYear, req.RatePerSoul, req.RiceKgPerSoul, userID.(string)); err != nil {
+		respondError(c, err, "Failed to set fitrah rate")
+		return
+	}
+
+	rate, err := h.fabricService.GetFitrahRate(region, req.HijriYear)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get fitrah rate"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Fitrah rate set successfully",
+		"rate":    rate,
+	})
+}
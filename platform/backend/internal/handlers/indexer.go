@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/models"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/services"
+)
+
+// IndexerHandler handles block indexer admin endpoints
+type IndexerHandler struct {
+	blockIndexer *services.BlockIndexer
+}
+
+// NewIndexerHandler creates a new indexer handler
+func NewIndexerHandler(blockIndexer *services.BlockIndexer) *IndexerHandler {
+	return &IndexerHandler{
+		blockIndexer: blockIndexer,
+	}
+}
+
+// GetStatus handles GET /api/admin/indexer
+func (h *IndexerHandler) GetStatus(c *gin.Context) {
+	status, err := h.blockIndexer.Status()
+	if err != nil {
+		respondError(c, err, "Failed to get indexer status")
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// SetCheckpoint handles PUT /api/admin/indexer/checkpoint, making the indexer continue from
+// the given block
+func (h *IndexerHandler) SetCheckpoint(c *gin.Context) {
+	var req models.SetIndexerCheckpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.blockIndexer.SetCheckpoint(*req.Block); err != nil {
+		respondError(c, err, "Failed to set indexer checkpoint")
+		return
+	}
+
+	status, err := h.blockIndexer.Status()
+	if err != nil {
+		respondError(c, err, "Failed to get indexer status")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Indexer checkpoint set",
+		"status":  status,
+	})
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/services"
+)
+
+// JobHandler handles background job admin endpoints
+type JobHandler struct {
+	jobQueue *services.JobQueue
+}
+
+// NewJobHandler creates a new job handler
+func NewJobHandler(jobQueue *services.JobQueue) *JobHandler {
+	return &JobHandler{
+		jobQueue: jobQueue,
+	}
+}
+
+// GetJobs handles GET /api/admin/jobs?state=
+// With a state (delayed, running or dead) all jobs in it are listed. Without one the stuck jobs
+// are: retrying (failed at least once), overdue (running past their lease) and dead.
+func (h *JobHandler) GetJobs(c *gin.Context) {
+	if state := c.Query("state"); state != "" {
+		jobs, err := h.jobQueue.ListJobs(state)
+		if err != nil {
+			respondError(c, err, "Failed to list jobs")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"state": state, "jobs": jobs})
+		return
+	}
+
+	delayed, err := h.jobQueue.ListJobs(services.JobDelayed)
+	if err != nil {
+		respondError(c, err, "Failed to list jobs")
+		return
+	}
+	running, err := h.jobQueue.ListJobs(services.JobRunning)
+	if err != nil {
+		respondError(c, err, "Failed to list jobs")
+		return
+	}
+	dead, err := h.jobQueue.ListJobs(services.JobDead)
+	if err != nil {
+		respondError(c, err, "Failed to list jobs")
+		return
+	}
+
+	retrying := []services.Job{}
+	for _, job := range delayed {
+		if job.Attempts > 0 {
+			retrying = append(retrying, job)
+		}
+	}
+	overdue := []services.Job{}
+	now := time.Now()
+	for _, job := range running {
+		if job.LeaseExpiry.Before(now) {
+			overdue = append(overdue, job)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"queued":   len(delayed),
+		"running":  len(running),
+		"retrying": retrying,
+		"overdue":  overdue,
+		"dead":     dead,
+	})
+}
+
+// RetryJob handles POST /api/admin/jobs/:id/retry, requeueing a dead job
+func (h *JobHandler) RetryJob(c *gin.Context) {
+	job, err := h.jobQueue.RetryDead(c.Param("id"))
+	if err != nil {
+		respondError(c, err, "Failed to retry job")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Job requeued",
+		"job":     job,
+	})
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/services"
+)
+
+// OfficerHandler handles officer analytics endpoints
+type OfficerHandler struct {
+	officerService *services.OfficerService
+}
+
+// NewOfficerHandler creates a new officer handler
+func NewOfficerHandler(officerService *services.OfficerService) *OfficerHandler {
+	return &OfficerHandler{
+		officerService: officerService,
+	}
+}
+
+// GetPerformance handles GET /api/admin/officers/performance?start_date=&end_date=
+// Admins get the organization's leaderboard (organization, default YDSF Malang), or one officer's
+// entry with officer_id. Officers only ever get their own entry. The period defaults to the
+// current month up to today.
+func (h *OfficerHandler) GetPerformance(c *gin.Context) {
+	now := time.Now()
+	startDate := c.DefaultQuery("start_date", now.Format("2006-01")+"-01")
+	endDate := c.DefaultQuery("end_date", now.Format("2006-01-02"))
+
+	if role, _ := c.Get("user_role"); role == "officer" {
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "User ID not found"})
+			return
+		}
+
+		performance, err := h.officerService.GetUserPerformance(userID.(string), startDate, endDate)
+		if err != nil {
+			respondError(c, err, "Failed to get officer performance")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"performance": performance})
+		return
+	}
+
+	organization := c.DefaultQuery("organization", "YDSF Malang")
+	if officerID := c.Query("officer_id"); officerID != "" {
+		performance, err := h.officerService.GetPerformance(officerID, organization, startDate, endDate)
+		if err != nil {
+			respondError(c, err, "Failed to get officer performance")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"performance": performance})
+		return
+	}
+
+	leaderboard, err := h.officerService.GetLeaderboard(organization, startDate, endDate)
+	if err != nil {
+		respondError(c, err, "Failed to get officer leaderboard")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"organization": organization,
+		"start_date":   startDate,
+		"end_date":     endDate,
+		"leaderboard":  leaderboard,
+	})
+}
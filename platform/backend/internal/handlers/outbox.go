@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/services"
+)
+
+// outboxListLimit caps how many entries GetOutbox returns per status
+const outboxListLimit = 100
+
+// OutboxHandler handles ledger outbox admin endpoints
+type OutboxHandler struct {
+	outboxService *services.OutboxService
+}
+
+// NewOutboxHandler creates a new outbox handler
+func NewOutboxHandler(outboxService *services.OutboxService) *OutboxHandler {
+	return &OutboxHandler{
+		outboxService: outboxService,
+	}
+}
+
+// GetOutbox handles GET /api/admin/outbox?status=
+// With a status (pending, done or failed) the oldest entries in it are listed. Without one the
+// entries that need attention are: pending (still being retried) and failed.
+func (h *OutboxHandler) GetOutbox(c *gin.Context) {
+	if status := c.Query("status"); status != "" {
+		entries, err := h.outboxService.ListEntries(status, outboxListLimit)
+		if err != nil {
+			respondError(c, err, "Failed to list outbox entries")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": status, "entries": entries})
+		return
+	}
+
+	pending, err := h.outboxService.ListEntries(services.OutboxPending, outboxListLimit)
+	if err != nil {
+		respondError(c, err, "Failed to list outbox entries")
+		return
+	}
+	failed, err := h.outboxService.ListEntries(services.OutboxFailed, outboxListLimit)
+	if err != nil {
+		respondError(c, err, "Failed to list outbox entries")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pending": pending,
+		"failed":  failed,
+	})
+}
+
+// RetryOutboxEntry handles POST /api/admin/outbox/:id/retry, requeueing an entry that ran out
+// of attempts
+func (h *OutboxHandler) RetryOutboxEntry(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid outbox entry ID"})
+		return
+	}
+
+	entry, err := h.outboxService.Retry(id)
+	if err != nil {
+		respondError(c, err, "Failed to retry outbox entry")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Outbox entry requeued",
+		"entry":   entry,
+	})
+}
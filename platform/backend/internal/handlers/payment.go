@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/services"
+)
+
+// maxWebhookBody bounds the size of accepted payment notifications
+const maxWebhookBody = 64 << 10
+
+// PaymentHandler handles payment gateway endpoints
+type PaymentHandler struct {
+	paymentService *services.PaymentService
+}
+
+// NewPaymentHandler creates a new payment handler
+func NewPaymentHandler(paymentService *services.PaymentService) *PaymentHandler {
+	return &PaymentHandler{
+		paymentService: paymentService,
+	}
+}
+
+// Webhook handles POST /api/payments/webhook.
+// Notifications must be signed by the gateway; a paid one validates its donation. Any
+// non-2xx response makes the gateway retry, so repeated notifications are acknowledged.
+func (h *PaymentHandler) Webhook(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBody))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read notification"})
+		return
+	}
+
+	if err := h.paymentService.HandleWebhook(body, c.Request.Header); err != nil {
+		if errors.Is(err, services.ErrInvalidSignature) {
+			log.Printf("⚠️ Rejected payment notification from %s: %v", c.ClientIP(), err)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		respondError(c, err, "Failed to process payment notification")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// GetPaymentStatus handles GET /api/donations/:id/payment.
+// The status is queried from the gateway, so a paid donation is validated even if its
+// notification was lost.
+func (h *PaymentHandler) GetPaymentStatus(c *gin.Context) {
+	donationID := c.Param("id")
+	if donationID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Donation ID is required"})
+		return
+	}
+
+	status, err := h.paymentService.RefreshPayment(donationID)
+	if err != nil {
+		respondError(c, err, "Failed to get payment status")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"donation_id": donationID,
+		"status":      status.Status,
+		"reference":   status.Reference,
+		"method":      status.Method,
+		"amount":      status.Amount,
+	})
+}
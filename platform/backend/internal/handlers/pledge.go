@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/models"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/services"
+)
+
+// PledgeHandler handles recurring pledge endpoints
+type PledgeHandler struct {
+	pledgeService *services.PledgeService
+}
+
+// NewPledgeHandler creates a new pledge handler
+func NewPledgeHandler(pledgeService *services.PledgeService) *PledgeHandler {
+	return &PledgeHandler{
+		pledgeService: pledgeService,
+	}
+}
+
+// CreatePledge handles POST /api/pledges, from guests or signed-in donors
+func (h *PledgeHandler) CreatePledge(c *gin.Context) {
+	var req models.CreatePledgeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Pledges made while signed in as a donor belong to their account
+	if c.GetString("user_role") == "donor" {
+		if donorID, err := uuid.Parse(c.GetString("user_id")); err == nil {
+			req.DonorID = donorID
+		}
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	startDate := today
+	if req.StartDate != "" {
+		startDate, _ = time.ParseInLocation("2006-01-02", req.StartDate, now.Location()) // Format checked by binding
+		if startDate.Before(today) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "start_date cannot be in the past"})
+			return
+		}
+	}
+
+	pledge, err := h.pledgeService.CreatePledge(req, startDate)
+	if err != nil {
+		respondError(c, err, "Failed to create pledge")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Pledge created successfully",
+		"pledge":  pledge,
+	})
+}
+
+// GetPledge handles GET /api/pledges/:id. The donor's contact details are left out.
+func (h *PledgeHandler) GetPledge(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Pledge ID is required"})
+		return
+	}
+
+	pledge, err := h.pledgeService.GetPledge(id)
+	if err != nil {
+		respondError(c, err, "Failed to get pledge")
+		return
+	}
+
+	c.JSON(http.StatusOK, pledge.PublicView())
+}
+
+// CancelPledge handles POST /api/pledges/:id/cancel, by the pledge's donor or an admin
+func (h *PledgeHandler) CancelPledge(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Pledge ID is required"})
+		return
+	}
+
+	var req models.CancelPledgeRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	pledge, err := h.pledgeService.CancelPledge(id, userID, c.GetString("user_role"), req.Reason)
+	if err != nil {
+		respondError(c, err, "Failed to cancel pledge")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Pledge cancelled successfully",
+		"pledge":  pledge,
+	})
+}
+
+// GetPledges handles GET /api/admin/pledges
+func (h *PledgeHandler) GetPledges(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	pledges, err := h.pledgeService.GetPledges(c.Query("status"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get pledges"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pledges": pledges,
+		"pagination": gin.H{
+			"limit":  limit,
+			"offset": offset,
+		},
+	})
+}
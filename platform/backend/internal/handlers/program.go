@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/services"
+)
+
+// ProgramHandler handles program endpoints
+type ProgramHandler struct {
+	programService *services.ProgramService
+}
+
+// NewProgramHandler creates a new program handler
+func NewProgramHandler(programService *services.ProgramService) *ProgramHandler {
+	return &ProgramHandler{
+		programService: programService,
+	}
+}
+
+// GetProgramSummary handles GET /api/programs/:id/summary
+// It returns the campaign progress shown on a program's public page.
+func (h *ProgramHandler) GetProgramSummary(c *gin.Context) {
+	programID := c.Param("id")
+	if programID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Program ID is required"})
+		return
+	}
+
+	summary, err := h.programService.GetProgramSummary(programID)
+	if err != nil {
+		respondError(c, err, "Failed to get program summary")
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/services"
+)
+
+// ReceiptHandler handles donation receipt endpoints
+type ReceiptHandler struct {
+	receiptService *services.ReceiptService
+}
+
+// NewReceiptHandler creates a new receipt handler
+func NewReceiptHandler(receiptService *services.ReceiptService) *ReceiptHandler {
+	return &ReceiptHandler{
+		receiptService: receiptService,
+	}
+}
+
+// GetReceipt handles GET /api/admin/donations/:id/receipt. Donors get theirs from /api/me.
+func (h *ReceiptHandler) GetReceipt(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Donation ID is required"})
+		return
+	}
+
+	receipt, err := h.receiptService.IssueReceipt(id)
+	if err != nil {
+		if errors.Is(err, services.ErrReceiptNotAvailable) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		respondError(c, err, "Failed to issue receipt")
+		return
+	}
+
+	c.JSON(http.StatusOK, receipt)
+}
+
+// VerifyReceipt handles GET /api/receipts/verify?token=...
+// It needs no authentication so anyone holding a receipt can check it.
+func (h *ReceiptHandler) VerifyReceipt(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Receipt token is required"})
+		return
+	}
+
+	result, err := h.receiptService.VerifyReceipt(token)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidReceiptToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid receipt token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify receipt"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetPublicKey handles GET /api/receipts/public-key
+func (h *ReceiptHandler) GetPublicKey(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"algorithm":  "ed25519",
+		"public_key": h.receiptService.PublicKey(),
+	})
+}
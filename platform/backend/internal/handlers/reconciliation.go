@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/services"
+)
+
+// ReconciliationHandler handles ledger reconciliation admin endpoints
+type ReconciliationHandler struct {
+	reconciliationService *services.ReconciliationService
+}
+
+// NewReconciliationHandler creates a new reconciliation handler
+func NewReconciliationHandler(reconciliationService *services.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{
+		reconciliationService: reconciliationService,
+	}
+}
+
+// GetReconciliation handles GET /api/admin/reconciliation?limit=
+// It returns the latest run with its mismatches and the history of recent runs.
+func (h *ReconciliationHandler) GetReconciliation(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	latest, err := h.reconciliationService.LatestRun()
+	if err != nil {
+		respondError(c, err, "Failed to get reconciliation")
+		return
+	}
+	history, err := h.reconciliationService.ListRuns(limit)
+	if err != nil {
+		respondError(c, err, "Failed to get reconciliation")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"latest":  latest,
+		"history": history,
+	})
+}
+
+// GetRun handles GET /api/admin/reconciliation/:id
+func (h *ReconciliationHandler) GetRun(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reconciliation run ID"})
+		return
+	}
+
+	run, err := h.reconciliationService.GetRun(id)
+	if err != nil {
+		respondError(c, err, "Failed to get reconciliation run")
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// Run handles POST /api/admin/reconciliation/run, starting a reconciliation in the background.
+// Its progress is followed with GET /api/admin/reconciliation/:id.
+func (h *ReconciliationHandler) Run(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	run, err := h.reconciliationService.Trigger(userID.(string))
+	if err != nil {
+		respondError(c, err, "Failed to start reconciliation")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Reconciliation started",
+		"run":     run,
+	})
+}
@@ -66,3 +66,45 @@ func AdminRequired() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// OrgAdminRequired middleware for organization admin access. Officers handle day-to-day
+// donations; moving funds, setting rates and operating the ledger sync are left to admins.
+func OrgAdminRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if role, _ := c.Get("user_role"); role != "org_admin" && role != "super_admin" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Organization admin access required"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// OptionalAuth sets the user context like AuthRequired when a valid token is given, and lets the
+// request through as a guest otherwise
+func OptionalAuth(jwtService *services.JWTService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parts := strings.Split(c.GetHeader("Authorization"), " ")
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			if claims, err := jwtService.ValidateToken(parts[1]); err == nil {
+				c.Set("user_id", claims.UserID)
+				c.Set("user_role", claims.Role)
+			}
+		}
+		c.Next()
+	}
+}
+
+// DonorRequired middleware for donor access, with a token from OTP sign-in
+func DonorRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if role, _ := c.Get("user_role"); role != "donor" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Donor access required"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -31,7 +31,7 @@ Valid   bool
 // User represents a user in the system
 type User struct {
 	ID             uuid.UUID      `json:"id"`
-	Phone          string         `json:"phone"`
+	Phone        sql.NullString `json:"phone"` // Donors signed in by email may have none
 	Email          sql.NullString `json:"email"`
 	Name           string         `json:"name"`
 	Role           string         `json:"role"` // donor, officer, org_admin, super_admin
@@ -49,22 +49,102 @@ type Donation struct {
 	DonorName        string         `json:"donor_name"`
 	DonorPhone       string         `json:"donor_phone"`
 	DonorEmail       sql.NullString `json:"donor_email"`
+	NormalizedPhone   sql.NullString `json:"-" gorm:"column:donor_phone_normalized"` // +62...; matches guest donations to donor accounts
+	IsAnonymous       bool           `json:"is_anonymous"`
+	DisplayName       sql.NullString `json:"display_name"` // Public alias for anonymous donations
 	Amount           float64        `json:"amount"`
 	Type             string         `json:"type"` // fitrah, maal
 	ProgramID        sql.NullString `json:"program_id"`
 	ReferralCode     sql.NullString `json:"referral_code"`
 	BlockchainStatus string         `json:"blockchain_status"` // pending, collected, distributed
 	SyncStatus       string         `json:"sync_status"`       // synced, pending_sync, error
+	Organization      string         `json:"organization"`      // YDSF Malang, YDSF Jatim
+	PaymentMethod     string         `json:"payment_method"`    // qris, transfer, ewallet, cash
 	PaymentReference sql.NullString `json:"payment_reference"`
+	PaymentProvider   sql.NullString `json:"payment_provider"` // Gateway the donation is paid through
+	PaymentURL        sql.NullString `json:"payment_url"`      // Where the donor completes the payment
+	QRISPayload       sql.NullString `json:"qris_payload"`     // Dynamic QRIS for qris payments
 	ValidatedAt      sql.NullTime   `json:"validated_at"`
 	ValidatedBy      sql.NullString `json:"validated_by"`
 	DistributedAt    sql.NullTime   `json:"distributed_at"`
 	DistributedBy    sql.NullString `json:"distributed_by"`
 	BlockchainTxID   sql.NullString `json:"blockchain_tx_id"`
+	PledgeID          sql.NullString `json:"pledge_id"`          // Set when created by a recurring pledge
+	PledgePeriod      sql.NullString `json:"pledge_period"`      // 2025-03 (monthly), 2025 (yearly), 1446H (ramadan)
+	Souls             int            `json:"souls"`              // Zakat fitrah: number of people paid for
+	SoulNames         sql.NullString `json:"soul_names"`         // Zakat fitrah: JSON array of their names (optional)
+	Denomination      string         `json:"denomination"`       // IDR, or e.g. USD, SAR, XAU_G (grams of gold)
+	OriginalAmount    float64        `json:"original_amount"`    // Amount in Denomination units; Amount is always IDR
+	ExchangeRate      float64        `json:"exchange_rate"`      // IDR per unit applied (1 for IDR)
+	DistributedAmount float64        `json:"distributed_amount"` // Allocated to distributions so far
+	RequestKey        sql.NullString `json:"-"`                  // Caller's idempotency key (api:..., pledge:...)
 	CreatedAt        time.Time      `json:"created_at"`
 	UpdatedAt        time.Time      `json:"updated_at"`
 }
 
+// DefaultAnonymousAlias is shown publicly for anonymous donations without a chosen alias
+const DefaultAnonymousAlias = "Hamba Allah"
+
+// PublicView returns a copy of the donation that is safe for unauthenticated responses.
+// Anonymous donors are shown by their display alias with contact details removed.
+func (d Donation) PublicView() Donation {
+	if !d.IsAnonymous {
+		return d
+	}
+	alias := DefaultAnonymousAlias
+	if d.DisplayName.Valid && d.DisplayName.String != "" {
+		alias = d.DisplayName.String
+	}
+	d.DonorID = uuid.NullUUID{}
+	d.DonorName = alias
+	d.DonorPhone = ""
+	d.DonorEmail = sql.NullString{}
+	return d
+}
+
+// Pledge represents a recurring zakat commitment
+type Pledge struct {
+	ID             string         `json:"id"`       // PLG-YDSF-MLG-{TIMESTAMP}-0001
+	DonorID        uuid.NullUUID  `json:"donor_id"` // Set when made by a signed-in donor
+	DonorName      string         `json:"donor_name"`
+	DonorPhone     string         `json:"donor_phone"`
+	DonorEmail     sql.NullString `json:"donor_email"`
+	IsAnonymous    bool           `json:"is_anonymous"`
+	DisplayName    sql.NullString `json:"display_name"` // Public alias for anonymous pledges
+	Amount         float64        `json:"amount"`       // Amount per period
+	Type           string         `json:"type"`         // fitrah, maal
+	Schedule       string         `json:"schedule"`     // monthly, yearly, ramadan
+	ProgramID      sql.NullString `json:"program_id"`
+	ReferralCode   sql.NullString `json:"referral_code"`
+	Organization   string         `json:"organization"`   // YDSF Malang, YDSF Jatim
+	PaymentMethod  string         `json:"payment_method"` // Used for every scheduled donation
+	Status         string         `json:"status"`         // active, cancelled
+	StartDate      time.Time      `json:"start_date"`
+	NextDueAt      sql.NullTime   `json:"next_due_at"`     // Null when no further period can be scheduled
+	ScheduledCount int            `json:"scheduled_count"` // Donations created so far
+	FulfilledCount int            `json:"fulfilled_count"` // Donations validated so far
+	TotalFulfilled float64        `json:"total_fulfilled"`
+	CancelledAt    sql.NullTime   `json:"cancelled_at"`
+	CancelReason   sql.NullString `json:"cancel_reason"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// PublicView returns a copy of the pledge that is safe for unauthenticated responses, with the
+// donor's account and contact details removed. Anonymous donors are shown by their display alias.
+func (p Pledge) PublicView() Pledge {
+	if p.IsAnonymous {
+		p.DonorName = DefaultAnonymousAlias
+		if p.DisplayName.Valid && p.DisplayName.String != "" {
+			p.DonorName = p.DisplayName.String
+		}
+	}
+	p.DonorID = uuid.NullUUID{}
+	p.DonorPhone = ""
+	p.DonorEmail = sql.NullString{}
+	return p
+}
+
 // Program represents a zakat program
 type Program struct {
 	ID              string          `json:"id"`
@@ -73,6 +153,7 @@ type Program struct {
 	Organization    string          `json:"organization"`
 	TargetAmount    sql.NullFloat64 `json:"target_amount"`
 	CollectedAmount float64         `json:"collected_amount"`
+	DistributedAmount float64         `json:"distributed_amount"`
 	IsActive        bool            `json:"is_active"`
 	CreatedAt       time.Time       `json:"created_at"`
 }
@@ -87,9 +168,25 @@ type Distribution struct {
 	DistributionDate sql.NullTime   `json:"distribution_date"`
 	DistributedBy    sql.NullString `json:"distributed_by"`
 	BlockchainTxID   sql.NullString `json:"blockchain_tx_id"`
+	ProgramDistributionID sql.NullString `json:"program_distribution_id"` // Set for shares of a program distribution
 	CreatedAt        time.Time      `json:"created_at"`
 }
 
+// ProgramDistribution represents a distribution paid from a program's pooled funds.
+// Allocations are its shares per donation, oldest collected donation first.
+type ProgramDistribution struct {
+	ID               string         `json:"id"`
+	ProgramID        string         `json:"program_id"`
+	RecipientName    string         `json:"recipient_name"`
+	RecipientDetails sql.NullString `json:"recipient_details"` // JSONB
+	Amount           float64        `json:"amount"`
+	DistributionDate time.Time      `json:"distribution_date"`
+	DistributedBy    string         `json:"distributed_by"`
+	BlockchainTxID   sql.NullString `json:"blockchain_tx_id"`
+	CreatedAt        time.Time      `json:"created_at"`
+	Allocations      []Distribution `json:"allocations" gorm:"-"`
+}
+
 // AuditLog represents an audit log entry
 type AuditLog struct {
 	ID          uuid.UUID `json:"id"`
@@ -101,6 +198,60 @@ type AuditLog struct {
 	CreatedAt   time.Time `json:"created_at"`
 }
 
+// OutboxEntry is a ledger submission persisted together with the records it belongs to
+type OutboxEntry struct {
+	ID            uuid.UUID  `json:"id"`
+	AggregateType string     `json:"aggregate_type"` // donation
+	AggregateID   string     `json:"aggregate_id"`
+	Operation     string     `json:"operation"` // create, validate, distribute, reallocate
+	Payload       string     `json:"payload"`   // JSONB
+	Status        string     `json:"status"`    // pending, done, failed
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	CompletedAt   *time.Time `json:"completed_at"`
+}
+
+// IndexerCheckpoint is the next ledger block the block indexer reads
+type IndexerCheckpoint struct {
+	Name      string    `json:"name"` // {channel}/{chaincode}
+	NextBlock int64     `json:"next_block"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ReconciliationRun is one comparison of the ledger's zakat with the donations table.
+// Mismatch counts are per category; the mismatches themselves are only loaded for a single run.
+type ReconciliationRun struct {
+	ID                    uuid.UUID                `json:"id"`
+	Trigger               string                   `json:"trigger"` // schedule, manual
+	TriggeredBy           string                   `json:"triggered_by"`
+	Status                string                   `json:"status"` // running, completed, failed
+	LedgerCount           int                      `json:"ledger_count"`
+	DatabaseCount         int                      `json:"database_count"`
+	MissingInDB           int                      `json:"missing_in_db"`
+	MissingOnLedger       int                      `json:"missing_on_ledger"`
+	StatusMismatches      int                      `json:"status_mismatches"`
+	AmountMismatches      int                      `json:"amount_mismatches"`
+	DistributedMismatches int                      `json:"distributed_mismatches"`
+	FixedCount            int                      `json:"fixed_count"`
+	MismatchDetails       string                   `json:"-"` // JSONB
+	Mismatches            []ReconciliationMismatch `json:"mismatches,omitempty" gorm:"-"`
+	Error                 string                   `json:"error,omitempty"`
+	StartedAt             time.Time                `json:"started_at"`
+	CompletedAt           *time.Time               `json:"completed_at"`
+}
+
+// ReconciliationMismatch is one difference found between a zakat on the ledger and its donation
+type ReconciliationMismatch struct {
+	DonationID string `json:"donation_id"`
+	Category   string `json:"category"` // missing_in_db, missing_on_ledger, status, amount, distributed_amount
+	Ledger     string `json:"ledger,omitempty"`
+	Database   string `json:"database,omitempty"`
+	Fixed      bool   `json:"fixed"` // Corrected in the database by the run
+}
+
 // APIRequest and APIResponse structs for handlers
 
 // CreateDonationRequest for POST /api/donations
@@ -108,10 +259,112 @@ type CreateDonationRequest struct {
 	Name         string  `json:"name" binding:"required"`
 	Phone        string  `json:"phone" binding:"required"`
 	Email        string  `json:"email"`
-	Amount       float64 `json:"amount" binding:"required,gt=0"`
+	Amount        float64  `json:"amount" binding:"required_without=OriginalAmount,gte=0"` // IDR; derived from OriginalAmount for other denominations
 	Type         string  `json:"type" binding:"required,oneof=fitrah maal"`
 	ProgramID    string  `json:"program_id"`
 	ReferralCode string  `json:"referral_code"`
+	Organization  string   `json:"organization"`                                 // Defaults to YDSF Malang
+	PaymentMethod string   `json:"payment_method"`                               // One the organization accepts; defaults to its first
+	Anonymous     bool     `json:"anonymous"`                                    // Hide the donor's name publicly ("Hamba Allah")
+	DisplayName   string   `json:"display_name"`                                 // Optional public alias for anonymous donations
+	Souls         int      `json:"souls" binding:"omitempty,gte=1,lte=100"`      // Zakat fitrah: number of people paid for
+	SoulNames     []string `json:"soul_names" binding:"omitempty,dive,required"` // Zakat fitrah: their names (optional)
+
+	Denomination   string  `json:"denomination" binding:"required_with=OriginalAmount"` // USD, SAR, XAU_G, ...; empty for IDR
+	OriginalAmount float64 `json:"original_amount" binding:"gte=0"`                     // Amount in Denomination units
+
+	DonorID uuid.UUID `json:"-"` // Signed-in donor, taken from their token; uuid.Nil for guests
+}
+
+// Receipt is the signed payload of a donation receipt
+type Receipt struct {
+	ZakatID      string  `json:"zakat_id"`
+	Amount       float64 `json:"amount"` // IDR
+	Date         string  `json:"date"`   // Validation date on the ledger (RFC3339)
+	Organization string  `json:"organization"`
+	TxID         string  `json:"tx_id"`        // Ledger transaction that validated the payment
+	ReceiptHash  string  `json:"receipt_hash"` // Hash recorded on the ledger at validation
+	IssuedAt     string  `json:"issued_at"`
+}
+
+// ReceiptResponse for GET /api/me/donations/:id/receipt and GET /api/admin/donations/:id/receipt
+type ReceiptResponse struct {
+	Receipt   Receipt `json:"receipt"`
+	Signature string  `json:"signature"`  // Base64url ed25519 signature over the receipt JSON in Token
+	Token     string  `json:"token"`      // {base64url receipt JSON}.{signature}
+	VerifyURL string  `json:"verify_url"` // Public verification link, also encoded in QRCode
+	QRCode    string  `json:"qr_code"`    // PNG data URI
+}
+
+// ReceiptVerificationResponse for GET /api/receipts/verify
+type ReceiptVerificationResponse struct {
+	Valid          bool     `json:"valid"`
+	SignatureValid bool     `json:"signature_valid"`
+	LedgerValid    bool     `json:"ledger_valid"`
+	Reason         string   `json:"reason,omitempty"`
+	Receipt        *Receipt `json:"receipt,omitempty"`
+	LedgerStatus   string   `json:"ledger_status,omitempty"`
+}
+
+// SetFitrahRateRequest for PUT /api/admin/fitrah-rates
+type SetFitrahRateRequest struct {
+	Region        string  `json:"region" binding:"required"`
+	HijriYear     int     `json:"hijri_year" binding:"required,gte=1400,lte=1600"`
+	RatePerSoul   float64 `json:"rate_per_soul" binding:"required,gt=0"`
+	RiceKgPerSoul float64 `json:"rice_kg_per_soul" binding:"gte=0"`
+}
+
+// DistributeFromProgramRequest for POST /api/admin/programs/:id/distributions
+type DistributeFromProgramRequest struct {
+	RecipientName    string                 `json:"recipient_name" binding:"required"`
+	RecipientDetails map[string]interface{} `json:"recipient_details"` // Optional address, NIK, asnaf, ...
+	Amount           float64                `json:"amount" binding:"required,gt=0"`
+}
+
+// ValidateDonationRequest for POST /api/admin/donations/:id/validate
+type ValidateDonationRequest struct {
+	ReceiptNumber string `json:"receipt_number" binding:"required,max=100"` // Bank transfer or payment gateway reference
+}
+
+// DistributeDonationRequest for POST /api/admin/donations/:id/distribute
+type DistributeDonationRequest struct {
+	RecipientName    string                 `json:"recipient_name" binding:"required,max=255"`
+	RecipientDetails map[string]interface{} `json:"recipient_details"` // Optional address, NIK, asnaf, ...
+	Amount           float64                `json:"amount" binding:"required,gt=0"`
+}
+
+// ReallocateDonationRequest for POST /api/admin/donations/:id/reallocate
+type ReallocateDonationRequest struct {
+	ProgramID string `json:"program_id" binding:"required"`
+	Reason    string `json:"reason" binding:"required"`
+}
+
+// SetIndexerCheckpointRequest for PUT /api/admin/indexer/checkpoint
+type SetIndexerCheckpointRequest struct {
+	Block *uint64 `json:"block" binding:"required"` // Next block to index; 0 reindexes the channel
+}
+
+// CreatePledgeRequest for POST /api/pledges
+type CreatePledgeRequest struct {
+	Name          string    `json:"name" binding:"required"`
+	Phone         string    `json:"phone" binding:"required"`
+	Email         string    `json:"email"`
+	Amount        float64   `json:"amount" binding:"required,gt=0"`
+	Type          string    `json:"type" binding:"required,oneof=fitrah maal"`
+	Schedule      string    `json:"schedule" binding:"required,oneof=monthly yearly ramadan"`
+	ProgramID     string    `json:"program_id"`
+	ReferralCode  string    `json:"referral_code"`
+	Organization  string    `json:"organization"`                                       // Defaults to YDSF Malang
+	PaymentMethod string    `json:"payment_method"`                                     // One the organization accepts; defaults to its first
+	Anonymous     bool      `json:"anonymous"`                                          // Hide the donor's name publicly ("Hamba Allah")
+	DisplayName   string    `json:"display_name"`                                       // Optional public alias for anonymous pledges
+	StartDate     string    `json:"start_date" binding:"omitempty,datetime=2006-01-02"` // Defaults to today
+	DonorID       uuid.UUID `json:"-"`                                                  // Signed-in donor, taken from their token; uuid.Nil for guests
+}
+
+// CancelPledgeRequest for POST /api/pledges/:id/cancel
+type CancelPledgeRequest struct {
+	Reason string `json:"reason"`
 }
 
 // AdminLoginRequest for POST /api/auth/admin/login
@@ -126,6 +379,55 @@ type AdminLoginResponse struct {
 	User  User   `json:"user"`
 }
 
+// SendOTPRequest for POST /api/auth/otp/send; exactly one of phone and email is given
+type SendOTPRequest struct {
+	Phone   string `json:"phone"`
+	Email   string `json:"email"`
+	Channel string `json:"channel"` // sms or whatsapp (default) for a phone; email for an email address
+}
+
+// VerifyOTPRequest for POST /api/auth/otp/verify
+type VerifyOTPRequest struct {
+	Phone string `json:"phone"`
+	Email string `json:"email"`
+	OTP   string `json:"otp" binding:"required"`
+}
+
+// DonorLoginResponse for POST /api/auth/otp/verify
+type DonorLoginResponse struct {
+	Token string `json:"token"`
+	User  User   `json:"user"`
+}
+
+// DonorYearTotal is what a donor paid in one year (WIB), counted by when each payment was validated
+type DonorYearTotal struct {
+	Year      int     `json:"year"`
+	Donations int     `json:"donations"`
+	Amount    float64 `json:"amount"` // IDR
+	Maal      float64 `json:"maal"`
+	Fitrah    float64 `json:"fitrah"`
+}
+
+// DonorDonationsResponse for GET /api/me/donations
+type DonorDonationsResponse struct {
+	Donations    []*Donation      `json:"donations"`
+	Total        int64            `json:"total"`
+	Claimable    []*Donation      `json:"claimable"` // Guest donations made with the donor's phone or email
+	YearlyTotals []DonorYearTotal `json:"yearly_totals"`
+}
+
+// DonorDonationResponse for GET /api/me/donations/:id
+type DonorDonationResponse struct {
+	Donation         Donation `json:"donation"`
+	LedgerStatus     string   `json:"ledger_status"` // Live from the ledger; empty when it cannot be reached
+	ReceiptAvailable bool     `json:"receipt_available"`
+}
+
+// ClaimDonationsRequest for POST /api/me/donations/claim; without IDs every claimable donation is claimed
+type ClaimDonationsRequest struct {
+	DonationIDs []string `json:"donation_ids"`
+}
+
 // DashboardMetrics for GET /api/admin/dashboard
 type DashboardMetrics struct {
 	PendingDonations      int     `json:"pending_donations"`
@@ -135,6 +437,35 @@ type DashboardMetrics struct {
 	NetworkHealth         string  `json:"network_health"` // healthy, warning, error
 	BlockchainHeight      uint64  `json:"blockchain_height"`
 	ChaincodeInstantiated bool    `json:"chaincode_instantiated"`
+
+	CollectedByDenomination []DenominationTotal `json:"collected_by_denomination"`
+
+	Channel          string       `json:"channel"`
+	CurrentBlockHash string       `json:"current_block_hash"`
+	Nodes            []NodeHealth `json:"nodes"` // Peer and orderer operations endpoints
+}
+
+// ChainInfo is a channel's ledger height as reported by the query system chaincode
+type ChainInfo struct {
+	Channel           string `json:"channel"`
+	Height            uint64 `json:"height"`
+	CurrentBlockHash  string `json:"current_block_hash"`
+	PreviousBlockHash string `json:"previous_block_hash"`
+}
+
+// NodeHealth is the /healthz result of one peer or orderer operations endpoint
+type NodeHealth struct {
+	Endpoint string `json:"endpoint"`
+	Status   string `json:"status"` // healthy, error
+	Error    string `json:"error,omitempty"`
+}
+
+// DenominationTotal is the collected total of one denomination; AmountIDR is what it counts for in IDR totals
+type DenominationTotal struct {
+	Denomination   string  `json:"denomination"`
+	OriginalAmount float64 `json:"original_amount"`
+	AmountIDR      float64 `json:"amount_idr"`
+	Count          int     `json:"count"`
 }
 
 // RecentActivity for GET /api/admin/dashboard
@@ -148,4 +479,5 @@ type RecentActivity struct {
 type DashboardResponse struct {
 	Metrics        DashboardMetrics `json:"metrics"`
 	RecentActivity []RecentActivity `json:"recent_activity"`
+	GeneratedAt    time.Time        `json:"generated_at"` // Responses are cached for DASHBOARD_CACHE_TTL
 }
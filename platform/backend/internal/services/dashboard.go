@@ -0,0 +1,95 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/models"
+	"github.com/izzuddinafif/fabric/platform/backend/pkg/database"
+)
+
+const (
+	dashboardRedisKey   = "admin-dashboard"
+	recentActivityLimit = 10
+)
+
+// DashboardService builds the admin dashboard from Postgres aggregates and the network's
+// ledger and node health, cached in Redis
+type DashboardService struct {
+	donationService *DonationService
+	networkService  *NetworkService
+	redis           *redis.Client
+	cacheTTL        time.Duration
+}
+
+// NewDashboardService creates a new dashboard service caching dashboards for cacheTTL
+func NewDashboardService(donationService *DonationService, networkService *NetworkService, redis *redis.Client, cacheTTL time.Duration) *DashboardService {
+	return &DashboardService{
+		donationService: donationService,
+		networkService:  networkService,
+		redis:           redis,
+		cacheTTL:        cacheTTL,
+	}
+}
+
+// GetDashboard returns the admin dashboard, computing it at most once per cache TTL
+func (s *DashboardService) GetDashboard() (*models.DashboardResponse, error) {
+	if data, err := s.redis.Get(database.Ctx, dashboardRedisKey).Bytes(); err == nil {
+		var dashboard models.DashboardResponse
+		if err := json.Unmarshal(data, &dashboard); err == nil {
+			return &dashboard, nil
+		}
+	} else if err != redis.Nil {
+		log.Printf("❌ Failed to read cached dashboard: %v", err)
+	}
+
+	dashboard, err := s.buildDashboard()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(dashboard)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal dashboard: %w", err)
+	}
+	if err := s.redis.Set(database.Ctx, dashboardRedisKey, data, s.cacheTTL).Err(); err != nil {
+		log.Printf("❌ Failed to cache dashboard: %v", err)
+	}
+
+	return dashboard, nil
+}
+
+func (s *DashboardService) buildDashboard() (*models.DashboardResponse, error) {
+	metrics, err := s.donationService.GetDashboardMetrics()
+	if err != nil {
+		return nil, err
+	}
+	activity, err := s.donationService.GetRecentActivity(recentActivityLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	// An unreachable network degrades the dashboard's health instead of failing it
+	ledgerOK := true
+	chainInfo, err := s.networkService.GetChainInfo()
+	if err != nil {
+		log.Printf("❌ Failed to get chain info: %v", err)
+		ledgerOK = false
+	} else {
+		metrics.Channel = chainInfo.Channel
+		metrics.BlockchainHeight = chainInfo.Height
+		metrics.CurrentBlockHash = chainInfo.CurrentBlockHash
+	}
+	metrics.ChaincodeInstantiated = s.networkService.ChaincodeReady()
+	metrics.Nodes = s.networkService.CheckNodes()
+	metrics.NetworkHealth = OverallHealth(metrics.Nodes, ledgerOK && metrics.ChaincodeInstantiated)
+
+	return &models.DashboardResponse{
+		Metrics:        *metrics,
+		RecentActivity: activity,
+		GeneratedAt:    time.Now(),
+	}, nil
+}
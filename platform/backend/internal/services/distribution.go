@@ -0,0 +1,213 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInsufficientProgramFunds is returned when a distribution exceeds a program's undistributed funds
+var ErrInsufficientProgramFunds = errors.New("insufficient program funds")
+
+// DistributionService handles distributions paid from programs' pooled funds
+type DistributionService struct {
+	fabricService  *FabricService
+	db             *gorm.DB
+	programService *ProgramService
+}
+
+// NewDistributionService creates a new distribution service
+func NewDistributionService(fabricService *FabricService, db *gorm.DB) *DistributionService {
+	return &DistributionService{
+		fabricService: fabricService,
+		db:            db,
+	}
+}
+
+// SetProgramService sets the program service whose cached summaries distributions invalidate
+func (s *DistributionService) SetProgramService(programService *ProgramService) {
+	s.programService = programService
+}
+
+// DistributeFromProgram distributes from a program's pooled funds on the ledger and mirrors the
+// distribution and its per-donation allocations in the database
+func (s *DistributionService) DistributeFromProgram(programID string, req models.DistributeFromProgramRequest, distributedBy string) (*models.ProgramDistribution, error) {
+	log.Printf("🎯 Program distribution requested from %s: Rp %.2f to %s by %s", programID, req.Amount, req.RecipientName, distributedBy)
+
+	recipientDetails := sql.NullString{}
+	if len(req.RecipientDetails) > 0 {
+		detailsJSON, err := json.Marshal(req.RecipientDetails)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal recipient details: %w", err)
+		}
+		recipientDetails = sql.NullString{String: string(detailsJSON), Valid: true}
+	}
+
+	ledger, err := s.fabricService.DistributeFromProgram(programID, req.RecipientName, req.Amount, distributedBy)
+	if err != nil {
+		if errors.Is(err, ErrInvalidState) {
+			return nil, fmt.Errorf("%w: %w", ErrInsufficientProgramFunds, err)
+		}
+		return nil, fmt.Errorf("failed to distribute from program on blockchain: %w", err)
+	}
+	if s.programService != nil {
+		s.programService.InvalidateSummary(programID)
+	}
+
+	distributedAt, err := time.Parse(time.RFC3339, ledger.DistributedAt)
+	if err != nil {
+		distributedAt = time.Now()
+	}
+
+	distribution := &models.ProgramDistribution{
+		ID:               ledger.ID,
+		ProgramID:        programID,
+		RecipientName:    req.RecipientName,
+		RecipientDetails: recipientDetails,
+		Amount:           ledger.Amount,
+		DistributionDate: distributedAt,
+		DistributedBy:    distributedBy,
+		CreatedAt:        time.Now(),
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(distribution)
+		if result.Error != nil {
+			return fmt.Errorf("failed to insert program distribution: %w", result.Error)
+		}
+
+		if result.RowsAffected == 0 {
+			// The block indexer recorded the distribution, its shares and the ledger's totals first
+			if err := tx.Model(&models.ProgramDistribution{}).Where("id = ?", ledger.ID).
+				Update("recipient_details", recipientDetails).Error; err != nil {
+				return fmt.Errorf("failed to update program distribution: %w", err)
+			}
+			if err := tx.Model(&models.Distribution{}).Where("program_distribution_id = ?", ledger.ID).
+				Update("recipient_details", recipientDetails).Error; err != nil {
+				return fmt.Errorf("failed to update program distribution shares: %w", err)
+			}
+			if err := tx.Where("program_distribution_id = ?", ledger.ID).Order("id").Find(&distribution.Allocations).Error; err != nil {
+				return fmt.Errorf("failed to get program distribution shares: %w", err)
+			}
+		} else {
+			for i, allocation := range ledger.Allocations {
+				share := models.Distribution{
+					ID:                    fmt.Sprintf("%s-%d", ledger.ID, i+1),
+					DonationID:            allocation.ZakatID,
+					RecipientName:         req.RecipientName,
+					RecipientDetails:      recipientDetails,
+					Amount:                allocation.Amount,
+					DistributionDate:      sql.NullTime{Time: distributedAt, Valid: true},
+					DistributedBy:         sql.NullString{String: distributedBy, Valid: true},
+					ProgramDistributionID: sql.NullString{String: ledger.ID, Valid: true},
+					CreatedAt:             time.Now(),
+				}
+				if err := tx.Create(&share).Error; err != nil {
+					return fmt.Errorf("failed to insert distribution for donation %s: %w", allocation.ZakatID, err)
+				}
+				distribution.Allocations = append(distribution.Allocations, share)
+
+				if err := tx.Model(&models.Donation{}).Where("id = ?", allocation.ZakatID).Updates(map[string]interface{}{
+					"distributed_amount": gorm.Expr("distributed_amount + ?", allocation.Amount),
+					"updated_at":         time.Now(),
+				}).Error; err != nil {
+					return fmt.Errorf("failed to update donation %s: %w", allocation.ZakatID, err)
+				}
+				// Fully allocated donations are distributed, as on the ledger
+				if err := tx.Model(&models.Donation{}).
+					Where("id = ? AND distributed_amount >= amount - 0.005", allocation.ZakatID).
+					Updates(map[string]interface{}{
+						"blockchain_status": "distributed",
+						"distributed_at":    distributedAt,
+						"distributed_by":    distributedBy,
+					}).Error; err != nil {
+					return fmt.Errorf("failed to update donation %s status: %w", allocation.ZakatID, err)
+				}
+			}
+
+			if err := tx.Model(&models.Program{}).Where("id = ?", programID).
+				Update("distributed_amount", gorm.Expr("distributed_amount + ?", ledger.Amount)).Error; err != nil {
+				return fmt.Errorf("failed to update program %s: %w", programID, err)
+			}
+		}
+
+		details, err := json.Marshal(map[string]interface{}{
+			"recipient_name": req.RecipientName,
+			"amount":         ledger.Amount,
+			"allocations":    ledger.Allocations,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal audit details: %w", err)
+		}
+
+		return tx.Create(&models.AuditLog{
+			ID:          uuid.New(),
+			EntityType:  "program",
+			EntityID:    programID,
+			Action:      "distribute",
+			PerformedBy: distributedBy,
+			Details:     string(details),
+			CreatedAt:   time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Program distribution %s recorded across %d donations", ledger.ID, len(ledger.Allocations))
+	return distribution, nil
+}
+
+// GetProgramDistributions retrieves a program's distributions with their allocations, newest first
+func (s *DistributionService) GetProgramDistributions(programID string) ([]*models.ProgramDistribution, error) {
+	var distributions []*models.ProgramDistribution
+	if err := s.db.Where("program_id = ?", programID).Order("distribution_date DESC").Find(&distributions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get program distributions: %w", err)
+	}
+	if len(distributions) == 0 {
+		return distributions, nil
+	}
+
+	ids := make([]string, len(distributions))
+	byID := make(map[string]*models.ProgramDistribution, len(distributions))
+	for i, distribution := range distributions {
+		ids[i] = distribution.ID
+		byID[distribution.ID] = distribution
+	}
+
+	var shares []models.Distribution
+	if err := s.db.Where("program_distribution_id IN ?", ids).Order("id").Find(&shares).Error; err != nil {
+		return nil, fmt.Errorf("failed to get program distribution allocations: %w", err)
+	}
+	for _, share := range shares {
+		distribution := byID[share.ProgramDistributionID.String]
+		distribution.Allocations = append(distribution.Allocations, share)
+	}
+
+	return distributions, nil
+}
+
+// GetDonationDistributions retrieves the distributions a donation paid for, oldest first
+func (s *DistributionService) GetDonationDistributions(donationID string) ([]models.Distribution, error) {
+	var exists int64
+	if err := s.db.Model(&models.Donation{}).Where("id = ?", donationID).Count(&exists).Error; err != nil {
+		return nil, fmt.Errorf("failed to get donation: %w", err)
+	}
+	if exists == 0 {
+		return nil, newNotFoundError("donation %s not found", donationID)
+	}
+
+	distributions := []models.Distribution{}
+	if err := s.db.Where("donation_id = ?", donationID).Order("distribution_date").Find(&distributions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get donation distributions: %w", err)
+	}
+	return distributions, nil
+}
@@ -2,32 +2,52 @@ package services
 
 import (
 "database/sql"
+	"encoding/json"
+	"errors"
 "fmt"
 "log"
+	"math"
+	"sort"
+	"strings"
 "time"
 
 "github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
 "github.com/izzuddinafif/fabric/platform/backend/internal/models"
 "gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrInvalidFitrahDonation prefixes errors for zakat fitrah donations that do not match the published rate
+var ErrInvalidFitrahDonation = errors.New("invalid fitrah donation")
+
+// ErrInvalidDenomination prefixes errors for donations whose currency or commodity cannot be converted to IDR
+var ErrInvalidDenomination = errors.New("invalid denomination")
+
 // DonationService handles donation business logic
 type DonationService struct {
 fabricService     *FabricService
 db                *gorm.DB
 redis             *redis.Client
-validationService *ValidationService
+	paymentService *PaymentService
+	outbox         *OutboxService
 emailService      *EmailService
+	pledgeService  *PledgeService
+	programService *ProgramService
 }
 
-// NewDonationService creates a new donation service
-func NewDonationService(fabricService *FabricService, db *gorm.DB, redis *redis.Client, validationService *ValidationService) *DonationService {
-return &DonationService{
+// NewDonationService creates a new donation service. Ledger submissions go through the outbox,
+// whose donation handlers it registers.
+func NewDonationService(fabricService *FabricService, db *gorm.DB, redis *redis.Client, paymentService *PaymentService, outbox *OutboxService) *DonationService {
+	s := &DonationService{
 fabricService:     fabricService,
 db:                db,
 redis:             redis,
-validationService: validationService,
+		paymentService: paymentService,
+		outbox:         outbox,
 }
+	s.registerOutboxHandlers()
+	return s
 }
 
 // SetEmailService sets the email service for donation notifications
@@ -35,64 +55,300 @@ func (s *DonationService) SetEmailService(emailService *EmailService) {
 s.emailService = emailService
 }
 
-// CreateDonation creates a new donation
-func (s *DonationService) CreateDonation(req models.CreateDonationRequest) (*models.Donation, error) {
-log.Printf("🎯 Creating donation for: %s, Amount: %.2f, Type: %s", req.Name, req.Amount, req.Type)
+// SetPledgeService sets the pledge service used to record fulfilment of pledge donations
+func (s *DonationService) SetPledgeService(pledgeService *PledgeService) {
+	s.pledgeService = pledgeService
+}
 
-// Submit to blockchain first to get the generated ID
-zakatID, err := s.fabricService.AddZakat(
-req.Name,
-req.Phone,
-req.Type,
-req.Amount,
-req.ProgramID,
-req.ReferralCode,
-)
-if err != nil {
-return nil, fmt.Errorf("failed to submit donation to blockchain: %w", err)
+// SetProgramService sets the program service whose cached summaries donation changes invalidate
+func (s *DonationService) SetProgramService(programService *ProgramService) {
+	s.programService = programService
+}
+
+// CreateDonation creates a new donation. A non-empty idempotencyKey is recorded on the ledger, so a
+// retry with the same key and request returns the original donation instead of creating another.
+func (s *DonationService) CreateDonation(req models.CreateDonationRequest, idempotencyKey string) (*models.Donation, error) {
+	requestKey := ""
+	if idempotencyKey != "" {
+		requestKey = "api:" + idempotencyKey
+	}
+	return s.createDonation(req, requestKey, "", "")
+}
+
+// CreatePledgeDonation creates the pending donation for one period of a recurring pledge.
+// The donor is reminded by the pledge service instead of receiving the submission email.
+func (s *DonationService) CreatePledgeDonation(req models.CreateDonationRequest, pledgeID, period string) (*models.Donation, error) {
+	return s.createDonation(req, fmt.Sprintf("pledge:%s:%s", pledgeID, period), pledgeID, period)
 }
 
-	// Create donation record in database
+func (s *DonationService) createDonation(req models.CreateDonationRequest, requestKey, pledgeID, period string) (*models.Donation, error) {
+log.Printf("🎯 Creating donation for: %s, Amount: %.2f, Type: %s", req.Name, req.Amount, req.Type)
+
+	// Anonymous donors are recorded on the ledger under their display alias only;
+	// the real name stays in the database for receipts and emails.
+	muzakki := req.Name
+	displayName := ""
+	if req.Anonymous {
+		displayName = req.DisplayName
+		if displayName == "" {
+			displayName = models.DefaultAnonymousAlias
+		}
+		muzakki = displayName
+	}
+
+	// Most donors pay by QRIS from e-wallets, the first method of every organization
+	organization, paymentMethod, err := resolvePaymentOptions(req.Organization, req.PaymentMethod)
+	if err != nil {
+		return nil, err
+	}
+	req.Organization, req.PaymentMethod = organization, paymentMethod
+
+	// Donations in another currency or in gold/silver are recorded in IDR at the ledger's current rate
+	exchangeRate, err := s.convertToIDR(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Zakat fitrah is checked against the published per-soul rate up front so the
+	// donor gets a clear error instead of a rejected transaction.
+	souls := 0
+	soulNames := sql.NullString{}
+	if req.Type == "fitrah" {
+		if souls, err = s.checkFitrahDonation(req); err != nil {
+			return nil, err
+		}
+		if len(req.SoulNames) > 0 {
+			namesJSON, _ := json.Marshal(req.SoulNames)
+			soulNames = sql.NullString{String: string(namesJSON), Valid: true}
+		}
+	} else if req.Souls != 0 || len(req.SoulNames) > 0 {
+		return nil, fmt.Errorf("%w: souls can only be given for zakat fitrah", ErrInvalidFitrahDonation)
+	}
+
+	// Names of the people paid for stay off the ledger for anonymous donations
+	ledgerSoulNames := req.SoulNames
+	if req.Anonymous {
+		ledgerSoulNames = nil
+	}
+
+	// The ledger records a denomination only for non-IDR donations
+	ledgerDenomination, ledgerOriginalAmount := "", 0.0
+	if req.Denomination != "IDR" {
+		ledgerDenomination, ledgerOriginalAmount = req.Denomination, req.OriginalAmount
+	}
+
+	// A retried request returns the donation it already created
+	if requestKey != "" {
+		if existing, err := s.findByRequestKey(requestKey, req); existing != nil || err != nil {
+			return existing, err
+		}
+	}
+
+	// The zakat ID is generated up front so the donation and its ledger submission can be
+	// recorded together before anything reaches the ledger
+	zakatID := s.fabricService.NewZakatID(req.Organization)
+	ledgerKey := requestKey
+	if ledgerKey == "" {
+		// Lets the outbox resubmit without creating a second zakat
+		ledgerKey = "donation:" + zakatID
+	}
+	submission := ZakatSubmission{
+		ID:           zakatID,
+		ProgramID:    req.ProgramID,
+		Muzakki:      muzakki,
+		Amount:       req.Amount,
+		Type:         req.Type,
+		ReferralCode: req.ReferralCode,
+		Anonymous:    req.Anonymous,
+		Souls:        souls,
+		SoulNames:    ledgerSoulNames,
+
+		PaymentMethod: req.PaymentMethod,
+		Organization:  req.Organization,
+
+		Denomination:   ledgerDenomination,
+		OriginalAmount: ledgerOriginalAmount,
+
+		RequestKey: ledgerKey,
+	}
+
+	// Lets the donor claim the donation once signed in with this phone
+	normalizedPhone, phoneOK := NormalizePhone(req.Phone)
 	donation := &models.Donation{
 		ID:               zakatID,
+		DonorID:          uuid.NullUUID{UUID: req.DonorID, Valid: req.DonorID != uuid.Nil},
 		DonorName:        req.Name,
 		DonorPhone:       req.Phone,
 		DonorEmail:       sql.NullString{String: req.Email, Valid: req.Email != ""},
+		NormalizedPhone:  sql.NullString{String: normalizedPhone, Valid: phoneOK},
+		IsAnonymous:      req.Anonymous,
+		DisplayName:      sql.NullString{String: displayName, Valid: displayName != ""},
 		Amount:           req.Amount,
 		Type:             req.Type,
 		ProgramID:        sql.NullString{String: req.ProgramID, Valid: req.ProgramID != ""},
 		ReferralCode:     sql.NullString{String: req.ReferralCode, Valid: req.ReferralCode != ""},
 		BlockchainStatus: "pending",
-		SyncStatus:       "synced", // Already synced to blockchain
+		SyncStatus:       "pending_sync", // Synced once the outbox has submitted it
+		Organization:     req.Organization,
+		PaymentMethod:    req.PaymentMethod,
+		PledgeID:         sql.NullString{String: pledgeID, Valid: pledgeID != ""},
+		PledgePeriod:     sql.NullString{String: period, Valid: period != ""},
+		Souls:            souls,
+		SoulNames:        soulNames,
+		Denomination:     req.Denomination,
+		OriginalAmount:   req.OriginalAmount,
+		ExchangeRate:     exchangeRate,
+		RequestKey:       sql.NullString{String: requestKey, Valid: requestKey != ""},
 		CreatedAt:        time.Now(),
 		UpdatedAt:        time.Now(),
 	}
 
-// Insert into database using GORM
-if err := s.db.Create(donation).Error; err != nil {
-return nil, fmt.Errorf("failed to insert donation: %w", err)
+	var entry *models.OutboxEntry
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(donation).Error; err != nil {
+			return fmt.Errorf("failed to insert donation: %w", err)
+		}
+		var err error
+		entry, err = s.outbox.Add(tx, "donation", zakatID, outboxCreateDonation, submission)
+		return err
+	})
+	if err != nil {
+		// A concurrent request under the same key may have inserted its donation first
+		if requestKey != "" {
+			if existing, findErr := s.findByRequestKey(requestKey, req); existing != nil || findErr != nil {
+				return existing, findErr
+			}
+		}
+		return nil, err
 }
 
-log.Printf("✅ Donation created successfully: %s", zakatID)
+	log.Printf("📝 Donation %s recorded, submitting to blockchain", zakatID)
 
-// Send submission email
-if s.emailService != nil && req.Email != "" {
-go func() {
-err := s.emailService.SendDonationSubmittedEmail(req.Email, req.Name, zakatID, req.Amount)
-if err != nil {
-log.Printf("❌ Failed to send submission email for donation %s: %v", zakatID, err)
-} else {
-log.Printf("📧 Submission email sent for donation %s", zakatID)
+	if err := s.submit(entry); err != nil {
+		// Only a conflict on the request key means it was used for another donation; the ledger
+		// also reports a taken zakat ID or pledge period as a conflict
+		if requestKey != "" && isRequestKeyConflict(err) {
+			return nil, fmt.Errorf("%w: %v", ErrIdempotencyKeyReused, err)
+		}
+		return nil, fmt.Errorf("failed to submit donation to blockchain: %w", err)
 }
-}()
+
+	// Reloaded for the sync status and payment details recorded once the ledger has it
+	return s.GetDonation(zakatID)
 }
 
-// Schedule auto-validation (mock payment)
-go func() {
-s.validationService.ScheduleAutoValidation(zakatID)
-}()
+// requestKeyField is the field of a CONFLICT raised because a request key was already used for
+// a different submission, on the ledger or when it replays another zakat
+const requestKeyField = "requestKey"
 
-return donation, nil
+// isRequestKeyConflict reports whether err rejects a request key that was already used
+func isRequestKeyConflict(err error) bool {
+	var contractErr *ContractError
+	return errors.As(err, &contractErr) && contractErr.Code == CodeConflict && contractErr.Field == requestKeyField
+}
+
+// findByRequestKey returns the donation created under a request key, or nil when there is none.
+// A donation for a different donor, type or amount means the key was reused for another request.
+func (s *DonationService) findByRequestKey(requestKey string, req models.CreateDonationRequest) (*models.Donation, error) {
+	var existing models.Donation
+	err := s.db.Where("request_key = ?", requestKey).First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up request key %s: %w", requestKey, err)
+	}
+
+	if existing.DonorPhone != req.Phone || existing.Type != req.Type || math.Abs(existing.Amount-req.Amount) > 0.01 {
+		return nil, fmt.Errorf("%w: request key %s already created donation %s", ErrIdempotencyKeyReused, requestKey, existing.ID)
+	}
+
+	log.Printf("🔁 Donation %s already created for request key %s", existing.ID, requestKey)
+	return &existing, nil
+}
+
+// convertToIDR sets the IDR amount of a donation made in another currency or in gold/silver from the
+// exchange rate currently published on the ledger and returns that rate. IDR donations get a rate of 1.
+// The chaincode repeats the conversion and rejects the donation if the rate changed in between.
+func (s *DonationService) convertToIDR(req *models.CreateDonationRequest) (float64, error) {
+	req.Denomination = strings.ToUpper(req.Denomination)
+	if req.Denomination == "" || req.Denomination == "IDR" {
+		req.Denomination = "IDR"
+		if req.Amount == 0 {
+			req.Amount = req.OriginalAmount
+		}
+		req.OriginalAmount = req.Amount
+		return 1, nil
+	}
+
+	if req.Type == "fitrah" {
+		return 0, fmt.Errorf("%w: zakat fitrah must be paid in IDR", ErrInvalidDenomination)
+	}
+	if req.OriginalAmount <= 0 {
+		return 0, fmt.Errorf("%w: original_amount is required for %s donations", ErrInvalidDenomination, req.Denomination)
+	}
+
+	rate, err := s.fabricService.GetExchangeRate(req.Denomination)
+	if err != nil {
+		// Unknown denominations and missing or stale rates are the donor's problem, not ours
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrInvalidInput) || errors.Is(err, ErrInvalidState) {
+			return 0, fmt.Errorf("%w: no current %s exchange rate is available", ErrInvalidDenomination, req.Denomination)
+		}
+		return 0, err
+	}
+
+	amount := math.Round(req.OriginalAmount*rate.RateIDR*100) / 100
+	if req.Amount != 0 && math.Abs(req.Amount-amount) > 0.01 {
+		return 0, fmt.Errorf("%w: amount %.2f does not match %g %s at Rp %.2f (Rp %.2f)",
+			ErrInvalidDenomination, req.Amount, req.OriginalAmount, req.Denomination, rate.RateIDR, amount)
+	}
+	req.Amount = amount
+
+	log.Printf("💱 Converted %g %s to Rp %.2f at %s rate %s", req.OriginalAmount, req.Denomination, amount, rate.Source, rate.EffectiveDate)
+	return rate.RateIDR, nil
+}
+
+// checkFitrahDonation checks a zakat fitrah amount against the current rate for the organization's
+// region and returns the number of souls it pays for, mirroring the chaincode's rules.
+func (s *DonationService) checkFitrahDonation(req models.CreateDonationRequest) (int, error) {
+	region := fitrahRegion(req.Organization)
+	rate, err := s.fabricService.GetFitrahRate(region, 0)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return 0, fmt.Errorf("%w: no fitrah rate has been set for %s this year", ErrInvalidFitrahDonation, region)
+		}
+		return 0, err
+	}
+
+	if len(req.SoulNames) > 0 && req.Souls != 0 && len(req.SoulNames) != req.Souls {
+		return 0, fmt.Errorf("%w: got %d soul names for %d souls", ErrInvalidFitrahDonation, len(req.SoulNames), req.Souls)
+	}
+
+	souls := req.Souls
+	if souls == 0 {
+		souls = len(req.SoulNames)
+	}
+	if souls == 0 {
+		souls = int(math.Round(req.Amount / rate.RatePerSoul))
+	}
+
+	expected := float64(souls) * rate.RatePerSoul
+	if souls == 0 || math.Abs(req.Amount-expected) > 0.01 {
+		return 0, fmt.Errorf("%w: amount must be a multiple of Rp %.0f per soul for %s %dH",
+			ErrInvalidFitrahDonation, rate.RatePerSoul, rate.Region, rate.HijriYear)
+	}
+
+	return souls, nil
+}
+
+// fitrahRegion maps an organization to the region whose fitrah rate applies, as the chaincode does
+func fitrahRegion(organization string) string {
+	switch organization {
+	case "YDSF Jatim":
+		return "JATIM"
+	default:
+		return "MALANG"
+	}
 }
 
 // GetDonation retrieves a donation by ID
@@ -100,17 +356,30 @@ func (s *DonationService) GetDonation(id string) (*models.Donation, error) {
 var donation models.Donation
 if err := s.db.Where("id = ?", id).First(&donation).Error; err != nil {
 if err == gorm.ErrRecordNotFound {
-return nil, fmt.Errorf("donation not found")
+			return nil, newNotFoundError("donation %s not found", id)
 }
 return nil, fmt.Errorf("failed to get donation: %w", err)
 }
 return &donation, nil
 }
 
-// GetDonations retrieves all donations with pagination
-func (s *DonationService) GetDonations(limit, offset int) ([]*models.Donation, error) {
+// DonationFilter narrows a donation listing; empty fields match every donation
+type DonationFilter struct {
+	Organization  string
+	PaymentMethod string
+}
+
+// GetDonations retrieves donations matching filter with pagination
+func (s *DonationService) GetDonations(filter DonationFilter, limit, offset int) ([]*models.Donation, error) {
+	query := s.db.Order("created_at DESC")
+	if filter.Organization != "" {
+		query = query.Where("organization = ?", filter.Organization)
+	}
+	if filter.PaymentMethod != "" {
+		query = query.Where("payment_method = ?", filter.PaymentMethod)
+	}
 var donations []*models.Donation
-if err := s.db.Order("created_at DESC").Limit(limit).Offset(offset).Find(&donations).Error; err != nil {
+	if err := query.Limit(limit).Offset(offset).Find(&donations).Error; err != nil {
 return nil, fmt.Errorf("failed to get donations: %w", err)
 }
 return donations, nil
@@ -125,141 +394,591 @@ return nil, fmt.Errorf("failed to get donations by status: %w", err)
 return donations, nil
 }
 
-// ValidateDonation manually validates a donation (admin action)
-func (s *DonationService) ValidateDonation(donationID, receiptNumber, validatedBy string) error {
+// ValidateDonation manually validates a donation (admin action) and returns the updated donation,
+// including the ID of the validating ledger transaction. When the ledger cannot be reached the
+// donation is returned with sync_status "pending_sync" and the outbox validates it later.
+func (s *DonationService) ValidateDonation(donationID, receiptNumber, validatedBy string) (*models.Donation, error) {
 log.Printf("🔐 Manual validation requested for donation %s by %s", donationID, validatedBy)
 
-// Call fabric service to validate payment
-err := s.fabricService.ValidatePayment(donationID, receiptNumber, validatedBy)
+	entry, err := s.addIntent(donationID, outboxValidateDonation, validateIntent{
+		ReceiptNumber: receiptNumber,
+		ValidatedBy:   validatedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.submit(entry); err != nil {
+		return nil, fmt.Errorf("failed to validate payment on blockchain: %w", err)
+}
+	return s.GetDonation(donationID)
+}
+
+// DistributeDonation distributes a collected donation to a single recipient (admin action).
+// Once the ledger marks the donation distributed, the distribution, the donation's and program's
+// distributed amounts and an audit log entry are written in one DB transaction. When the ledger
+// cannot be reached the donation is returned with sync_status "pending_sync" and the outbox
+// distributes it later.
+func (s *DonationService) DistributeDonation(donationID string, req models.DistributeDonationRequest, distributedBy string) (*models.Donation, error) {
+	log.Printf("🎯 Distribution requested for donation %s to %s", donationID, req.RecipientName)
+
+	recipientDetails := ""
+	if len(req.RecipientDetails) > 0 {
+		detailsJSON, err := json.Marshal(req.RecipientDetails)
 if err != nil {
-return fmt.Errorf("failed to validate payment on blockchain: %w", err)
+			return nil, fmt.Errorf("failed to marshal recipient details: %w", err)
+		}
+		recipientDetails = string(detailsJSON)
 }
 
-// Update database record
-now := time.Now()
-err = s.db.Model(&models.Donation{}).Where("id = ?", donationID).Updates(map[string]interface{}{
-"blockchain_status":  "collected",
-"payment_reference":  models.NullString{String: receiptNumber, Valid: true},
-"validated_by":       models.NullString{String: validatedBy, Valid: true},
-"validated_at":       models.NullTime{Time: now, Valid: true},
-"updated_at":         now,
-}).Error
+	entry, err := s.addIntent(donationID, outboxDistributeDonation, distributeIntent{
+		DistributionID:   s.fabricService.NewDistributionID(),
+		RecipientName:    req.RecipientName,
+		RecipientDetails: recipientDetails,
+		Amount:           req.Amount,
+		DistributedAt:    time.Now().UTC().Truncate(time.Second),
+		DistributedBy:    distributedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-if err != nil {
-log.Printf("❌ Failed to update donation record for %s: %v", donationID, err)
-return fmt.Errorf("failed to update donation record: %w", err)
+	if err := s.submit(entry); err != nil {
+		return nil, fmt.Errorf("failed to distribute zakat on blockchain: %w", err)
+	}
+	return s.GetDonation(donationID)
+}
+
+// Outbox operations of donations
+const (
+	outboxCreateDonation     = "create"
+	outboxValidateDonation   = "validate"
+	outboxDistributeDonation = "distribute"
+	outboxReallocateDonation = "reallocate"
+)
+
+// validateIntent is the outbox payload of a manual validation
+type validateIntent struct {
+	ReceiptNumber string `json:"receipt_number"`
+	ValidatedBy   string `json:"validated_by"`
+}
+
+// distributeIntent is the outbox payload of a distribution. Its ID and time are fixed when the
+// distribution is requested so a resubmission records the same distribution.
+type distributeIntent struct {
+	DistributionID   string    `json:"distribution_id"`
+	RecipientName    string    `json:"recipient_name"`
+	RecipientDetails string    `json:"recipient_details,omitempty"` // JSON
+	Amount           float64   `json:"amount"`
+	DistributedAt    time.Time `json:"distributed_at"`
+	DistributedBy    string    `json:"distributed_by"`
+}
+
+// reallocateIntent is the outbox payload of a reallocation to another program
+type reallocateIntent struct {
+	FromProgramID string `json:"from_program_id"`
+	ProgramID     string `json:"program_id"`
+	Reason        string `json:"reason"`
+	ApprovedBy    string `json:"approved_by"`
 }
 
-log.Printf("✅ Successfully validated donation: %s", donationID)
-return nil
+// registerOutboxHandlers sets how the outbox carries out donation ledger submissions.
+// A rejected create removes the donation; a rejected validation or distribution leaves it as it
+// was, as does a rejected reallocation. A submission that keeps failing marks the donation's sync_status "error".
+func (s *DonationService) registerOutboxHandlers() {
+	s.outbox.Register(outboxCreateDonation, OutboxHandler{
+		Apply: s.applyCreate,
+		Compensate: func(tx *gorm.DB, entry *models.OutboxEntry, cause error) error {
+			log.Printf("🗑️ Removing donation %s rejected by the ledger: %v", entry.AggregateID, cause)
+			return tx.Where("id = ?", entry.AggregateID).Delete(&models.Donation{}).Error
+		},
+		Fail: s.markSyncError,
+		Done: s.created,
+	})
+	s.outbox.Register(outboxValidateDonation, OutboxHandler{
+		Apply:      s.applyValidate,
+		Compensate: s.restoreSynced,
+		Fail:       s.markSyncError,
+		Done: func(entry *models.OutboxEntry) {
+			if s.programService != nil {
+				s.programService.InvalidateDonationProgram(entry.AggregateID)
+			}
+			if s.pledgeService != nil {
+				if err := s.pledgeService.RecordFulfilment(entry.AggregateID); err != nil {
+					log.Printf("❌ Failed to record pledge fulfilment for donation %s: %v", entry.AggregateID, err)
+				}
+			}
+		},
+	})
+	s.outbox.Register(outboxDistributeDonation, OutboxHandler{
+		Apply:      s.applyDistribute,
+		Compensate: s.restoreSynced,
+		Fail:       s.markSyncError,
+		Done: func(entry *models.OutboxEntry) {
+			if s.programService != nil {
+				s.programService.InvalidateDonationProgram(entry.AggregateID)
+			}
+		},
+	})
+	s.outbox.Register(outboxReallocateDonation, OutboxHandler{
+		Apply:      s.applyReallocate,
+		Compensate: s.restoreSynced,
+		Fail:       s.markSyncError,
+		Done: func(entry *models.OutboxEntry) {
+			var intent reallocateIntent
+			if s.programService != nil && json.Unmarshal([]byte(entry.Payload), &intent) == nil {
+				s.programService.InvalidateSummary(intent.FromProgramID, intent.ProgramID)
+			}
+		},
+	})
 }
 
-// DistributeDonation distributes a collected donation
-func (s *DonationService) DistributeDonation(donationID, recipientName string, amount float64, distributedBy string) error {
-log.Printf("🎯 Distribution requested for donation %s to %s", donationID, recipientName)
+// addIntent marks a synced donation "pending_sync" and records a ledger submission for it.
+// Donations with a submission pending, or one that failed, take no further submissions.
+func (s *DonationService) addIntent(donationID, operation string, payload interface{}) (*models.OutboxEntry, error) {
+	donation, err := s.GetDonation(donationID)
+	if err != nil {
+		return nil, err
+}
+
+	var entry *models.OutboxEntry
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Donation{}).Where("id = ? AND sync_status = ?", donationID, "synced").Updates(map[string]interface{}{
+			"sync_status": "pending_sync",
+			"updated_at":  time.Now(),
+		})
+		if result.Error != nil {
+			return fmt.Errorf("failed to update donation sync status: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return newInvalidStateError("donation %s is not in sync with the ledger (sync_status %s)", donationID, donation.SyncStatus)
+		}
+
+		entry, err = s.outbox.Add(tx, "donation", donationID, operation, payload)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// submit carries out a recorded submission right away. It only fails when the ledger rejected
+// the submission; anything else leaves it to the outbox worker.
+func (s *DonationService) submit(entry *models.OutboxEntry) error {
+	err := s.outbox.Process(entry.ID)
+	var contractErr *ContractError
+	if err == nil || errors.As(err, &contractErr) {
+		return err
+	}
+
+	log.Printf("⏳ Ledger %s of donation %s left to the outbox worker: %v", entry.Operation, entry.AggregateID, err)
+	return nil
+}
 
-// Call fabric service to distribute zakat
-err := s.fabricService.DistributeZakat(donationID, recipientName, amount, distributedBy)
+// applyCreate submits a recorded donation to the ledger
+func (s *DonationService) applyCreate(tx *gorm.DB, entry *models.OutboxEntry) error {
+	var submission ZakatSubmission
+	if err := json.Unmarshal([]byte(entry.Payload), &submission); err != nil {
+		return fmt.Errorf("failed to unmarshal zakat submission: %w", err)
+	}
+
+	zakatID, err := s.fabricService.AddZakat(submission)
+	if err != nil {
+		return err
+	}
+	if zakatID != submission.ID {
+		return &ContractError{
+			Code:    CodeConflict,
+			Message: fmt.Sprintf("request key %s was already used for zakat %s", submission.RequestKey, zakatID),
+			Field:   requestKeyField,
+		}
+	}
+
+	return s.markSynced(tx, entry.AggregateID, map[string]interface{}{})
+}
+
+// created runs after a donation reached the ledger: it can now be paid
+func (s *DonationService) created(entry *models.OutboxEntry) {
+	donation, err := s.GetDonation(entry.AggregateID)
 if err != nil {
-return fmt.Errorf("failed to distribute zakat on blockchain: %w", err)
+		log.Printf("❌ Failed to load created donation %s: %v", entry.AggregateID, err)
+		return
+}
+
+	log.Printf("✅ Donation created successfully: %s", donation.ID)
+
+	if s.programService != nil {
+		s.programService.InvalidateSummary(donation.ProgramID.String)
+	}
+
+	// Send submission email; pledge donors are reminded by the pledge service instead
+	if s.emailService != nil && donation.DonorEmail.Valid && !donation.PledgeID.Valid {
+		go func() {
+			err := s.emailService.SendDonationSubmittedEmail(donation.DonorEmail.String, donation.DonorName, donation.ID, donation.Amount)
+			if err != nil {
+				log.Printf("❌ Failed to send submission email for donation %s: %v", donation.ID, err)
+			} else {
+				log.Printf("📧 Submission email sent for donation %s", donation.ID)
+			}
+		}()
+	}
+
+	// Open the payment; the gateway's verified notification validates the donation
+	if s.paymentService != nil {
+		if err := s.paymentService.CreateCharge(donation); err != nil {
+			log.Printf("❌ %v", err)
+		}
+	}
 }
 
-// Update database record
+// applyValidate validates a donation's payment on the ledger
+func (s *DonationService) applyValidate(tx *gorm.DB, entry *models.OutboxEntry) error {
+	var intent validateIntent
+	if err := json.Unmarshal([]byte(entry.Payload), &intent); err != nil {
+		return fmt.Errorf("failed to unmarshal validation: %w", err)
+	}
+
+	txID, err := s.fabricService.ValidatePayment(entry.AggregateID, intent.ReceiptNumber, intent.ValidatedBy)
+	if errors.Is(err, ErrInvalidState) {
+		// An earlier attempt may have been committed before its outcome was saved
+		if zakat, queryErr := s.fabricService.QueryZakat(entry.AggregateID); queryErr == nil &&
+			zakat["status"] != "pending" && zakat["receiptNumber"] == intent.ReceiptNumber {
+			txID, _ = zakat["validationTxID"].(string)
+			err = nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+
 now := time.Now()
-err = s.db.Model(&models.Donation{}).Where("id = ?", donationID).Updates(map[string]interface{}{
+	return s.markSynced(tx, entry.AggregateID, map[string]interface{}{
+		"blockchain_status": "collected",
+		"payment_reference": sql.NullString{String: intent.ReceiptNumber, Valid: true},
+		"validated_by":      sql.NullString{String: intent.ValidatedBy, Valid: true},
+		"validated_at":      sql.NullTime{Time: now, Valid: true},
+		"blockchain_tx_id":  sql.NullString{String: txID, Valid: txID != ""},
+	})
+}
+
+// applyDistribute distributes a donation on the ledger and records the distribution
+func (s *DonationService) applyDistribute(tx *gorm.DB, entry *models.OutboxEntry) error {
+	var intent distributeIntent
+	if err := json.Unmarshal([]byte(entry.Payload), &intent); err != nil {
+		return fmt.Errorf("failed to unmarshal distribution: %w", err)
+	}
+	donationID := entry.AggregateID
+
+	txID, err := s.fabricService.DistributeZakat(donationID, intent.DistributionID, intent.RecipientName,
+		intent.Amount, intent.DistributedAt, intent.DistributedBy)
+	if errors.Is(err, ErrInvalidState) {
+		// An earlier attempt may have been committed before its outcome was saved
+		if zakat, queryErr := s.fabricService.QueryZakat(donationID); queryErr == nil &&
+			zakat["distributionID"] == intent.DistributionID {
+			err = nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	var donation models.Donation
+	if err := tx.Where("id = ?", donationID).First(&donation).Error; err != nil {
+		return fmt.Errorf("failed to get donation %s: %w", donationID, err)
+	}
+
+	now := time.Now()
+	ledgerTxID := sql.NullString{String: txID, Valid: txID != ""}
+	recipientDetails := sql.NullString{String: intent.RecipientDetails, Valid: intent.RecipientDetails != ""}
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.Distribution{
+		ID:               intent.DistributionID,
+		DonationID:       donationID,
+		RecipientName:    intent.RecipientName,
+		RecipientDetails: recipientDetails,
+		Amount:           intent.Amount,
+		DistributionDate: sql.NullTime{Time: intent.DistributedAt, Valid: true},
+		DistributedBy:    sql.NullString{String: intent.DistributedBy, Valid: true},
+		BlockchainTxID:   ledgerTxID,
+		CreatedAt:        now,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to insert distribution: %w", result.Error)
+	}
+
+	updates := map[string]interface{}{
 "blockchain_status": "distributed",
-"distributed_by":    models.NullString{String: distributedBy, Valid: true},
-"distributed_at":    models.NullTime{Time: now, Valid: true},
-"updated_at":        now,
-}).Error
+		"distributed_by":    sql.NullString{String: intent.DistributedBy, Valid: true},
+		"distributed_at":    sql.NullTime{Time: intent.DistributedAt, Valid: true},
+		"blockchain_tx_id":  ledgerTxID,
+	}
+	if result.RowsAffected == 0 {
+		// The block indexer recorded the distribution, and the ledger's totals, first
+		if err := tx.Model(&models.Distribution{}).Where("id = ?", intent.DistributionID).
+			Update("recipient_details", recipientDetails).Error; err != nil {
+			return fmt.Errorf("failed to update distribution: %w", err)
+		}
+	} else {
+		updates["distributed_amount"] = gorm.Expr("distributed_amount + ?", intent.Amount)
+		if donation.ProgramID.Valid && donation.ProgramID.String != "" {
+			if err := tx.Model(&models.Program{}).Where("id = ?", donation.ProgramID.String).
+				Update("distributed_amount", gorm.Expr("distributed_amount + ?", intent.Amount)).Error; err != nil {
+				return fmt.Errorf("failed to update program %s: %w", donation.ProgramID.String, err)
+			}
+		}
+	}
+	if err := s.markSynced(tx, donationID, updates); err != nil {
+		return err
+	}
 
+	details, err := json.Marshal(map[string]interface{}{
+		"distribution_id": intent.DistributionID,
+		"recipient_name":  intent.RecipientName,
+		"amount":          intent.Amount,
+		"tx_id":           txID,
+	})
 if err != nil {
-log.Printf("❌ Failed to update donation record for distribution %s: %v", donationID, err)
-return fmt.Errorf("failed to update donation record: %w", err)
+		return fmt.Errorf("failed to marshal audit details: %w", err)
+	}
+
+	return tx.Create(&models.AuditLog{
+		ID:          uuid.New(),
+		EntityType:  "donation",
+		EntityID:    donationID,
+		Action:      "distribute",
+		PerformedBy: intent.DistributedBy,
+		Details:     string(details),
+		CreatedAt:   now,
+	}).Error
 }
 
-log.Printf("✅ Successfully distributed donation: %s", donationID)
-return nil
+// markSynced applies the ledger's outcome to a donation and marks it synced
+func (s *DonationService) markSynced(tx *gorm.DB, donationID string, updates map[string]interface{}) error {
+	updates["sync_status"] = "synced"
+	updates["updated_at"] = time.Now()
+	if err := tx.Model(&models.Donation{}).Where("id = ?", donationID).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to update donation %s: %w", donationID, err)
+	}
+	return nil
 }
 
-// GetDashboardMetrics gets metrics for admin dashboard
+// restoreSynced returns a donation whose submission the ledger rejected to its synced state
+func (s *DonationService) restoreSynced(tx *gorm.DB, entry *models.OutboxEntry, cause error) error {
+	return s.markSynced(tx, entry.AggregateID, map[string]interface{}{})
+}
+
+// markSyncError flags a donation whose submission ran out of attempts
+func (s *DonationService) markSyncError(tx *gorm.DB, entry *models.OutboxEntry) error {
+	return tx.Model(&models.Donation{}).Where("id = ?", entry.AggregateID).Updates(map[string]interface{}{
+		"sync_status": "error",
+		"updated_at":  time.Now(),
+	}).Error
+}
+
+// ReallocateDonation moves an undistributed donation to another program (admin action).
+// Once the ledger moves the zakat and adjusts program totals, the donation's program, both
+// programs' collected amounts and an audit log entry with the reason and approver are written
+// in one DB transaction. When the ledger cannot be reached the donation is returned with
+// sync_status "pending_sync" and the outbox reallocates it later.
+func (s *DonationService) ReallocateDonation(donationID, newProgramID, reason, approvedBy string) (*models.Donation, error) {
+	log.Printf("🔀 Reallocation requested for donation %s to program %s by %s", donationID, newProgramID, approvedBy)
+
+	donation, err := s.GetDonation(donationID)
+	if err != nil {
+		return nil, err
+	}
+	if donation.ProgramID.String == newProgramID {
+		return nil, newInvalidStateError("donation %s already belongs to program %s", donationID, newProgramID)
+	}
+
+	entry, err := s.addIntent(donationID, outboxReallocateDonation, reallocateIntent{
+		FromProgramID: donation.ProgramID.String,
+		ProgramID:     newProgramID,
+		Reason:        reason,
+		ApprovedBy:    approvedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.submit(entry); err != nil {
+		return nil, fmt.Errorf("failed to reallocate zakat on blockchain: %w", err)
+	}
+	return s.GetDonation(donationID)
+}
+
+// applyReallocate moves a donation to another program on the ledger and in the database
+func (s *DonationService) applyReallocate(tx *gorm.DB, entry *models.OutboxEntry) error {
+	var intent reallocateIntent
+	if err := json.Unmarshal([]byte(entry.Payload), &intent); err != nil {
+		return fmt.Errorf("failed to unmarshal reallocation: %w", err)
+	}
+	donationID := entry.AggregateID
+
+	err := s.fabricService.ReallocateZakat(donationID, intent.ProgramID, intent.Reason, intent.ApprovedBy)
+	if errors.Is(err, ErrInvalidState) {
+		// An earlier attempt may have been committed before its outcome was saved
+		if zakat, queryErr := s.fabricService.QueryZakat(donationID); queryErr == nil &&
+			zakat["programID"] == intent.ProgramID {
+			err = nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	var donation models.Donation
+	if err := tx.Where("id = ?", donationID).First(&donation).Error; err != nil {
+		return fmt.Errorf("failed to get donation %s: %w", donationID, err)
+	}
+
+	// The block indexer may already have moved the donation, with the ledger's program totals
+	now := time.Now()
+	result := tx.Model(&models.Donation{}).Where("id = ? AND program_id IS DISTINCT FROM ?", donationID, intent.ProgramID).
+		Updates(map[string]interface{}{
+			"program_id": intent.ProgramID,
+			"updated_at": now,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update donation program: %w", result.Error)
+	}
+
+	// Only collected donations count towards program totals
+	if donation.BlockchainStatus == "collected" && result.RowsAffected > 0 {
+		if donation.ProgramID.Valid && donation.ProgramID.String != "" {
+			if err := tx.Model(&models.Program{}).Where("id = ?", donation.ProgramID.String).
+				Update("collected_amount", gorm.Expr("collected_amount - ?", donation.Amount)).Error; err != nil {
+				return fmt.Errorf("failed to update program %s: %w", donation.ProgramID.String, err)
+			}
+		}
+		if err := tx.Model(&models.Program{}).Where("id = ?", intent.ProgramID).
+			Update("collected_amount", gorm.Expr("collected_amount + ?", donation.Amount)).Error; err != nil {
+			return fmt.Errorf("failed to update program %s: %w", intent.ProgramID, err)
+		}
+	}
+	if err := s.markSynced(tx, donationID, map[string]interface{}{}); err != nil {
+		return err
+	}
+
+	details, err := json.Marshal(map[string]interface{}{
+		"from_program_id": intent.FromProgramID,
+		"to_program_id":   intent.ProgramID,
+		"reason":          intent.Reason,
+		"amount":          donation.Amount,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit details: %w", err)
+	}
+
+	return tx.Create(&models.AuditLog{
+		ID:          uuid.New(),
+		EntityType:  "donation",
+		EntityID:    donationID,
+		Action:      "reallocate",
+		PerformedBy: intent.ApprovedBy,
+		Details:     string(details),
+		CreatedAt:   now,
+	}).Error
+}
+
+// GetDashboardMetrics gets the Postgres metrics of the admin dashboard. Network health and
+// block height come from the ledger, see DashboardService.
 func (s *DonationService) GetDashboardMetrics() (*models.DashboardMetrics, error) {
 metrics := &models.DashboardMetrics{}
+	collected := []string{"collected", "distributed"}
 
 // Count pending donations
 var pendingCount int64
-s.db.Model(&models.Donation{}).Where("blockchain_status = ?", "pending").Count(&pendingCount)
+	if err := s.db.Model(&models.Donation{}).Where("blockchain_status = ?", "pending").Count(&pendingCount).Error; err != nil {
+		return nil, fmt.Errorf("failed to count pending donations: %w", err)
+	}
 metrics.PendingDonations = int(pendingCount)
 
-// Calculate today's collection
-today := time.Now().Truncate(24 * time.Hour)
-var todaySum struct {
-Total float64
-}
-s.db.Model(&models.Donation{}).
-Where("blockchain_status = ? AND created_at >= ?", "collected", today).
-Select("COALESCE(SUM(amount), 0) as total").
-Scan(&todaySum)
-metrics.TodaysCollection = todaySum.Total
-
-// Calculate total collected
-var totalSum struct {
-Total float64
-}
-s.db.Model(&models.Donation{}).
-Where("blockchain_status IN ?", []string{"collected", "distributed"}).
-Select("COALESCE(SUM(amount), 0) as total").
-Scan(&totalSum)
-metrics.TotalCollected = totalSum.Total
-
-// Calculate total distributed
-var distributedSum struct {
-Total float64
-}
-s.db.Model(&models.Donation{}).
-Where("blockchain_status = ?", "distributed").
-Select("COALESCE(SUM(amount), 0) as total").
-Scan(&distributedSum)
-metrics.TotalDistributed = distributedSum.Total
-
-// Set network health (simplified for MVP)
-metrics.NetworkHealth = "healthy"
-metrics.ChaincodeInstantiated = true
-metrics.BlockchainHeight = uint64(time.Now().Unix()) // Mock value
+	// Sum the donations validated since local midnight, and all collected and distributed funds
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var totals struct {
+		Today       float64
+		Collected   float64
+		Distributed float64
+	}
+	err := s.db.Model(&models.Donation{}).
+		Where("blockchain_status IN ?", collected).
+		Select("COALESCE(SUM(amount) FILTER (WHERE validated_at >= ?), 0) as today, "+
+			"COALESCE(SUM(amount), 0) as collected, "+
+			"COALESCE(SUM(distributed_amount), 0) as distributed", today).
+		Scan(&totals).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum donations: %w", err)
+	}
+	metrics.TodaysCollection = totals.Today
+	metrics.TotalCollected = totals.Collected
+	metrics.TotalDistributed = totals.Distributed
+
+	// Collected totals per denomination; all other totals are in IDR
+	err = s.db.Model(&models.Donation{}).
+		Where("blockchain_status IN ?", collected).
+		Select("denomination, COALESCE(SUM(original_amount), 0) as original_amount, COALESCE(SUM(amount), 0) as amount_idr, COUNT(*) as count").
+		Group("denomination").
+		Order("amount_idr DESC").
+		Scan(&metrics.CollectedByDenomination).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum donations by denomination: %w", err)
+	}
 
 return metrics, nil
 }
 
-// GetRecentActivity gets recent activity for admin dashboard
+// GetRecentActivity gets the latest limit donations, validations and distributions for the
+// admin dashboard, newest first, each at the time it happened
 func (s *DonationService) GetRecentActivity(limit int) ([]models.RecentActivity, error) {
-var donations []models.Donation
-if err := s.db.Order("created_at DESC").Limit(limit).Find(&donations).Error; err != nil {
+	var activities []models.RecentActivity
+
+	var created []models.Donation
+	if err := s.db.Order("created_at DESC").Limit(limit).Find(&created).Error; err != nil {
 return nil, fmt.Errorf("failed to get recent donations: %w", err)
 }
+	for _, donation := range created {
+		activities = append(activities, models.RecentActivity{
+			Type:      "donation",
+			Message:   fmt.Sprintf("New donation from %s: Rp %.2f (%s)", donation.DonorName, donation.Amount, donation.Type),
+			Timestamp: donation.CreatedAt,
+		})
+	}
 
-var activities []models.RecentActivity
-for _, donation := range donations {
-var activityType, message string
-switch donation.BlockchainStatus {
-case "pending":
-activityType = "donation"
-message = fmt.Sprintf("New donation from %s: Rp %.2f (%s)", donation.DonorName, donation.Amount, donation.Type)
-case "collected":
-activityType = "validation"
-message = fmt.Sprintf("Payment validated for %s: Rp %.2f", donation.DonorName, donation.Amount)
-case "distributed":
-activityType = "distribution"
-message = fmt.Sprintf("Zakat distributed for %s: Rp %.2f", donation.DonorName, donation.Amount)
-default:
-continue
+	var validated []models.Donation
+	if err := s.db.Where("validated_at IS NOT NULL").Order("validated_at DESC").Limit(limit).Find(&validated).Error; err != nil {
+		return nil, fmt.Errorf("failed to get recent validations: %w", err)
+	}
+	for _, donation := range validated {
+		activities = append(activities, models.RecentActivity{
+			Type:      "validation",
+			Message:   fmt.Sprintf("Payment validated for %s: Rp %.2f", donation.DonorName, donation.Amount),
+			Timestamp: donation.ValidatedAt.Time,
+		})
 }
 
+	var distributions []models.Distribution
+	if err := s.db.Order("created_at DESC").Limit(limit).Find(&distributions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get recent distributions: %w", err)
+	}
+	for _, distribution := range distributions {
+		timestamp := distribution.CreatedAt
+		if distribution.DistributionDate.Valid {
+			timestamp = distribution.DistributionDate.Time
+		}
 activities = append(activities, models.RecentActivity{
-Type:      activityType,
-Message:   message,
-Timestamp: donation.CreatedAt,
+			Type:      "distribution",
+			Message:   fmt.Sprintf("Zakat from %s distributed to %s: Rp %.2f", distribution.DonationID, distribution.RecipientName, distribution.Amount),
+			Timestamp: timestamp,
 })
 }
 
+	sort.SliceStable(activities, func(i, j int) bool {
+		return activities[i].Timestamp.After(activities[j].Timestamp)
+	})
+	if len(activities) > limit {
+		activities = activities[:limit]
+	}
 return activities, nil
 }
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRequestKeyConflict(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "RequestKeyReused",
+			err:      fmt.Errorf("failed to submit zakat: %w", &ContractError{Code: CodeConflict, Message: "request key k1 was already used for zakat ZKT-YDSF-MLG-202503-000001 with a different submission", Field: requestKeyField}),
+			expected: true,
+		},
+		{name: "ZakatIDTaken", err: newConflictError("zakat ZKT-YDSF-MLG-202503-000001 already exists")},
+		{name: "PledgePeriodFulfilled", err: newConflictError("period 2025-03 of pledge PLG-YDSF-MLG-1735689000000000000-0001 is already fulfilled by zakat ZKT-YDSF-MLG-202503-000001")},
+		{name: "InvalidRequestKey", err: newInvalidInputError(requestKeyField, "invalid request key")},
+		{name: "NotAContractError", err: errors.New("connection refused")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, isRequestKeyConflict(tc.err))
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// maxClaimable caps the guest donations listed as claimable
+const maxClaimable = 100
+
+// DonorService serves signed-in donors their own donations. A donation is a donor's when it was
+// made while signed in or claimed since. Guest donations made with the donor's phone number or
+// email address, verified when they signed in with a one-time code, can be claimed.
+type DonorService struct {
+	db            *gorm.DB
+	fabricService *FabricService
+	userService   *UserService
+}
+
+// NewDonorService creates a new donor service
+func NewDonorService(fabricService *FabricService, db *gorm.DB, userService *UserService) *DonorService {
+	return &DonorService{
+		db:            db,
+		fabricService: fabricService,
+		userService:   userService,
+	}
+}
+
+// ListDonations returns a page of a donor's donations, newest first, together with the guest
+// donations they can claim and what they paid per year
+func (s *DonorService) ListDonations(donorID uuid.UUID, limit, offset int) (*models.DonorDonationsResponse, error) {
+	donor, err := s.donor(donorID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.DonorDonationsResponse{
+		Donations:    []*models.Donation{},
+		Claimable:    []*models.Donation{},
+		YearlyTotals: []models.DonorYearTotal{},
+	}
+	if err := s.db.Model(&models.Donation{}).Where("donor_id = ?", donorID).Count(&response.Total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count donor donations: %w", err)
+	}
+	if err := s.db.Where("donor_id = ?", donorID).Order("created_at DESC").Limit(limit).Offset(offset).Find(&response.Donations).Error; err != nil {
+		return nil, fmt.Errorf("failed to get donor donations: %w", err)
+	}
+
+	if condition, args := claimableCondition(donor); condition != "" {
+		err := s.db.Where(condition, args...).Order("created_at DESC").Limit(maxClaimable).Find(&response.Claimable).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to get claimable donations: %w", err)
+		}
+	}
+
+	// Only paid donations count, in the year their payment was validated
+	err = s.db.Raw(`
+SELECT EXTRACT(YEAR FROM COALESCE(validated_at, created_at) AT TIME ZONE 'Asia/Jakarta')::int AS year,
+    COUNT(*) AS donations,
+    SUM(amount) AS amount,
+    SUM(CASE WHEN type = 'maal' THEN amount ELSE 0 END) AS maal,
+    SUM(CASE WHEN type = 'fitrah' THEN amount ELSE 0 END) AS fitrah
+FROM donations
+WHERE donor_id = ? AND blockchain_status IN ('collected', 'distributed')
+GROUP BY 1
+ORDER BY 1 DESC`, donorID).Scan(&response.YearlyTotals).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get donor yearly totals: %w", err)
+	}
+
+	return response, nil
+}
+
+// OwnedDonation returns one of a donor's donations. Donations of other donors are not found.
+func (s *DonorService) OwnedDonation(donorID uuid.UUID, donationID string) (*models.Donation, error) {
+	var donation models.Donation
+	err := s.db.Where("id = ? AND donor_id = ?", donationID, donorID).First(&donation).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, newNotFoundError("donation %s not found", donationID)
+		}
+		return nil, fmt.Errorf("failed to get donation: %w", err)
+	}
+	return &donation, nil
+}
+
+// GetDonation returns one of a donor's donations with its status read live from the ledger
+func (s *DonorService) GetDonation(donorID uuid.UUID, donationID string) (*models.DonorDonationResponse, error) {
+	donation, err := s.OwnedDonation(donorID, donationID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.DonorDonationResponse{Donation: *donation}
+	// A donation still waiting for the outbox is not on the ledger yet
+	if zakat, err := s.fabricService.QueryZakat(donationID); err == nil {
+		response.LedgerStatus = ledgerString(zakat, "status")
+	} else if donation.SyncStatus == "synced" {
+		log.Printf("❌ Failed to get ledger status of donation %s: %v", donationID, err)
+	}
+	response.ReceiptAvailable = response.LedgerStatus == "collected" || response.LedgerStatus == "distributed"
+	return response, nil
+}
+
+// ClaimDonations adds guest donations made with the donor's phone number or email address to
+// their account and returns the IDs claimed. Without donationIDs every claimable donation is
+// claimed; requested donations that are not claimable are left as they are.
+func (s *DonorService) ClaimDonations(donorID uuid.UUID, donationIDs []string) ([]string, error) {
+	donor, err := s.donor(donorID)
+	if err != nil {
+		return nil, err
+	}
+	claimed := []string{}
+	condition, args := claimableCondition(donor)
+	if condition == "" {
+		return claimed, nil
+	}
+	if len(donationIDs) > 0 {
+		condition += " AND id IN ?"
+		args = append(args, donationIDs)
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Raw("UPDATE donations SET donor_id = ?, updated_at = ? WHERE "+condition+" RETURNING id",
+			append([]interface{}{donorID, now}, args...)...).Scan(&claimed).Error
+		if err != nil {
+			return fmt.Errorf("failed to claim donations: %w", err)
+		}
+
+		for _, id := range claimed {
+			details, err := json.Marshal(map[string]interface{}{"donor_id": donorID})
+			if err != nil {
+				return fmt.Errorf("failed to marshal audit details: %w", err)
+			}
+			err = tx.Create(&models.AuditLog{
+				ID:          uuid.New(),
+				EntityType:  "donation",
+				EntityID:    id,
+				Action:      "claim",
+				PerformedBy: donorID.String(),
+				Details:     string(details),
+				CreatedAt:   now,
+			}).Error
+			if err != nil {
+				return fmt.Errorf("failed to record claim: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(claimed) > 0 {
+		log.Printf("🙋 Donor %s claimed %d donation(s)", donorID, len(claimed))
+	}
+	return claimed, nil
+}
+
+// donor returns the donor account with an ID
+func (s *DonorService) donor(donorID uuid.UUID) (*models.User, error) {
+	donor, err := s.userService.GetUserByID(donorID)
+	if err != nil {
+		return nil, err
+	}
+	if donor.Role != "donor" {
+		return nil, newUnauthorizedError("only donor accounts have donations")
+	}
+	return donor, nil
+}
+
+// claimableCondition selects the guest donations made with a donor's phone number or email
+// address; it is empty when the donor has neither
+func claimableCondition(donor *models.User) (string, []interface{}) {
+	var matches []string
+	var args []interface{}
+	if phone, ok := NormalizePhone(donor.Phone.String); donor.Phone.Valid && ok {
+		matches = append(matches, "donor_phone_normalized = ?")
+		args = append(args, phone)
+	}
+	if donor.Email.Valid && donor.Email.String != "" {
+		matches = append(matches, "LOWER(donor_email) = ?")
+		args = append(args, strings.ToLower(donor.Email.String))
+	}
+	if len(matches) == 0 {
+		return "", nil
+	}
+	return "donor_id IS NULL AND (" + strings.Join(matches, " OR ") + ")", args
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"crypto/tls"
 	"fmt"
+	"time"
 
 	"github.com/izzuddinafif/fabric/platform/backend/internal/config"
 	"gopkg.in/gomail.v2"
@@ -20,9 +21,14 @@ func NewEmailService(cfg config.EmailConfig) *EmailService {
 	}
 }
 
+// Configured reports whether SMTP credentials are set; without them emails are skipped
+func (s *EmailService) Configured() bool {
+	return s.config.Username != "" && s.config.Password != ""
+}
+
 // SendEmail sends an email
 func (s *EmailService) SendEmail(to, subject, body string) error {
-	if s.config.Username == "" || s.config.Password == "" {
+	if !s.Configured() {
 		// Email not configured, skip sending
 		return nil
 	}
@@ -84,3 +90,60 @@ YDSF Platform
 
 	return s.SendEmail(donorEmail, subject, body)
 }
+
+// SendPledgeCreatedEmail sends confirmation when a recurring pledge is registered
+func (s *EmailService) SendPledgeCreatedEmail(donorEmail, donorName, pledgeID string, amount float64, schedule string, firstDue time.Time) error {
+	subject := "Komitmen Zakat Rutin Anda Telah Tercatat"
+	body := fmt.Sprintf(`
+Assalamu'alaikum %s,
+
+Terima kasih atas komitmen zakat rutin Anda.
+Detail komitmen:
+- ID Komitmen: %s
+- Jumlah per periode: Rp %.2f
+- Jadwal: %s
+- Jatuh tempo pertama: %s
+
+Kami akan mengirimkan pengingat setiap kali zakat Anda jatuh tempo.
+
+Barakallahu fiikum,
+YDSF Platform
+`, donorName, pledgeID, amount, pledgeScheduleLabel(schedule), firstDue.Format("02 January 2006"))
+
+	return s.SendEmail(donorEmail, subject, body)
+}
+
+// SendPledgeReminderEmail reminds the donor that a pledged payment is due
+func (s *EmailService) SendPledgeReminderEmail(donorEmail, donorName, pledgeID, period, donationID string, amount float64) error {
+	subject := "Pengingat Zakat Rutin " + period
+	body := fmt.Sprintf(`
+Assalamu'alaikum %s,
+
+Zakat rutin Anda untuk periode %s telah jatuh tempo.
+Detail pembayaran:
+- ID Komitmen: %s
+- ID Donasi: %s
+- Jumlah: Rp %.2f
+- Status: Menunggu Pembayaran
+
+Silakan selesaikan pembayaran menggunakan ID donasi di atas.
+
+Barakallahu fiikum,
+YDSF Platform
+`, donorName, period, pledgeID, donationID, amount)
+
+	return s.SendEmail(donorEmail, subject, body)
+}
+
+// pledgeScheduleLabel returns the Indonesian label for a pledge schedule
+func pledgeScheduleLabel(schedule string) string {
+	switch schedule {
+	case "monthly":
+		return "Bulanan"
+	case "yearly":
+		return "Tahunan"
+	case "ramadan":
+		return "Setiap Ramadan"
+	}
+	return schedule
+}
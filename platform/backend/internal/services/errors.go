@@ -0,0 +1,93 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Error codes shared with the chaincode's error envelope
+const (
+	CodeNotFound     = "NOT_FOUND"
+	CodeInvalidInput = "INVALID_INPUT"
+	CodeInvalidState = "INVALID_STATE"
+	CodeConflict     = "CONFLICT"
+	CodeUnauthorized = "UNAUTHORIZED"
+)
+
+// Sentinels matched by ContractError codes, for use with errors.Is
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrInvalidInput = errors.New("invalid input")
+	ErrInvalidState = errors.New("invalid state")
+	ErrConflict     = errors.New("conflict")
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+var contractErrorCodes = map[error]string{
+	ErrNotFound:     CodeNotFound,
+	ErrInvalidInput: CodeInvalidInput,
+	ErrInvalidState: CodeInvalidState,
+	ErrConflict:     CodeConflict,
+	ErrUnauthorized: CodeUnauthorized,
+}
+
+// ContractError is a typed failure reported by the chaincode, or raised by a service
+// for the same class of problem (e.g. a donation missing from the database)
+type ContractError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"` // Argument or JSON field that failed validation
+}
+
+func (e *ContractError) Error() string {
+	return e.Message
+}
+
+// Is reports whether target is the sentinel for the error's code
+func (e *ContractError) Is(target error) bool {
+	code, ok := contractErrorCodes[target]
+	return ok && code == e.Code
+}
+
+func newNotFoundError(format string, args ...interface{}) error {
+	return &ContractError{Code: CodeNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+func newInvalidInputError(field, format string, args ...interface{}) error {
+	return &ContractError{Code: CodeInvalidInput, Message: fmt.Sprintf(format, args...), Field: field}
+}
+
+func newInvalidStateError(format string, args ...interface{}) error {
+	return &ContractError{Code: CodeInvalidState, Message: fmt.Sprintf(format, args...)}
+}
+
+func newConflictError(format string, args ...interface{}) error {
+	return &ContractError{Code: CodeConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+func newUnauthorizedError(format string, args ...interface{}) error {
+	return &ContractError{Code: CodeUnauthorized, Message: fmt.Sprintf(format, args...)}
+}
+
+// parseContractError extracts the chaincode's {"code","message","field"} envelope from a
+// gateway error. Errors without one (network, endorsement or timeout failures) are returned as is.
+func parseContractError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	message := err.Error()
+	start := strings.Index(message, `{"code"`)
+	if start < 0 {
+		return err
+	}
+
+	var contractErr ContractError
+	decodeErr := json.NewDecoder(strings.NewReader(message[start:])).Decode(&contractErr)
+	if decodeErr != nil || contractErr.Code == "" {
+		return err
+	}
+	return &contractErr
+}
@@ -4,6 +4,7 @@ import (
 "encoding/json"
 "fmt"
 "log"
+	"strconv"
 "time"
 
 "github.com/hyperledger/fabric-sdk-go/pkg/gateway"
@@ -13,6 +14,7 @@ import (
 type FabricService struct {
 contract      *gateway.Contract
 idGenerator   *IDGeneratorService
+	zakatSequence *ZakatSequence
 }
 
 // NewFabricService creates a new Fabric service
@@ -23,41 +25,203 @@ idGenerator: NewIDGeneratorService(),
 }
 }
 
-// AddZakat creates a new zakat donation in the blockchain
-// Maps backend parameters to chaincode signature: AddZakat(id, programID, muzakki, amount, zakatType, paymentMethod, organization, referralCode)
-func (f *FabricService) AddZakat(donorName, donorPhone, zakatType string, amount float64, programID, referralCode string) (string, error) {
-// Generate unique zakat ID
-zakatID := f.idGenerator.GenerateZakatID("YDSF Malang", 1) // Default to Malang for MVP
+// SetZakatSequence sets where zakat numbers are allocated from. Without it, or while it fails,
+// zakat get timestamped IDs.
+func (f *FabricService) SetZakatSequence(zakatSequence *ZakatSequence) {
+	f.zakatSequence = zakatSequence
+}
 
-// Map zakat type to payment method (simplified for MVP)
-paymentMethod := "transfer" // Default payment method for MVP
-organization := "YDSF Malang" // Default organization for MVP
+// submitTransaction submits a transaction like Contract.SubmitTransaction, also returning the
+// ID of the transaction once it is committed
+func (f *FabricService) submitTransaction(name string, args ...string) ([]byte, string, error) {
+	txn, err := f.contract.CreateTransaction(name)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create %s transaction: %w", name, err)
+	}
+	commit := txn.RegisterCommitEvent()
+
+	result, err := txn.Submit(args...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Submit only returns after the commit event has been queued
+	txID := ""
+	select {
+	case event, ok := <-commit:
+		if ok && event != nil {
+			txID = event.TxID
+		}
+	default:
+	}
+	return result, txID, nil
+}
 
-// Convert amount to string
-amountStr := fmt.Sprintf("%.2f", amount)
+// ZakatSubmission mirrors the chaincode's SubmitZakat input
+type ZakatSubmission struct {
+	ID            string   `json:"ID"`
+	ProgramID     string   `json:"programID,omitempty"`
+	Muzakki       string   `json:"muzakki,omitempty"` // Donor name, or display alias when Anonymous is set
+	Amount        float64  `json:"amount"`
+	Type          string   `json:"type"`
+	PaymentMethod string   `json:"paymentMethod"`
+	Organization  string   `json:"organization"`
+	ReferralCode  string   `json:"referralCode,omitempty"`
+	Anonymous     bool     `json:"anonymous,omitempty"`
+	Souls         int      `json:"souls,omitempty"`     // Zakat fitrah only
+	SoulNames     []string `json:"soulNames,omitempty"` // Zakat fitrah only; never sent for anonymous donations
+
+	Denomination   string  `json:"denomination,omitempty"`   // Empty for IDR
+	OriginalAmount float64 `json:"originalAmount,omitempty"` // Amount in Denomination units
+
+	RequestKey string `json:"requestKey,omitempty"` // Replays with the same key return the original zakat
+}
+
+// ProgramDistribution mirrors the chaincode's distribution from a program's pooled funds
+type ProgramDistribution struct {
+	ID            string                   `json:"ID"`
+	ProgramID     string                   `json:"programID"`
+	Mustahik      string                   `json:"mustahik"`
+	Amount        float64                  `json:"amount"`
+	DistributedAt string                   `json:"distributedAt"`
+	DistributedBy string                   `json:"distributedBy"`
+	Allocations   []DistributionAllocation `json:"allocations"` // Oldest collected zakat first
+}
 
-// Prepare arguments for chaincode in correct order
-args := []string{
-zakatID,          // id
-programID,        // programID (can be empty)
-donorName,        // muzakki
-amountStr,        // amount
-zakatType,        // zakatType (fitrah/maal)
-paymentMethod,    // paymentMethod
-organization,     // organization
-referralCode,     // referralCode (can be empty)
+// ProgramSummary mirrors the chaincode's campaign progress of a program
+type ProgramSummary struct {
+	ProgramID         string            `json:"programID"`
+	Name              string            `json:"name"`
+	Status            string            `json:"status"`
+	Target            float64           `json:"target"`
+	Collected         float64           `json:"collected"`
+	Distributed       float64           `json:"distributed"`
+	StartDate         string            `json:"startDate"`
+	EndDate           string            `json:"endDate"`
+	ProgressPercent   float64           `json:"progressPercent"`
+	DistributionRatio float64           `json:"distributionRatio"` // Share of Collected already distributed (0-1)
+	DaysRemaining     int               `json:"daysRemaining"`
+	DonorCount        int               `json:"donorCount"`
+	DonationCount     int               `json:"donationCount"`
+	CountByType       map[string]int    `json:"countByType"`
+	PendingCount      int               `json:"pendingCount"`
+	DailyCollections  []DailyCollection `json:"dailyCollections"` // Oldest first
+	AsOf              string            `json:"asOf"`
 }
 
-log.Printf("🔗 Calling AddZakat chaincode with args: %v", args)
+// DailyCollection mirrors one day of a program summary's collection series
+type DailyCollection struct {
+	Date   string  `json:"date"`
+	Amount float64 `json:"amount"`
+	Count  int     `json:"count"`
+}
+
+// DistributionAllocation mirrors the share of a program distribution drawn from one zakat
+type DistributionAllocation struct {
+	ZakatID string  `json:"zakatID"`
+	Amount  float64 `json:"amount"`
+}
+
+// OfficerPerformance mirrors the chaincode's referral performance of an officer for one organization and period
+type OfficerPerformance struct {
+	OfficerID       string  `json:"officerID"`
+	Name            string  `json:"name"`
+	ReferralCode    string  `json:"referralCode"`
+	Organization    string  `json:"organization"`
+	StartDate       string  `json:"startDate"`
+	EndDate         string  `json:"endDate"`
+	DonationCount   int     `json:"donationCount"`
+	CollectedCount  int     `json:"collectedCount"`
+	PendingCount    int     `json:"pendingCount"`
+	AmountReferred  float64 `json:"amountReferred"`
+	AmountCollected float64 `json:"amountCollected"`
+	ConversionRate  float64 `json:"conversionRate"` // CollectedCount / DonationCount (0-1)
+	AverageTicket   float64 `json:"averageTicket"`
+	Rank            int     `json:"rank"` // 0 when the officer has no referrals in the period
+	RankedOfficers  int     `json:"rankedOfficers"`
+}
+
+// Officer mirrors the chaincode's officer record
+type Officer struct {
+	ID             string  `json:"ID"`
+	Name           string  `json:"name"`
+	ReferralCode   string  `json:"referralCode"`
+	TotalReferred  float64 `json:"totalReferred"`
+	CommissionRate float64 `json:"commissionRate"`
+	Status         string  `json:"status"`
+}
+
+// ReceiptVerification mirrors the chaincode's result of checking a receipt hash against the ledger
+type ReceiptVerification struct {
+	ZakatID        string  `json:"zakatID"`
+	Valid          bool    `json:"valid"`
+	Reason         string  `json:"reason,omitempty"`
+	Status         string  `json:"status,omitempty"`
+	Amount         float64 `json:"amount,omitempty"`
+	Organization   string  `json:"organization,omitempty"`
+	ValidationDate string  `json:"validationDate,omitempty"`
+	ValidationTxID string  `json:"validationTxID,omitempty"`
+}
+
+// ExchangeRate mirrors the chaincode's published IDR value of one unit of a currency or commodity
+type ExchangeRate struct {
+	ID            string  `json:"ID"`
+	Denomination  string  `json:"denomination"`
+	RateIDR       float64 `json:"rateIDR"`
+	EffectiveDate string  `json:"effectiveDate"`
+	Source        string  `json:"source"`
+	PublishedBy   string  `json:"publishedBy"`
+	PublishedAt   string  `json:"publishedAt"`
+}
+
+// FitrahRate mirrors the chaincode's per-soul zakat fitrah rate for a region and Hijri year
+type FitrahRate struct {
+	ID            string  `json:"ID"`
+	Region        string  `json:"region"`
+	HijriYear     int     `json:"hijriYear"`
+	RatePerSoul   float64 `json:"ratePerSoul"`
+	RiceKgPerSoul float64 `json:"riceKgPerSoul"`
+	SetBy         string  `json:"setBy"`
+	UpdatedAt     string  `json:"updatedAt"`
+}
+
+// AddZakat creates a new zakat donation in the blockchain using the SubmitZakat chaincode function.
+// The zakat ID, organization and payment method are filled in with defaults when empty.
+// When the submission carries a request key the chaincode already saw, the ID of the zakat it
+// created then is returned instead.
+func (f *FabricService) AddZakat(submission ZakatSubmission) (string, error) {
+	organization, paymentMethod, err := resolvePaymentOptions(submission.Organization, submission.PaymentMethod)
+	if err != nil {
+		return "", err
+	}
+	submission.Organization, submission.PaymentMethod = organization, paymentMethod
+	if submission.ID == "" {
+		submission.ID = f.NewZakatID(submission.Organization)
+	}
+
+	submissionJSON, err := json.Marshal(submission)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal zakat submission: %w", err)
+}
+
+	log.Printf("🔗 Calling SubmitZakat chaincode for: %s (anonymous: %t)", submission.ID, submission.Anonymous)
 
 // Call chaincode
-_, err := f.contract.SubmitTransaction("AddZakat", args...)
+	result, err := f.contract.SubmitTransaction("SubmitZakat", string(submissionJSON))
 if err != nil {
-return "", fmt.Errorf("failed to submit AddZakat transaction: %w", err)
+		return "", fmt.Errorf("failed to submit SubmitZakat transaction: %w", parseContractError(err))
 }
 
-log.Printf("✅ Successfully added zakat to blockchain: %s", zakatID)
-return zakatID, nil
+	var stored struct {
+		ID string `json:"ID"`
+	}
+	if err := json.Unmarshal(result, &stored); err == nil && stored.ID != "" && stored.ID != submission.ID {
+		log.Printf("🔁 Request key %s already used, ledger returned zakat: %s", submission.RequestKey, stored.ID)
+		return stored.ID, nil
+	}
+
+	log.Printf("✅ Successfully added zakat to blockchain: %s", submission.ID)
+	return submission.ID, nil
 }
 
 // AutoValidatePayment auto-validates a pending payment
@@ -66,24 +230,25 @@ log.Printf("🔗 Calling AutoValidatePayment for: %s", zakatID)
 
 _, err := f.contract.SubmitTransaction("AutoValidatePayment", zakatID, paymentReference)
 if err != nil {
-return fmt.Errorf("failed to auto-validate payment: %w", err)
+		return fmt.Errorf("failed to auto-validate payment: %w", parseContractError(err))
 }
 
 log.Printf("✅ Successfully auto-validated payment for: %s", zakatID)
 return nil
 }
 
-// ValidatePayment manually validates a payment (admin action)
-func (f *FabricService) ValidatePayment(zakatID, receiptNumber, validatedBy string) error {
+// ValidatePayment manually validates a payment (admin action).
+// It returns the ID of the committed transaction.
+func (f *FabricService) ValidatePayment(zakatID, receiptNumber, validatedBy string) (string, error) {
 log.Printf("🔗 Calling ValidatePayment for: %s", zakatID)
 
-_, err := f.contract.SubmitTransaction("ValidatePayment", zakatID, receiptNumber, validatedBy)
+	_, txID, err := f.submitTransaction("ValidatePayment", zakatID, receiptNumber, validatedBy)
 if err != nil {
-return fmt.Errorf("failed to validate payment: %w", err)
+		return "", fmt.Errorf("failed to validate payment: %w", parseContractError(err))
 }
 
-log.Printf("✅ Successfully validated payment for: %s", zakatID)
-return nil
+	log.Printf("✅ Successfully validated payment for: %s (tx %s)", zakatID, txID)
+	return txID, nil
 }
 
 // QueryZakat queries a zakat donation by ID
@@ -92,7 +257,7 @@ log.Printf("🔍 Querying zakat: %s", zakatID)
 
 result, err := f.contract.EvaluateTransaction("QueryZakat", zakatID)
 if err != nil {
-return nil, fmt.Errorf("failed to query zakat: %w", err)
+		return nil, fmt.Errorf("failed to query zakat: %w", parseContractError(err))
 }
 
 var zakat map[string]interface{}
@@ -110,7 +275,7 @@ log.Printf("🔍 Querying all zakat donations")
 
 result, err := f.contract.EvaluateTransaction("GetAllZakat")
 if err != nil {
-return nil, fmt.Errorf("failed to get all zakat: %w", err)
+		return nil, fmt.Errorf("failed to get all zakat: %w", parseContractError(err))
 }
 
 var zakats []map[string]interface{}
@@ -122,13 +287,34 @@ return nil, fmt.Errorf("failed to unmarshal zakat data: %w", err)
 return zakats, nil
 }
 
+// ZakatPage is one page of zakat records from GetZakatPage; Records are decoded by the caller
+type ZakatPage struct {
+	Records  []json.RawMessage `json:"records"`
+	Bookmark string            `json:"bookmark"` // Empty on the last page
+}
+
+// GetZakatPage gets up to pageSize zakat records in ID order, after bookmark (empty for the first page)
+func (f *FabricService) GetZakatPage(pageSize int, bookmark string) (*ZakatPage, error) {
+	result, err := f.contract.EvaluateTransaction("GetZakatPage", strconv.Itoa(pageSize), bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get zakat page: %w", parseContractError(err))
+	}
+
+	var page ZakatPage
+	if err := json.Unmarshal(result, &page); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal zakat page: %w", err)
+	}
+
+	return &page, nil
+}
+
 // GetZakatByStatus gets zakat donations by status
 func (f *FabricService) GetZakatByStatus(status string) ([]map[string]interface{}, error) {
 log.Printf("🔍 Querying zakat by status: %s", status)
 
 result, err := f.contract.EvaluateTransaction("GetZakatByStatus", status)
 if err != nil {
-return nil, fmt.Errorf("failed to get zakat by status: %w", err)
+		return nil, fmt.Errorf("failed to get zakat by status: %w", parseContractError(err))
 }
 
 var zakats []map[string]interface{}
@@ -140,25 +326,199 @@ return nil, fmt.Errorf("failed to unmarshal zakat data: %w", err)
 return zakats, nil
 }
 
-// DistributeZakat distributes a collected zakat
-func (f *FabricService) DistributeZakat(zakatID, recipientName string, amount float64, distributedBy string) error {
-// Generate distribution ID
-distributionID := f.idGenerator.GenerateDistributionID(1)
+// NewZakatID generates the ID of a zakat to be submitted for an organization, so the
+// submission can be recorded before it reaches the ledger. IDs are numbered per organization
+// and month (ZKT-YDSF-MLG-202610-000123); a timestamped ID is used when no number can be
+// allocated, so donations are still accepted while Redis is down.
+func (f *FabricService) NewZakatID(organization string) string {
+	if organization == "" {
+		organization = defaultOrganization
+	}
+	if f.zakatSequence != nil {
+		id, err := f.zakatSequence.NextZakatID(organization)
+		if err == nil {
+			return id
+		}
+		log.Printf("⚠️ Failed to allocate zakat number, using a timestamped ID: %v", err)
+	}
+	return f.idGenerator.GenerateZakatID(organization, 1)
+}
 
-// Current timestamp
-timestamp := fmt.Sprintf("%d", time.Now().Unix())
+// NewDistributionID generates the ID of a distribution to be submitted
+func (f *FabricService) NewDistributionID() string {
+	return f.idGenerator.GenerateDistributionID(1)
+}
+
+// DistributeZakat distributes a collected zakat under a distribution ID from NewDistributionID.
+// It returns the ID of the committed transaction.
+func (f *FabricService) DistributeZakat(zakatID, distributionID, recipientName string, amount float64, distributedAt time.Time, distributedBy string) (string, error) {
 amountStr := fmt.Sprintf("%.2f", amount)
 
 log.Printf("🔗 Calling DistributeZakat for: %s", zakatID)
 
-_, err := f.contract.SubmitTransaction("DistributeZakat", 
-zakatID, distributionID, recipientName, amountStr, timestamp, distributedBy)
+	// The chaincode expects an ISO 8601 timestamp
+	_, txID, err := f.submitTransaction("DistributeZakat",
+		zakatID, distributionID, recipientName, amountStr, distributedAt.UTC().Format(time.RFC3339), distributedBy)
 if err != nil {
-return fmt.Errorf("failed to distribute zakat: %w", err)
+		return "", fmt.Errorf("failed to distribute zakat: %w", parseContractError(err))
 }
 
-log.Printf("✅ Successfully distributed zakat: %s", zakatID)
-return nil
+	log.Printf("✅ Successfully distributed zakat: %s (tx %s)", zakatID, txID)
+	return txID, nil
+}
+
+// DistributeFromProgram distributes an amount from a program's pooled funds. The chaincode
+// allocates it to the program's collected zakat oldest first and returns the allocations.
+func (f *FabricService) DistributeFromProgram(programID, recipientName string, amount float64, distributedBy string) (*ProgramDistribution, error) {
+	distributionID := f.idGenerator.GenerateDistributionID(1)
+	timestamp := time.Now().Format(time.RFC3339)
+	amountStr := fmt.Sprintf("%.2f", amount)
+
+	log.Printf("🔗 Calling DistributeFromProgram for: %s (%s)", programID, distributionID)
+
+	result, err := f.contract.SubmitTransaction("DistributeFromProgram",
+		programID, distributionID, recipientName, amountStr, timestamp, distributedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to distribute from program: %w", parseContractError(err))
+	}
+
+	var distribution ProgramDistribution
+	if err := json.Unmarshal(result, &distribution); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal program distribution: %w", err)
+	}
+
+	log.Printf("✅ Successfully distributed %s from program %s across %d zakat", amountStr, programID, len(distribution.Allocations))
+	return &distribution, nil
+}
+
+// ReallocateZakat moves an undistributed zakat to another program (admin action)
+func (f *FabricService) ReallocateZakat(zakatID, newProgramID, reason, approvedBy string) error {
+	log.Printf("🔗 Calling ReallocateZakat for: %s -> %s", zakatID, newProgramID)
+
+	_, err := f.contract.SubmitTransaction("ReallocateZakat", zakatID, newProgramID, reason, approvedBy)
+	if err != nil {
+		return fmt.Errorf("failed to reallocate zakat: %w", parseContractError(err))
+	}
+
+	log.Printf("✅ Successfully reallocated zakat %s to program %s", zakatID, newProgramID)
+	return nil
+}
+
+// CreatePledge records a recurring zakat pledge on the blockchain and returns the generated pledge ID
+func (f *FabricService) CreatePledge(muzakki string, amount float64, zakatType, schedule, programID, organization string, startDate time.Time) (string, error) {
+	pledgeID := f.idGenerator.GeneratePledgeID(organization, 1)
+	amountStr := fmt.Sprintf("%.2f", amount)
+
+	log.Printf("🔗 Calling CreatePledge for: %s (%s)", pledgeID, schedule)
+
+	_, err := f.contract.SubmitTransaction("CreatePledge",
+		pledgeID, muzakki, amountStr, zakatType, schedule, programID, organization, startDate.Format(time.RFC3339))
+	if err != nil {
+		return "", fmt.Errorf("failed to create pledge: %w", parseContractError(err))
+	}
+
+	log.Printf("✅ Successfully created pledge: %s", pledgeID)
+	return pledgeID, nil
+}
+
+// RecordPledgeFulfilment links a collected zakat to the pledge period it pays for
+func (f *FabricService) RecordPledgeFulfilment(pledgeID, zakatID, period string) error {
+	log.Printf("🔗 Calling RecordPledgeFulfilment for: %s (%s) <- %s", pledgeID, period, zakatID)
+
+	_, err := f.contract.SubmitTransaction("RecordPledgeFulfilment", pledgeID, zakatID, period)
+	if err != nil {
+		return fmt.Errorf("failed to record pledge fulfilment: %w", parseContractError(err))
+	}
+
+	log.Printf("✅ Successfully recorded fulfilment of pledge %s for %s", pledgeID, period)
+	return nil
+}
+
+// CancelPledge cancels an active pledge on the blockchain
+func (f *FabricService) CancelPledge(pledgeID, reason string) error {
+	log.Printf("🔗 Calling CancelPledge for: %s", pledgeID)
+
+	_, err := f.contract.SubmitTransaction("CancelPledge", pledgeID, reason)
+	if err != nil {
+		return fmt.Errorf("failed to cancel pledge: %w", parseContractError(err))
+	}
+
+	log.Printf("✅ Successfully cancelled pledge: %s", pledgeID)
+	return nil
+}
+
+// SetFitrahRate publishes the per-soul zakat fitrah rate for a region and Hijri year
+func (f *FabricService) SetFitrahRate(region string, hijriYear int, ratePerSoul, riceKgPerSoul float64, setBy string) error {
+	log.Printf("🔗 Calling SetFitrahRate for: %s %dH", region, hijriYear)
+
+	_, err := f.contract.SubmitTransaction("SetFitrahRate",
+		region, strconv.Itoa(hijriYear), fmt.Sprintf("%.2f", ratePerSoul), fmt.Sprintf("%.2f", riceKgPerSoul), setBy)
+	if err != nil {
+		return fmt.Errorf("failed to set fitrah rate: %w", parseContractError(err))
+	}
+
+	log.Printf("✅ Successfully set fitrah rate for %s %dH: %.2f per soul", region, hijriYear, ratePerSoul)
+	return nil
+}
+
+// GetFitrahRate gets the per-soul zakat fitrah rate for a region; hijriYear 0 means the current year
+func (f *FabricService) GetFitrahRate(region string, hijriYear int) (*FitrahRate, error) {
+	result, err := f.contract.EvaluateTransaction("GetFitrahRate", region, strconv.Itoa(hijriYear))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fitrah rate: %w", parseContractError(err))
+	}
+
+	var rate FitrahRate
+	if err := json.Unmarshal(result, &rate); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal fitrah rate: %w", err)
+	}
+
+	return &rate, nil
+}
+
+// VerifyReceipt checks a receipt hash against the zakat recorded on the ledger
+func (f *FabricService) VerifyReceipt(zakatID, receiptHash string) (*ReceiptVerification, error) {
+	result, err := f.contract.EvaluateTransaction("VerifyReceipt", zakatID, receiptHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify receipt: %w", parseContractError(err))
+	}
+
+	var verification ReceiptVerification
+	if err := json.Unmarshal(result, &verification); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal receipt verification: %w", err)
+	}
+
+	return &verification, nil
+}
+
+// PublishExchangeRate publishes the IDR value of one unit of a denomination from effectiveDate (YYYY-MM-DD).
+// The chaincode only accepts it from identities with the zakat.ratePublisher attribute.
+func (f *FabricService) PublishExchangeRate(denomination string, rateIDR float64, effectiveDate, source string) error {
+	log.Printf("🔗 Calling PublishExchangeRate for: %s %s", denomination, effectiveDate)
+
+	_, err := f.contract.SubmitTransaction("PublishExchangeRate",
+		denomination, strconv.FormatFloat(rateIDR, 'f', -1, 64), effectiveDate, source)
+	if err != nil {
+		return fmt.Errorf("failed to publish exchange rate: %w", parseContractError(err))
+	}
+
+	log.Printf("✅ Successfully published %s rate for %s: %.2f IDR", denomination, effectiveDate, rateIDR)
+	return nil
+}
+
+// GetExchangeRate gets the exchange rate currently in force for a denomination
+func (f *FabricService) GetExchangeRate(denomination string) (*ExchangeRate, error) {
+	result, err := f.contract.EvaluateTransaction("GetExchangeRate", denomination, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rate: %w", parseContractError(err))
+	}
+
+	var rate ExchangeRate
+	if err := json.Unmarshal(result, &rate); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal exchange rate: %w", err)
+	}
+
+	return &rate, nil
 }
 
 // CreateProgram creates a new donation program
@@ -178,20 +538,84 @@ log.Printf("🔗 Calling CreateProgram: %s", name)
 _, err := f.contract.SubmitTransaction("CreateProgram", 
 programID, name, description, targetStr, startDate, endDate, createdBy)
 if err != nil {
-return "", fmt.Errorf("failed to create program: %w", err)
+		return "", fmt.Errorf("failed to create program: %w", parseContractError(err))
 }
 
 log.Printf("✅ Successfully created program: %s", programID)
 return programID, nil
 }
 
+// GetProgramSummary gets a program's campaign progress computed by the chaincode
+func (f *FabricService) GetProgramSummary(programID string) (*ProgramSummary, error) {
+	log.Printf("🔍 Querying summary of program %s", programID)
+
+	result, err := f.contract.EvaluateTransaction("GetProgramSummary", programID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get program summary: %w", parseContractError(err))
+	}
+
+	var summary ProgramSummary
+	if err := json.Unmarshal(result, &summary); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal program summary: %w", err)
+	}
+
+	return &summary, nil
+}
+
+// GetOfficerByReferral gets the officer currently holding a referral code
+func (f *FabricService) GetOfficerByReferral(referralCode string) (*Officer, error) {
+	result, err := f.contract.EvaluateTransaction("GetOfficerByReferral", referralCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get officer by referral code: %w", parseContractError(err))
+	}
+
+	var officer Officer
+	if err := json.Unmarshal(result, &officer); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal officer: %w", err)
+	}
+	return &officer, nil
+}
+
+// GetOfficerLeaderboard gets the officers ranked by the referrals they brought to an organization
+// between startDate and endDate (YYYY-MM-DD, both inclusive)
+func (f *FabricService) GetOfficerLeaderboard(organization, startDate, endDate string) ([]OfficerPerformance, error) {
+	log.Printf("🔍 Querying officer leaderboard of %s from %s to %s", organization, startDate, endDate)
+
+	result, err := f.contract.EvaluateTransaction("GetOfficerLeaderboard", organization, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get officer leaderboard: %w", parseContractError(err))
+	}
+
+	leaderboard := []OfficerPerformance{}
+	if err := json.Unmarshal(result, &leaderboard); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal officer leaderboard: %w", err)
+	}
+	return leaderboard, nil
+}
+
+// GetOfficerPerformance gets one officer's referral performance and rank within an organization
+func (f *FabricService) GetOfficerPerformance(officerID, organization, startDate, endDate string) (*OfficerPerformance, error) {
+	log.Printf("🔍 Querying performance of officer %s in %s from %s to %s", officerID, organization, startDate, endDate)
+
+	result, err := f.contract.EvaluateTransaction("GetOfficerPerformance", officerID, organization, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get officer performance: %w", parseContractError(err))
+	}
+
+	var performance OfficerPerformance
+	if err := json.Unmarshal(result, &performance); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal officer performance: %w", err)
+	}
+	return &performance, nil
+}
+
 // GetAllPrograms gets all donation programs
 func (f *FabricService) GetAllPrograms() ([]map[string]interface{}, error) {
 log.Printf("🔍 Querying all programs")
 
 result, err := f.contract.EvaluateTransaction("GetAllPrograms")
 if err != nil {
-return nil, fmt.Errorf("failed to get all programs: %w", err)
+		return nil, fmt.Errorf("failed to get all programs: %w", parseContractError(err))
 }
 
 var programs []map[string]interface{}
@@ -2,6 +2,8 @@ package services
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -13,24 +15,51 @@ func NewIDGeneratorService() *IDGeneratorService {
 	return &IDGeneratorService{}
 }
 
-// GenerateZakatID generates a new zakat ID in the format:
-// ZKT-YDSF-{MLG|JTM}-{UNIXTIMESTAMPNANO}-{SEQUENCE}
-func (g *IDGeneratorService) GenerateZakatID(organization string, sequence int) string {
-	// Map organization to org code
-	var orgCode string
+// zakatOrgCode maps an organization to the code used in its zakat IDs
+func zakatOrgCode(organization string) string {
 	switch organization {
 	case "YDSF Malang":
-		orgCode = "MLG"
+		return "MLG"
 	case "YDSF Jatim":
-		orgCode = "JTM"
+		return "JTM"
 	default:
-		orgCode = "MLG" // Default to Malang
+		return "MLG" // Default to Malang
 	}
+}
 
+// GenerateZakatID generates a new zakat ID in the format:
+// ZKT-YDSF-{MLG|JTM}-{UNIXTIMESTAMPNANO}-{SEQUENCE}
+// Only used when no number can be allocated with GenerateNumberedZakatID.
+func (g *IDGeneratorService) GenerateZakatID(organization string, sequence int) string {
 	// Generate nanosecond timestamp for uniqueness
 	nanoTimestamp := time.Now().UnixNano()
 
-	return fmt.Sprintf("ZKT-YDSF-%s-%d-%04d", orgCode, nanoTimestamp, sequence)
+	return fmt.Sprintf("ZKT-YDSF-%s-%d-%04d", zakatOrgCode(organization), nanoTimestamp, sequence)
+}
+
+// GenerateNumberedZakatID generates a zakat ID in the format:
+// ZKT-YDSF-{MLG|JTM}-{YYYYMM}-{NUMBER}
+// where NUMBER counts the organization's zakat of the month from 000001
+func (g *IDGeneratorService) GenerateNumberedZakatID(organization string, month time.Time, number int64) string {
+	return fmt.Sprintf("%s%06d", g.NumberedZakatIDPrefix(organization, month), number)
+}
+
+// NumberedZakatIDPrefix returns the ZKT-YDSF-{MLG|JTM}-{YYYYMM}- prefix of an organization's
+// numbered zakat IDs for a month
+func (g *IDGeneratorService) NumberedZakatIDPrefix(organization string, month time.Time) string {
+	return fmt.Sprintf("ZKT-YDSF-%s-%s-", zakatOrgCode(organization), month.Format("200601"))
+}
+
+// GeneratePledgeID generates a new pledge ID in the format:
+// PLG-YDSF-{MLG|JTM}-{UNIXTIMESTAMPNANO}-{SEQUENCE}
+func (g *IDGeneratorService) GeneratePledgeID(organization string, sequence int) string {
+	orgCode := "MLG" // Default to Malang
+	if organization == "YDSF Jatim" {
+		orgCode = "JTM"
+	}
+
+	nanoTimestamp := time.Now().UnixNano()
+	return fmt.Sprintf("PLG-YDSF-%s-%d-%04d", orgCode, nanoTimestamp, sequence)
 }
 
 // GenerateProgramID generates a new program ID in the format:
@@ -56,7 +85,7 @@ func (g *IDGeneratorService) GenerateDistributionID(sequence int) string {
 
 // GetOrganizationFromZakatID extracts organization from a zakat ID
 func (g *IDGeneratorService) GetOrganizationFromZakatID(zakatID string) string {
-	// Parse ZKT-YDSF-{MLG|JTM}-{TIMESTAMP}-{SEQUENCE}
+	// Parse ZKT-YDSF-{MLG|JTM}-{TIMESTAMP|YYYYMM}-{SEQUENCE}
 	if len(zakatID) >= 12 {
 		orgCode := zakatID[9:12] // Extract MLG or JTM
 		switch orgCode {
@@ -71,11 +100,11 @@ func (g *IDGeneratorService) GetOrganizationFromZakatID(zakatID string) string {
 
 // GetSequenceFromID extracts the sequence number from any ID
 func (g *IDGeneratorService) GetSequenceFromID(id string) int {
-	// IDs end with -SEQUENCE format, extract last 4 digits
-	if len(id) >= 4 {
-		var sequence int
-		fmt.Sscanf(id[len(id)-4:], "%04d", &sequence)
-		return sequence
+	// IDs end with -SEQUENCE format; numbered zakat IDs have 6 digits, the others 4
+	if i := strings.LastIndex(id, "-"); i >= 0 {
+		if sequence, err := strconv.Atoi(id[i+1:]); err == nil {
+			return sequence
+		}
 	}
 	return 1 // Default sequence
 }
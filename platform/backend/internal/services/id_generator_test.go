@@ -0,0 +1,80 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateNumberedZakatID(t *testing.T) {
+	g := NewIDGeneratorService()
+	march := time.Date(2025, 3, 31, 23, 59, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name           string
+		organization   string
+		number         int64
+		expectedPrefix string
+		expected       string
+	}{
+		{name: "Malang", organization: "YDSF Malang", number: 1, expectedPrefix: "ZKT-YDSF-MLG-202503-", expected: "ZKT-YDSF-MLG-202503-000001"},
+		{name: "Jatim", organization: "YDSF Jatim", number: 42, expectedPrefix: "ZKT-YDSF-JTM-202503-", expected: "ZKT-YDSF-JTM-202503-000042"},
+		{name: "UnknownOrganization", organization: "YDSF Surabaya", number: 7, expectedPrefix: "ZKT-YDSF-MLG-202503-", expected: "ZKT-YDSF-MLG-202503-000007"},
+		{name: "PastSixDigits", organization: "YDSF Malang", number: 1234567, expectedPrefix: "ZKT-YDSF-MLG-202503-", expected: "ZKT-YDSF-MLG-202503-1234567"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expectedPrefix, g.NumberedZakatIDPrefix(tc.organization, march))
+			id := g.GenerateNumberedZakatID(tc.organization, march, tc.number)
+			require.Equal(t, tc.expected, id)
+			require.Equal(t, int(tc.number), g.GetSequenceFromID(id))
+		})
+	}
+}
+
+func TestGetSequenceFromID(t *testing.T) {
+	g := NewIDGeneratorService()
+
+	testCases := []struct {
+		id       string
+		expected int
+	}{
+		{"ZKT-YDSF-MLG-202503-000123", 123},
+		{"ZKT-YDSF-JTM-1735689000000000000-0042", 42},
+		{"PROG-2025-0001", 1},
+		{"DIST-1735689000000000000-0310", 310},
+		{"ZKT-YDSF-MLG-202503-", 1},
+		{"ZKT-YDSF-MLG-202503-ABC", 1},
+		{"ZKT005", 1},
+		{"", 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.id, func(t *testing.T) {
+			require.Equal(t, tc.expected, g.GetSequenceFromID(tc.id))
+		})
+	}
+}
+
+func TestGetOrganizationFromZakatID(t *testing.T) {
+	g := NewIDGeneratorService()
+
+	testCases := []struct {
+		id       string
+		expected string
+	}{
+		{"ZKT-YDSF-MLG-202503-000001", "YDSF Malang"},
+		{"ZKT-YDSF-JTM-202503-000001", "YDSF Jatim"},
+		{"ZKT-YDSF-JTM-1735689000000000000-0001", "YDSF Jatim"},
+		{"ZKT-YDSF-SBY-202503-000001", "YDSF Malang"},
+		{"ZKT005", "YDSF Malang"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.id, func(t *testing.T) {
+			require.Equal(t, tc.expected, g.GetOrganizationFromZakatID(tc.id))
+		})
+	}
+}
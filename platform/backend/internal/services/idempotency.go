@@ -0,0 +1,121 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/izzuddinafif/fabric/platform/backend/pkg/database"
+)
+
+var (
+	// ErrIdempotencyKeyReused is returned when a key is replayed with a different request
+	ErrIdempotencyKeyReused = errors.New("idempotency key was already used with a different request")
+	// ErrIdempotentRequestInProgress is returned while the first request under a key is still running
+	ErrIdempotentRequestInProgress = errors.New("a request with this idempotency key is still being processed")
+)
+
+var idempotencyKeyPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,100}$`)
+
+// IdempotentResponse is what is stored in Redis under an idempotency key
+type IdempotentResponse struct {
+	RequestHash string          `json:"request_hash"`
+	StatusCode  int             `json:"status_code,omitempty"` // 0 while the first request is in progress
+	Body        json.RawMessage `json:"body,omitempty"`
+}
+
+// IdempotencyService stores responses under client Idempotency-Key headers in Redis, so a
+// retried request gets the original response instead of being processed twice
+type IdempotencyService struct {
+	redis *redis.Client
+	ttl   time.Duration
+}
+
+// NewIdempotencyService creates a new idempotency service keeping keys for ttl
+func NewIdempotencyService(redis *redis.Client, ttl time.Duration) *IdempotencyService {
+	return &IdempotencyService{
+		redis: redis,
+		ttl:   ttl,
+	}
+}
+
+// ValidIdempotencyKey reports whether a client key can be used, including on the ledger
+func ValidIdempotencyKey(key string) bool {
+	return idempotencyKeyPattern.MatchString(key)
+}
+
+// RequestHash returns the hex SHA-256 of a request's JSON encoding
+func RequestHash(request interface{}) (string, error) {
+	requestJSON, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+	sum := sha256.Sum256(requestJSON)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Begin reserves key within scope for a request. It returns the stored response when the same
+// request already completed, and nil when the caller should process the request and then call
+// Complete or Release.
+func (s *IdempotencyService) Begin(scope, key, requestHash string) (*IdempotentResponse, error) {
+	redisKey := idempotencyRedisKey(scope, key)
+	reservation, err := json.Marshal(IdempotentResponse{RequestHash: requestHash})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal idempotency reservation: %w", err)
+	}
+
+	reserved, err := s.redis.SetNX(database.Ctx, redisKey, reservation, s.ttl).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	if reserved {
+		return nil, nil
+	}
+
+	data, err := s.redis.Get(database.Ctx, redisKey).Bytes()
+	if err == redis.Nil {
+		return nil, ErrIdempotentRequestInProgress // Released or expired just now; the client can retry
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	var stored IdempotentResponse
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal idempotency key: %w", err)
+	}
+	if stored.RequestHash != requestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if stored.StatusCode == 0 {
+		return nil, ErrIdempotentRequestInProgress
+	}
+	return &stored, nil
+}
+
+// Complete stores the response of a request reserved with Begin
+func (s *IdempotencyService) Complete(scope, key, requestHash string, statusCode int, body interface{}) error {
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal idempotent response: %w", err)
+	}
+	data, err := json.Marshal(IdempotentResponse{RequestHash: requestHash, StatusCode: statusCode, Body: bodyJSON})
+	if err != nil {
+		return fmt.Errorf("failed to marshal idempotent response: %w", err)
+	}
+	return s.redis.Set(database.Ctx, idempotencyRedisKey(scope, key), data, s.ttl).Err()
+}
+
+// Release frees a key reserved with Begin so the request can be retried
+func (s *IdempotencyService) Release(scope, key string) error {
+	return s.redis.Del(database.Ctx, idempotencyRedisKey(scope, key)).Err()
+}
+
+func idempotencyRedisKey(scope, key string) string {
+	return fmt.Sprintf("idempotency:%s:%s", scope, key)
+}
@@ -0,0 +1,653 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IndexerStatus reports how far the block indexer has got
+type IndexerStatus struct {
+	Channel   string    `json:"channel"`
+	Chaincode string    `json:"chaincode"`
+	NextBlock uint64    `json:"next_block"` // First block not indexed yet
+	Height    uint64    `json:"height"`     // Blocks on the channel
+	Lag       uint64    `json:"lag"`        // Blocks still to index
+	UpdatedAt time.Time `json:"updated_at"` // When the checkpoint last moved
+}
+
+// ledgerWrite is one key written by a valid transaction of the zakat chaincode
+type ledgerWrite struct {
+	TxID  string
+	Key   string
+	Value []byte
+}
+
+// BlockIndexer copies ledger state into Postgres. It reads committed blocks through the query
+// system chaincode (qscc), decodes the zakat chaincode's write sets and upserts donations,
+// programs, officers and distributions, recording the block and transaction of each write.
+// The next block to read is checkpointed in the same transaction as the block's writes.
+type BlockIndexer struct {
+	qscc      *gateway.Contract
+	db        *gorm.DB
+	channel   string
+	chaincode string
+	mu        sync.Mutex // Serializes indexing runs and checkpoint resets
+}
+
+// NewBlockIndexer creates a new block indexer for a chaincode on a channel
+func NewBlockIndexer(qscc *gateway.Contract, db *gorm.DB, channel, chaincode string) *BlockIndexer {
+	return &BlockIndexer{
+		qscc:      qscc,
+		db:        db,
+		channel:   channel,
+		chaincode: chaincode,
+	}
+}
+
+// Start indexes new blocks every interval. A startBlock of 0 or more first moves the checkpoint
+// there, to index the channel from the beginning or again from a given block.
+func (s *BlockIndexer) Start(interval time.Duration, startBlock int) {
+	if startBlock >= 0 {
+		if err := s.SetCheckpoint(uint64(startBlock)); err != nil {
+			log.Printf("❌ Block indexer: %v", err)
+		}
+	}
+	log.Printf("🕒 Block indexer polling %s every %v", s.checkpointName(), interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := s.IndexNewBlocks(); err != nil {
+				log.Printf("❌ Block indexer: %v", err)
+			}
+		}
+	}()
+}
+
+// IndexNewBlocks indexes every committed block after the checkpoint
+func (s *BlockIndexer) IndexNewBlocks() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next, _, err := s.checkpoint()
+	if err != nil {
+		return err
+	}
+	height, err := s.height()
+	if err != nil {
+		return err
+	}
+
+	for ; next < height; next++ {
+		if err := s.indexBlock(next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Status returns the checkpoint and the channel's current height
+func (s *BlockIndexer) Status() (*IndexerStatus, error) {
+	next, updatedAt, err := s.checkpoint()
+	if err != nil {
+		return nil, err
+	}
+	height, err := s.height()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &IndexerStatus{
+		Channel:   s.channel,
+		Chaincode: s.chaincode,
+		NextBlock: next,
+		Height:    height,
+		UpdatedAt: updatedAt,
+	}
+	if height > next {
+		status.Lag = height - next
+	}
+	return status, nil
+}
+
+// SetCheckpoint makes block the next block to index. Blocks before it are not read again;
+// indexing from an earlier block re-applies its writes without undoing newer ones.
+func (s *BlockIndexer) SetCheckpoint(block uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.saveCheckpoint(s.db, block); err != nil {
+		return err
+	}
+	log.Printf("⏮️ Block indexer checkpoint of %s set to block %d", s.checkpointName(), block)
+	return nil
+}
+
+func (s *BlockIndexer) checkpointName() string {
+	return s.channel + "/" + s.chaincode
+}
+
+// checkpoint returns the next block to index, 0 when nothing was indexed yet
+func (s *BlockIndexer) checkpoint() (uint64, time.Time, error) {
+	var checkpoint models.IndexerCheckpoint
+	err := s.db.Where("name = ?", s.checkpointName()).First(&checkpoint).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, time.Time{}, nil
+	}
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to get indexer checkpoint: %w", err)
+	}
+	return uint64(checkpoint.NextBlock), checkpoint.UpdatedAt, nil
+}
+
+func (s *BlockIndexer) saveCheckpoint(tx *gorm.DB, next uint64) error {
+	err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"next_block", "updated_at"}),
+	}).Create(&models.IndexerCheckpoint{
+		Name:      s.checkpointName(),
+		NextBlock: int64(next),
+		UpdatedAt: time.Now(),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to save indexer checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (s *BlockIndexer) height() (uint64, error) {
+	result, err := s.qscc.EvaluateTransaction("GetChainInfo", s.channel)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query chain info of %s: %w", s.channel, err)
+	}
+
+	var info common.BlockchainInfo
+	if err := proto.Unmarshal(result, &info); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal chain info of %s: %w", s.channel, err)
+	}
+	return info.Height, nil
+}
+
+// indexBlock applies a block's writes and moves the checkpoint past it in one transaction
+func (s *BlockIndexer) indexBlock(number uint64) error {
+	result, err := s.qscc.EvaluateTransaction("GetBlockByNumber", s.channel, strconv.FormatUint(number, 10))
+	if err != nil {
+		return fmt.Errorf("failed to get block %d: %w", number, err)
+	}
+
+	var block common.Block
+	if err := proto.Unmarshal(result, &block); err != nil {
+		return fmt.Errorf("failed to unmarshal block %d: %w", number, err)
+	}
+	writes, err := s.decodeBlock(&block)
+	if err != nil {
+		return fmt.Errorf("failed to decode block %d: %w", number, err)
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for _, write := range writes {
+			if err := s.apply(tx, number, write); err != nil {
+				return fmt.Errorf("failed to index %s (tx %s): %w", write.Key, write.TxID, err)
+			}
+		}
+		return s.saveCheckpoint(tx, number+1)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to index block %d: %w", number, err)
+	}
+
+	if len(writes) > 0 {
+		log.Printf("📦 Indexed block %d: %d writes", number, len(writes))
+	}
+	return nil
+}
+
+// decodeBlock returns the chaincode's writes in a block's valid endorser transactions, in block
+// order. Within a transaction, records are applied before the records that refer to them.
+func (s *BlockIndexer) decodeBlock(block *common.Block) ([]ledgerWrite, error) {
+	var validation []byte
+	if block.Metadata != nil && len(block.Metadata.Metadata) > int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		validation = block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER]
+	}
+
+	var writes []ledgerWrite
+	for i, data := range block.GetData().GetData() {
+		if i < len(validation) && peer.TxValidationCode(validation[i]) != peer.TxValidationCode_VALID {
+			continue
+		}
+
+		var envelope common.Envelope
+		if err := proto.Unmarshal(data, &envelope); err != nil {
+			return nil, fmt.Errorf("transaction %d: failed to unmarshal envelope: %w", i, err)
+		}
+		var payload common.Payload
+		if err := proto.Unmarshal(envelope.Payload, &payload); err != nil {
+			return nil, fmt.Errorf("transaction %d: failed to unmarshal payload: %w", i, err)
+		}
+		if payload.Header == nil {
+			continue
+		}
+		var header common.ChannelHeader
+		if err := proto.Unmarshal(payload.Header.ChannelHeader, &header); err != nil {
+			return nil, fmt.Errorf("transaction %d: failed to unmarshal channel header: %w", i, err)
+		}
+		if common.HeaderType(header.Type) != common.HeaderType_ENDORSER_TRANSACTION {
+			continue
+		}
+
+		txWrites, err := s.decodeTransaction(header.TxId, payload.Data)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %s: %w", header.TxId, err)
+		}
+		sort.SliceStable(txWrites, func(a, b int) bool {
+			return writeOrder(txWrites[a].Key) < writeOrder(txWrites[b].Key)
+		})
+		writes = append(writes, txWrites...)
+	}
+	return writes, nil
+}
+
+// decodeTransaction returns the chaincode's writes in an endorser transaction
+func (s *BlockIndexer) decodeTransaction(txID string, data []byte) ([]ledgerWrite, error) {
+	var transaction peer.Transaction
+	if err := proto.Unmarshal(data, &transaction); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal transaction: %w", err)
+	}
+
+	var writes []ledgerWrite
+	for _, action := range transaction.Actions {
+		var actionPayload peer.ChaincodeActionPayload
+		if err := proto.Unmarshal(action.Payload, &actionPayload); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal action payload: %w", err)
+		}
+		if actionPayload.Action == nil {
+			continue
+		}
+		var response peer.ProposalResponsePayload
+		if err := proto.Unmarshal(actionPayload.Action.ProposalResponsePayload, &response); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal proposal response: %w", err)
+		}
+		var chaincodeAction peer.ChaincodeAction
+		if err := proto.Unmarshal(response.Extension, &chaincodeAction); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal chaincode action: %w", err)
+		}
+		var txRWSet rwset.TxReadWriteSet
+		if err := proto.Unmarshal(chaincodeAction.Results, &txRWSet); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal read-write set: %w", err)
+		}
+
+		for _, namespace := range txRWSet.NsRwset {
+			if namespace.Namespace != s.chaincode {
+				continue
+			}
+			var kvRWSet kvrwset.KVRWSet
+			if err := proto.Unmarshal(namespace.Rwset, &kvRWSet); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal %s write set: %w", s.chaincode, err)
+			}
+			for _, write := range kvRWSet.Writes {
+				// Deletes only come from the ClearAll* development resets; indexed rows are kept
+				if write.IsDelete {
+					continue
+				}
+				writes = append(writes, ledgerWrite{TxID: txID, Key: write.Key, Value: write.Value})
+			}
+		}
+	}
+	return writes, nil
+}
+
+// writeOrder ranks ledger keys so programs and officers are indexed before the zakat that refer
+// to them, and zakat before the program distributions allocated to them
+func writeOrder(key string) int {
+	switch {
+	case strings.HasPrefix(key, "PROG-"), strings.HasPrefix(key, "OFF-"):
+		return 0
+	case strings.HasPrefix(key, "ZKT-"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// apply upserts the record behind one ledger write. Keys of other records (pledges, rates,
+// request key indexes) are not mirrored in Postgres and are skipped.
+func (s *BlockIndexer) apply(tx *gorm.DB, block uint64, write ledgerWrite) error {
+	switch {
+	case strings.HasPrefix(write.Key, "ZKT-"):
+		var zakat ledgerZakat
+		if err := json.Unmarshal(write.Value, &zakat); err != nil {
+			return fmt.Errorf("failed to unmarshal zakat: %w", err)
+		}
+		return s.upsertDonation(tx, block, write.TxID, zakat)
+	case strings.HasPrefix(write.Key, "PROG-"):
+		var program ledgerProgram
+		if err := json.Unmarshal(write.Value, &program); err != nil {
+			return fmt.Errorf("failed to unmarshal program: %w", err)
+		}
+		return s.upsertProgram(tx, block, write.TxID, program)
+	case strings.HasPrefix(write.Key, "OFF-"):
+		var officer ledgerOfficer
+		if err := json.Unmarshal(write.Value, &officer); err != nil {
+			return fmt.Errorf("failed to unmarshal officer: %w", err)
+		}
+		return s.upsertOfficer(tx, block, write.TxID, officer)
+	case strings.HasPrefix(write.Key, "PDIST-"):
+		var distribution ProgramDistribution
+		if err := json.Unmarshal(write.Value, &distribution); err != nil {
+			return fmt.Errorf("failed to unmarshal program distribution: %w", err)
+		}
+		return s.insertProgramDistribution(tx, write.TxID, distribution)
+	}
+	return nil
+}
+
+// ledgerZakat mirrors the chaincode's zakat record, as far as Postgres stores it
+type ledgerZakat struct {
+	ID             string  `json:"ID"`
+	ProgramID      string  `json:"programID"`
+	Muzakki        string  `json:"muzakki"`
+	IsAnonymous    bool    `json:"isAnonymous"`
+	Amount         float64 `json:"amount"`
+	Type           string  `json:"type"`
+	PaymentMethod  string  `json:"paymentMethod"`
+	Status         string  `json:"status"`
+	Organization   string  `json:"organization"`
+	ReferralCode   string  `json:"referralCode"`
+	ReceiptNumber  string  `json:"receiptNumber"`
+	Timestamp      string  `json:"timestamp"`
+	ValidatedBy    string  `json:"validatedBy"`
+	ValidationDate string  `json:"validationDate"`
+	Mustahik       string  `json:"mustahik"`
+	Distribution   float64 `json:"distribution"`
+	DistributedAt  string  `json:"distributedAt"`
+	DistributionID string  `json:"distributionID"`
+	DistributedBy  string  `json:"distributedBy"`
+	ValidationTxID string  `json:"validationTxID"`
+	Souls          int     `json:"souls"`
+	Denomination   string  `json:"denomination"`
+	OriginalAmount float64 `json:"originalAmount"`
+	ExchangeRate   float64 `json:"exchangeRate"`
+
+	Allocations []struct {
+		Amount float64 `json:"amount"`
+	} `json:"allocations"`
+}
+
+// ledgerProgram mirrors the chaincode's donation program
+type ledgerProgram struct {
+	ID          string  `json:"ID"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Target      float64 `json:"target"`
+	Collected   float64 `json:"collected"`
+	Distributed float64 `json:"distributed"`
+	Status      string  `json:"status"`
+	CreatedAt   string  `json:"createdAt"`
+}
+
+// ledgerOfficer mirrors the chaincode's officer record
+type ledgerOfficer struct {
+	ID             string  `json:"ID"`
+	Name           string  `json:"name"`
+	ReferralCode   string  `json:"referralCode"`
+	TotalReferred  float64 `json:"totalReferred"`
+	CommissionRate float64 `json:"commissionRate"`
+	Status         string  `json:"status"`
+	CreatedAt      string  `json:"createdAt"`
+	Phone          string  `json:"phone"`
+	Email          string  `json:"email"`
+	UpdatedAt      string  `json:"updatedAt"`
+}
+
+// upsertDonation mirrors a zakat into donations. Donor contact details are not on the ledger:
+// donations created elsewhere get the ledger's donor name and no phone, and existing donations
+// keep theirs. Validation and distribution details recorded by the API are kept as well.
+func (s *BlockIndexer) upsertDonation(tx *gorm.DB, block uint64, txID string, zakat ledgerZakat) error {
+	denomination, originalAmount, exchangeRate := zakat.Denomination, zakat.OriginalAmount, zakat.ExchangeRate
+	if denomination == "" {
+		denomination, originalAmount, exchangeRate = "IDR", zakat.Amount, 1
+	}
+	displayName := ""
+	if zakat.IsAnonymous {
+		displayName = zakat.Muzakki
+	}
+
+	err := tx.Exec(`
+INSERT INTO donations (id, donor_name, donor_phone, is_anonymous, display_name, amount, type, program_id, referral_code,
+    blockchain_status, sync_status, organization, payment_method, payment_reference, validated_at, validated_by,
+    distributed_at, distributed_by, distributed_amount, blockchain_tx_id, souls, denomination, original_amount,
+    exchange_rate, ledger_block, ledger_tx_id, created_at, updated_at)
+VALUES (@id, @muzakki, '', @anonymous, NULLIF(@display_name, ''), @amount, @type,
+    (SELECT id FROM programs WHERE id = @program_id), NULLIF(@referral_code, ''),
+    @status, 'synced', @organization, @payment_method, NULLIF(@receipt_number, ''), @validated_at, NULLIF(@validated_by, ''),
+    @distributed_at, NULLIF(@distributed_by, ''), @distributed_amount, NULLIF(@validation_tx_id, ''), @souls, @denomination,
+    @original_amount, @exchange_rate, @block, @tx_id, COALESCE(@created_at, NOW()), NOW())
+ON CONFLICT (id) DO UPDATE SET
+    amount = EXCLUDED.amount,
+    type = EXCLUDED.type,
+    is_anonymous = EXCLUDED.is_anonymous,
+    program_id = COALESCE(EXCLUDED.program_id, donations.program_id),
+    referral_code = EXCLUDED.referral_code,
+    blockchain_status = EXCLUDED.blockchain_status,
+    organization = EXCLUDED.organization,
+    payment_method = EXCLUDED.payment_method,
+    payment_reference = COALESCE(EXCLUDED.payment_reference, donations.payment_reference),
+    validated_at = COALESCE(donations.validated_at, EXCLUDED.validated_at),
+    validated_by = COALESCE(donations.validated_by, EXCLUDED.validated_by),
+    distributed_at = COALESCE(donations.distributed_at, EXCLUDED.distributed_at),
+    distributed_by = COALESCE(donations.distributed_by, EXCLUDED.distributed_by),
+    distributed_amount = EXCLUDED.distributed_amount,
+    blockchain_tx_id = COALESCE(donations.blockchain_tx_id, EXCLUDED.blockchain_tx_id),
+    souls = EXCLUDED.souls,
+    denomination = EXCLUDED.denomination,
+    original_amount = EXCLUDED.original_amount,
+    exchange_rate = EXCLUDED.exchange_rate,
+    ledger_block = EXCLUDED.ledger_block,
+    ledger_tx_id = EXCLUDED.ledger_tx_id,
+    updated_at = NOW()
+WHERE donations.ledger_block IS NULL OR donations.ledger_block <= EXCLUDED.ledger_block`,
+		map[string]interface{}{
+			"id":                 zakat.ID,
+			"muzakki":            zakat.Muzakki,
+			"anonymous":          zakat.IsAnonymous,
+			"display_name":       displayName,
+			"amount":             zakat.Amount,
+			"type":               zakat.Type,
+			"program_id":         zakat.ProgramID,
+			"referral_code":      zakat.ReferralCode,
+			"status":             zakat.Status,
+			"organization":       zakat.Organization,
+			"payment_method":     zakat.PaymentMethod,
+			"receipt_number":     zakat.ReceiptNumber,
+			"validated_at":       ledgerTime(zakat.ValidationDate),
+			"validated_by":       zakat.ValidatedBy,
+			"distributed_at":     ledgerTime(zakat.DistributedAt),
+			"distributed_by":     zakat.DistributedBy,
+			"distributed_amount": zakat.Distribution,
+			"validation_tx_id":   zakat.ValidationTxID,
+			"souls":              zakat.Souls,
+			"denomination":       denomination,
+			"original_amount":    originalAmount,
+			"exchange_rate":      exchangeRate,
+			"block":              block,
+			"tx_id":              txID,
+			"created_at":         ledgerTime(zakat.Timestamp),
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	// A single-recipient distribution is recorded on the zakat itself; its amount is what the
+	// zakat distributed beyond its shares of program distributions
+	if zakat.DistributionID == "" || zakat.Mustahik == "" {
+		return nil
+	}
+	amount := zakat.Distribution
+	for _, allocation := range zakat.Allocations {
+		amount -= allocation.Amount
+	}
+	if amount <= 0.005 {
+		return nil
+	}
+
+	return tx.Exec(`
+INSERT INTO distributions (id, donation_id, recipient_name, amount, distribution_date, distributed_by, blockchain_tx_id, created_at)
+VALUES (@id, @donation_id, @recipient_name, @amount, @distributed_at, NULLIF(@distributed_by, ''), @tx_id, NOW())
+ON CONFLICT (id) DO NOTHING`,
+		map[string]interface{}{
+			"id":             zakat.DistributionID,
+			"donation_id":    zakat.ID,
+			"recipient_name": zakat.Mustahik,
+			"amount":         amount,
+			"distributed_at": ledgerTime(zakat.DistributedAt),
+			"distributed_by": zakat.DistributedBy,
+			"tx_id":          txID,
+		}).Error
+}
+
+// upsertProgram mirrors a donation program into programs. The ledger does not record a
+// program's organization; programs created elsewhere get the default one.
+func (s *BlockIndexer) upsertProgram(tx *gorm.DB, block uint64, txID string, program ledgerProgram) error {
+	return tx.Exec(`
+INSERT INTO programs (id, name, description, organization, target_amount, collected_amount, distributed_amount,
+    is_active, ledger_block, ledger_tx_id, created_at)
+VALUES (@id, @name, NULLIF(@description, ''), @organization, @target, @collected, @distributed,
+    @active, @block, @tx_id, COALESCE(@created_at, NOW()))
+ON CONFLICT (id) DO UPDATE SET
+    name = EXCLUDED.name,
+    description = EXCLUDED.description,
+    target_amount = EXCLUDED.target_amount,
+    collected_amount = EXCLUDED.collected_amount,
+    distributed_amount = EXCLUDED.distributed_amount,
+    is_active = EXCLUDED.is_active,
+    ledger_block = EXCLUDED.ledger_block,
+    ledger_tx_id = EXCLUDED.ledger_tx_id
+WHERE programs.ledger_block IS NULL OR programs.ledger_block <= EXCLUDED.ledger_block`,
+		map[string]interface{}{
+			"id":           program.ID,
+			"name":         program.Name,
+			"description":  program.Description,
+			"organization": defaultOrganization,
+			"target":       program.Target,
+			"collected":    program.Collected,
+			"distributed":  program.Distributed,
+			"active":       program.Status == "active",
+			"block":        block,
+			"tx_id":        txID,
+			"created_at":   ledgerTime(program.CreatedAt),
+		}).Error
+}
+
+// upsertOfficer mirrors an officer into officers
+func (s *BlockIndexer) upsertOfficer(tx *gorm.DB, block uint64, txID string, officer ledgerOfficer) error {
+	return tx.Exec(`
+INSERT INTO officers (id, name, referral_code, total_referred, commission_rate, status, phone, email,
+    ledger_block, ledger_tx_id, created_at, updated_at)
+VALUES (@id, @name, @referral_code, @total_referred, @commission_rate, @status, NULLIF(@phone, ''), NULLIF(@email, ''),
+    @block, @tx_id, COALESCE(@created_at, NOW()), COALESCE(@updated_at, @created_at, NOW()))
+ON CONFLICT (id) DO UPDATE SET
+    name = EXCLUDED.name,
+    referral_code = EXCLUDED.referral_code,
+    total_referred = EXCLUDED.total_referred,
+    commission_rate = EXCLUDED.commission_rate,
+    status = EXCLUDED.status,
+    phone = EXCLUDED.phone,
+    email = EXCLUDED.email,
+    ledger_block = EXCLUDED.ledger_block,
+    ledger_tx_id = EXCLUDED.ledger_tx_id,
+    updated_at = EXCLUDED.updated_at
+WHERE officers.ledger_block IS NULL OR officers.ledger_block <= EXCLUDED.ledger_block`,
+		map[string]interface{}{
+			"id":              officer.ID,
+			"name":            officer.Name,
+			"referral_code":   officer.ReferralCode,
+			"total_referred":  officer.TotalReferred,
+			"commission_rate": officer.CommissionRate,
+			"status":          officer.Status,
+			"phone":           officer.Phone,
+			"email":           officer.Email,
+			"block":           block,
+			"tx_id":           txID,
+			"created_at":      ledgerTime(officer.CreatedAt),
+			"updated_at":      ledgerTime(officer.UpdatedAt),
+		}).Error
+}
+
+// insertProgramDistribution records a program distribution and its per-donation shares, as
+// DistributionService does. Program distributions are never changed once written; the amounts
+// they add to donations and the program come from those records, which the same ledger
+// transaction rewrites.
+func (s *BlockIndexer) insertProgramDistribution(tx *gorm.DB, txID string, distribution ProgramDistribution) error {
+	distributedAt := ledgerTime(distribution.DistributedAt)
+	err := tx.Exec(`
+INSERT INTO program_distributions (id, program_id, recipient_name, amount, distribution_date, distributed_by, blockchain_tx_id, created_at)
+SELECT @id, @program_id, @recipient_name, @amount, COALESCE(@distributed_at, NOW()), @distributed_by, @tx_id, NOW()
+WHERE EXISTS (SELECT 1 FROM programs WHERE id = @program_id)
+ON CONFLICT (id) DO NOTHING`,
+		map[string]interface{}{
+			"id":             distribution.ID,
+			"program_id":     distribution.ProgramID,
+			"recipient_name": distribution.Mustahik,
+			"amount":         distribution.Amount,
+			"distributed_at": distributedAt,
+			"distributed_by": distribution.DistributedBy,
+			"tx_id":          txID,
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	for i, allocation := range distribution.Allocations {
+		err := tx.Exec(`
+INSERT INTO distributions (id, donation_id, recipient_name, amount, distribution_date, distributed_by,
+    blockchain_tx_id, program_distribution_id, created_at)
+SELECT @id, @donation_id, @recipient_name, @amount, @distributed_at, @distributed_by, @tx_id, @program_distribution_id, NOW()
+WHERE EXISTS (SELECT 1 FROM donations WHERE id = @donation_id)
+    AND EXISTS (SELECT 1 FROM program_distributions WHERE id = @program_distribution_id)
+ON CONFLICT (id) DO NOTHING`,
+			map[string]interface{}{
+				"id":                      fmt.Sprintf("%s-%d", distribution.ID, i+1),
+				"donation_id":             allocation.ZakatID,
+				"recipient_name":          distribution.Mustahik,
+				"amount":                  allocation.Amount,
+				"distributed_at":          distributedAt,
+				"distributed_by":          distribution.DistributedBy,
+				"tx_id":                   txID,
+				"program_distribution_id": distribution.ID,
+			}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ledgerTime parses a ledger timestamp, nil when it is empty or malformed
+func ledgerTime(value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+	return &parsed
+}
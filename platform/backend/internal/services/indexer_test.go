@@ -0,0 +1,160 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/require"
+)
+
+// testEnvelope builds a transaction envelope of the given type writing kvWrites per chaincode namespace
+func testEnvelope(t *testing.T, headerType common.HeaderType, txID string, kvWrites map[string][]*kvrwset.KVWrite) []byte {
+	marshal := func(message proto.Message) []byte {
+		data, err := proto.Marshal(message)
+		require.NoError(t, err)
+		return data
+	}
+
+	txRWSet := &rwset.TxReadWriteSet{DataModel: rwset.TxReadWriteSet_KV}
+	for namespace, writes := range kvWrites {
+		txRWSet.NsRwset = append(txRWSet.NsRwset, &rwset.NsReadWriteSet{
+			Namespace: namespace,
+			Rwset:     marshal(&kvrwset.KVRWSet{Writes: writes}),
+		})
+	}
+	response := &peer.ProposalResponsePayload{Extension: marshal(&peer.ChaincodeAction{Results: marshal(txRWSet)})}
+	actionPayload := &peer.ChaincodeActionPayload{
+		Action: &peer.ChaincodeEndorsedAction{ProposalResponsePayload: marshal(response)},
+	}
+	transaction := &peer.Transaction{Actions: []*peer.TransactionAction{{Payload: marshal(actionPayload)}}}
+
+	payload := &common.Payload{
+		Header: &common.Header{ChannelHeader: marshal(&common.ChannelHeader{Type: int32(headerType), TxId: txID})},
+		Data:   marshal(transaction),
+	}
+	return marshal(&common.Envelope{Payload: marshal(payload)})
+}
+
+// testBlock builds a block of envelopes with their validation codes
+func testBlock(envelopes [][]byte, validation []peer.TxValidationCode) *common.Block {
+	filter := make([]byte, len(validation))
+	for i, code := range validation {
+		filter[i] = byte(code)
+	}
+	metadata := make([][]byte, common.BlockMetadataIndex_TRANSACTIONS_FILTER+1)
+	metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = filter
+	return &common.Block{
+		Data:     &common.BlockData{Data: envelopes},
+		Metadata: &common.BlockMetadata{Metadata: metadata},
+	}
+}
+
+func TestBlockIndexerDecodeBlock(t *testing.T) {
+	indexer := NewBlockIndexer(nil, nil, "zakatchannel", "zakat")
+	zakatJSON := []byte(`{"ID":"ZKT-YDSF-MLG-202503-0001","programID":"PROG-2025-0001"}`)
+	programJSON := []byte(`{"ID":"PROG-2025-0001"}`)
+
+	t.Run("OrdersWritesWithinTransaction", func(t *testing.T) {
+		envelope := testEnvelope(t, common.HeaderType_ENDORSER_TRANSACTION, "tx1", map[string][]*kvrwset.KVWrite{
+			"zakat": {
+				{Key: "REQKEY-6f1c2f7e", Value: []byte(`{}`)},
+				{Key: "ZKT-YDSF-MLG-202503-0001", Value: zakatJSON},
+				{Key: "PROG-2025-0001", Value: programJSON},
+			},
+		})
+
+		writes, err := indexer.decodeBlock(testBlock([][]byte{envelope}, []peer.TxValidationCode{peer.TxValidationCode_VALID}))
+		require.NoError(t, err)
+		require.Equal(t, []ledgerWrite{
+			{TxID: "tx1", Key: "PROG-2025-0001", Value: programJSON},
+			{TxID: "tx1", Key: "ZKT-YDSF-MLG-202503-0001", Value: zakatJSON},
+			{TxID: "tx1", Key: "REQKEY-6f1c2f7e", Value: []byte(`{}`)},
+		}, writes)
+	})
+
+	t.Run("SkipsWhatIsNotIndexed", func(t *testing.T) {
+		envelopes := [][]byte{
+			// Rejected at commit
+			testEnvelope(t, common.HeaderType_ENDORSER_TRANSACTION, "invalid", map[string][]*kvrwset.KVWrite{
+				"zakat": {{Key: "ZKT-YDSF-MLG-202503-0002", Value: zakatJSON}},
+			}),
+			// Other chaincodes and deletes
+			testEnvelope(t, common.HeaderType_ENDORSER_TRANSACTION, "tx2", map[string][]*kvrwset.KVWrite{
+				"_lifecycle": {{Key: "namespaces/fields/zakat/Sequence", Value: []byte{1}}},
+				"zakat": {
+					{Key: "ZKT-YDSF-MLG-202503-0003", IsDelete: true},
+					{Key: "ZKT-YDSF-MLG-202503-0001", Value: zakatJSON},
+				},
+			}),
+			// Channel configuration
+			testEnvelope(t, common.HeaderType_CONFIG, "config", map[string][]*kvrwset.KVWrite{
+				"zakat": {{Key: "ZKT-YDSF-MLG-202503-0004", Value: zakatJSON}},
+			}),
+		}
+		validation := []peer.TxValidationCode{
+			peer.TxValidationCode_MVCC_READ_CONFLICT,
+			peer.TxValidationCode_VALID,
+			peer.TxValidationCode_VALID,
+		}
+
+		writes, err := indexer.decodeBlock(testBlock(envelopes, validation))
+		require.NoError(t, err)
+		require.Equal(t, []ledgerWrite{{TxID: "tx2", Key: "ZKT-YDSF-MLG-202503-0001", Value: zakatJSON}}, writes)
+	})
+
+	t.Run("MalformedEnvelope", func(t *testing.T) {
+		_, err := indexer.decodeBlock(testBlock([][]byte{{0xff, 0xff}}, []peer.TxValidationCode{peer.TxValidationCode_VALID}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "transaction 0: failed to unmarshal envelope")
+	})
+}
+
+func TestWriteOrder(t *testing.T) {
+	testCases := []struct {
+		key      string
+		expected int
+	}{
+		{"PROG-2025-0001", 0},
+		{"OFF-2025-0001", 0},
+		{"ZKT-YDSF-MLG-202503-0001", 1},
+		{"PDIST-1735689000000000000", 2},
+		{"REFCODE-REF001", 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.key, func(t *testing.T) {
+			require.Equal(t, tc.expected, writeOrder(tc.key))
+		})
+	}
+}
+
+func TestLedgerTime(t *testing.T) {
+	expected := time.Date(2025, 3, 1, 9, 30, 0, 0, time.FixedZone("", 7*60*60))
+
+	testCases := []struct {
+		name     string
+		value    string
+		expected *time.Time
+	}{
+		{name: "RFC3339", value: "2025-03-01T09:30:00+07:00", expected: &expected},
+		{name: "Empty", value: ""},
+		{name: "Malformed", value: "01/03/2025"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parsed := ledgerTime(tc.value)
+			if tc.expected == nil {
+				require.Nil(t, parsed)
+				return
+			}
+			require.NotNil(t, parsed)
+			require.True(t, tc.expected.Equal(*parsed))
+		})
+	}
+}
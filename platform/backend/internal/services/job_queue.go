@@ -0,0 +1,351 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/izzuddinafif/fabric/platform/backend/pkg/database"
+)
+
+// Redis keys of the job queue. Job data lives in a hash by job ID; the delayed and running sets
+// are scored by when a job is due and when its lease runs out, and the dead list keeps the IDs
+// of jobs that ran out of attempts.
+const (
+	jobsDataKey    = "jobs:data"
+	jobsDelayedKey = "jobs:delayed"
+	jobsRunningKey = "jobs:running"
+	jobsDeadKey    = "jobs:dead"
+)
+
+// Job states
+const (
+	JobDelayed = "delayed"
+	JobRunning = "running"
+	JobDead    = "dead"
+)
+
+// ErrJobNotFound is returned for job IDs the queue does not know
+var ErrJobNotFound = errors.New("job not found")
+
+// Job is a unit of background work stored in Redis
+type Job struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload"`
+	Attempts    int             `json:"attempts"`
+	LastError   string          `json:"last_error,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	RunAt       time.Time       `json:"run_at"`          // When the job is next due
+	State       string          `json:"state,omitempty"` // Filled in by ListJobs
+	LeaseExpiry time.Time       `json:"lease_expiry,omitempty"`
+}
+
+// JobHandler runs one job. It must be idempotent: a job whose worker died mid-run is run again.
+type JobHandler func(payload json.RawMessage) error
+
+// JobQueueOptions tune retries and leases
+type JobQueueOptions struct {
+	MaxAttempts int           // Attempts before a job is moved to the dead list
+	Backoff     time.Duration // Delay before the first retry; doubles with every attempt
+	MaxBackoff  time.Duration
+	Lease       time.Duration // How long a running job may take before it is considered lost
+}
+
+// JobQueue is a Redis-backed delayed job queue. Jobs survive restarts, failed jobs are retried
+// with exponential backoff and jobs that keep failing end up on a dead-letter list.
+type JobQueue struct {
+	redis    *redis.Client
+	options  JobQueueOptions
+	mu       sync.RWMutex
+	handlers map[string]JobHandler
+}
+
+// NewJobQueue creates a new job queue
+func NewJobQueue(redis *redis.Client, options JobQueueOptions) *JobQueue {
+	return &JobQueue{
+		redis:    redis,
+		options:  options,
+		handlers: make(map[string]JobHandler),
+	}
+}
+
+// claimJobScript moves the next due job from the delayed set to the running set with a lease,
+// first returning jobs whose lease ran out to the delayed set.
+// KEYS: delayed, running. ARGV: now (ms), lease expiry (ms).
+var claimJobScript = redis.NewScript(`
+local expired = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ARGV[1])
+for _, id in ipairs(expired) do
+	redis.call('ZREM', KEYS[2], id)
+	redis.call('ZADD', KEYS[1], ARGV[1], id)
+end
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, 1)
+if #due == 0 then
+	return false
+end
+redis.call('ZREM', KEYS[1], due[1])
+redis.call('ZADD', KEYS[2], ARGV[2], due[1])
+return due[1]
+`)
+
+// Register sets the handler for a job type
+func (q *JobQueue) Register(jobType string, handler JobHandler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[jobType] = handler
+}
+
+// Enqueue schedules a job to run after delay. The ID identifies the work: enqueueing an ID that
+// is already queued, running or dead does nothing, so duplicate triggers run a job once.
+func (q *JobQueue) Enqueue(id, jobType string, payload interface{}, delay time.Duration) error {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job payload: %w", err)
+	}
+
+	now := time.Now()
+	job := Job{
+		ID:        id,
+		Type:      jobType,
+		Payload:   payloadJSON,
+		CreatedAt: now,
+		UpdatedAt: now,
+		RunAt:     now.Add(delay),
+	}
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job: %w", err)
+	}
+
+	created, err := q.redis.HSetNX(database.Ctx, jobsDataKey, id, data).Result()
+	if err != nil {
+		return fmt.Errorf("failed to store job %s: %w", id, err)
+	}
+	if !created {
+		log.Printf("🔁 Job %s is already queued", id)
+		return nil
+	}
+	if err := q.redis.ZAdd(database.Ctx, jobsDelayedKey, &redis.Z{Score: jobScore(job.RunAt), Member: id}).Err(); err != nil {
+		// Drop the data again so that the job can be enqueued once Redis recovers
+		q.redis.HDel(database.Ctx, jobsDataKey, id)
+		return fmt.Errorf("failed to schedule job %s: %w", id, err)
+	}
+
+	log.Printf("🗂️ Queued %s job %s to run at %s", jobType, id, job.RunAt.Format(time.RFC3339))
+	return nil
+}
+
+// Start polls for due jobs every interval
+func (q *JobQueue) Start(interval time.Duration) {
+	log.Printf("🕒 Job worker polling every %v", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			q.RunDue()
+		}
+	}()
+}
+
+// RunDue runs every job that is due, one at a time
+func (q *JobQueue) RunDue() {
+	for {
+		now := time.Now()
+		id, err := claimJobScript.Run(database.Ctx, q.redis,
+			[]string{jobsDelayedKey, jobsRunningKey},
+			now.UnixMilli(), now.Add(q.options.Lease).UnixMilli()).Text()
+		if err == redis.Nil {
+			return
+		}
+		if err != nil {
+			log.Printf("❌ Failed to claim job: %v", err)
+			return
+		}
+		q.run(id)
+	}
+}
+
+func (q *JobQueue) run(id string) {
+	job, err := q.getJob(id)
+	if err != nil {
+		log.Printf("❌ Failed to load job %s: %v", id, err)
+		q.redis.ZRem(database.Ctx, jobsRunningKey, id)
+		return
+	}
+
+	q.mu.RLock()
+	handler, ok := q.handlers[job.Type]
+	q.mu.RUnlock()
+
+	job.Attempts++
+	if !ok {
+		err = fmt.Errorf("no handler registered for job type %s", job.Type)
+	} else {
+		err = handler(job.Payload)
+	}
+
+	if err == nil {
+		pipe := q.redis.TxPipeline()
+		pipe.ZRem(database.Ctx, jobsRunningKey, id)
+		pipe.HDel(database.Ctx, jobsDataKey, id)
+		if _, err := pipe.Exec(database.Ctx); err != nil {
+			log.Printf("❌ Failed to complete job %s: %v", id, err)
+			return
+		}
+		log.Printf("✅ Job %s done after %d attempt(s)", id, job.Attempts)
+		return
+	}
+
+	job.LastError = err.Error()
+	job.UpdatedAt = time.Now()
+	if job.Attempts >= q.options.MaxAttempts {
+		log.Printf("💀 Job %s failed %d times, moving it to the dead list: %v", id, job.Attempts, err)
+		q.save(job, func(pipe redis.Pipeliner) {
+			pipe.LPush(database.Ctx, jobsDeadKey, id)
+		})
+		return
+	}
+
+	job.RunAt = job.UpdatedAt.Add(q.backoff(job.Attempts))
+	log.Printf("⚠️ Job %s failed (attempt %d of %d), retrying at %s: %v",
+		id, job.Attempts, q.options.MaxAttempts, job.RunAt.Format(time.RFC3339), err)
+	q.save(job, func(pipe redis.Pipeliner) {
+		pipe.ZAdd(database.Ctx, jobsDelayedKey, &redis.Z{Score: jobScore(job.RunAt), Member: id})
+	})
+}
+
+// save stores a job that left the running set, together with where it goes next
+func (q *JobQueue) save(job *Job, next func(pipe redis.Pipeliner)) {
+	data, err := json.Marshal(job)
+	if err != nil {
+		log.Printf("❌ Failed to marshal job %s: %v", job.ID, err)
+		return
+	}
+
+	pipe := q.redis.TxPipeline()
+	pipe.HSet(database.Ctx, jobsDataKey, job.ID, data)
+	pipe.ZRem(database.Ctx, jobsRunningKey, job.ID)
+	next(pipe)
+	if _, err := pipe.Exec(database.Ctx); err != nil {
+		log.Printf("❌ Failed to save job %s: %v", job.ID, err)
+	}
+}
+
+// backoff is Backoff doubled for every attempt after the first, capped at MaxBackoff
+func (q *JobQueue) backoff(attempts int) time.Duration {
+	backoff := float64(q.options.Backoff) * math.Pow(2, float64(attempts-1))
+	if backoff > float64(q.options.MaxBackoff) {
+		return q.options.MaxBackoff
+	}
+	return time.Duration(backoff)
+}
+
+// ListJobs returns the jobs in a state (delayed, running or dead), due or failed first
+func (q *JobQueue) ListJobs(state string) ([]Job, error) {
+	var ids []string
+	scores := map[string]float64{}
+	switch state {
+	case JobDelayed, JobRunning:
+		key := jobsDelayedKey
+		if state == JobRunning {
+			key = jobsRunningKey
+		}
+		entries, err := q.redis.ZRangeWithScores(database.Ctx, key, 0, -1).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s jobs: %w", state, err)
+		}
+		for _, entry := range entries {
+			id := entry.Member.(string)
+			ids = append(ids, id)
+			scores[id] = entry.Score
+		}
+	case JobDead:
+		var err error
+		if ids, err = q.redis.LRange(database.Ctx, jobsDeadKey, 0, -1).Result(); err != nil {
+			return nil, fmt.Errorf("failed to list dead jobs: %w", err)
+		}
+	default:
+		return nil, newInvalidInputError("state", "state must be one of: delayed, running, dead")
+	}
+
+	jobs := []Job{}
+	for _, id := range ids {
+		job, err := q.getJob(id)
+		if err != nil {
+			log.Printf("❌ Failed to load job %s: %v", id, err)
+			continue
+		}
+		job.State = state
+		if state == JobRunning {
+			job.LeaseExpiry = time.UnixMilli(int64(scores[id]))
+		}
+		jobs = append(jobs, *job)
+	}
+	if state == JobDead {
+		sort.SliceStable(jobs, func(i, j int) bool { return jobs[i].UpdatedAt.Before(jobs[j].UpdatedAt) })
+	}
+	return jobs, nil
+}
+
+// RetryDead moves a dead job back to the queue with fresh attempts, to run now
+func (q *JobQueue) RetryDead(id string) (*Job, error) {
+	removed, err := q.redis.LRem(database.Ctx, jobsDeadKey, 0, id).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove job %s from the dead list: %w", id, err)
+	}
+	if removed == 0 {
+		return nil, newNotFoundError("dead job %s not found", id)
+	}
+
+	job, err := q.getJob(id)
+	if err != nil {
+		return nil, err
+	}
+	job.Attempts = 0
+	job.RunAt = time.Now()
+	job.UpdatedAt = job.RunAt
+	job.State = JobDelayed
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal job: %w", err)
+	}
+	pipe := q.redis.TxPipeline()
+	pipe.HSet(database.Ctx, jobsDataKey, id, data)
+	pipe.ZAdd(database.Ctx, jobsDelayedKey, &redis.Z{Score: jobScore(job.RunAt), Member: id})
+	if _, err := pipe.Exec(database.Ctx); err != nil {
+		return nil, fmt.Errorf("failed to requeue job %s: %w", id, err)
+	}
+
+	log.Printf("🔁 Requeued dead job %s", id)
+	return job, nil
+}
+
+func (q *JobQueue) getJob(id string) (*Job, error) {
+	data, err := q.redis.HGet(database.Ctx, jobsDataKey, id).Bytes()
+	if err == redis.Nil {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job %s: %w", id, err)
+	}
+
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal job %s: %w", id, err)
+	}
+	return &job, nil
+}
+
+// jobScore is a time as a sorted set score, in Unix milliseconds
+func jobScore(t time.Time) float64 {
+	return float64(t.UnixMilli())
+}
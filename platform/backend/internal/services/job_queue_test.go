@@ -0,0 +1,31 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobQueueBackoff(t *testing.T) {
+	queue := NewJobQueue(nil, JobQueueOptions{Backoff: 5 * time.Second, MaxBackoff: time.Minute})
+
+	testCases := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{1, 5 * time.Second},
+		{2, 10 * time.Second},
+		{3, 20 * time.Second},
+		{4, 40 * time.Second},
+		{5, time.Minute}, // 80s, capped
+		{30, time.Minute},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("Attempt%d", tc.attempts), func(t *testing.T) {
+			require.Equal(t, tc.expected, queue.backoff(tc.attempts))
+		})
+	}
+}
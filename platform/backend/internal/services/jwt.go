@@ -9,15 +9,17 @@ import (
 
 // JWTService handles JWT token operations
 type JWTService struct {
-	secret []byte
-	expiry time.Duration
+	secret      []byte
+	expiry      time.Duration
+	donorExpiry time.Duration
 }
 
 // NewJWTService creates a new JWT service
-func NewJWTService(secret string, expiry time.Duration) *JWTService {
+func NewJWTService(secret string, expiry, donorExpiry time.Duration) *JWTService {
 	return &JWTService{
-		secret: []byte(secret),
-		expiry: expiry,
+		secret:      []byte(secret),
+		expiry:      expiry,
+		donorExpiry: donorExpiry,
 	}
 }
 
@@ -30,11 +32,21 @@ type Claims struct {
 
 // GenerateToken generates a JWT token
 func (s *JWTService) GenerateToken(userID, role string) (string, error) {
+	return s.generate(userID, role, s.expiry)
+}
+
+// GenerateDonorToken generates a token for a donor signed in with a one-time code. Its donor
+// role is not accepted by any admin endpoint.
+func (s *JWTService) GenerateDonorToken(userID string) (string, error) {
+	return s.generate(userID, "donor", s.donorExpiry)
+}
+
+func (s *JWTService) generate(userID, role string, expiry time.Duration) (string, error) {
 	claims := &Claims{
 		UserID: userID,
 		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.expiry)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
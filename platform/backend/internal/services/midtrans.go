@@ -0,0 +1,198 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/izzuddinafif/fabric/platform/backend/internal/models"
+)
+
+// MidtransProvider is a PaymentProvider for Midtrans: charges are opened through Snap, statuses
+// come from the Core API and notifications are signed with the merchant's server key.
+// cmd/mockpayment serves the same API for local development.
+type MidtransProvider struct {
+	serverKey string
+	apiURL    string
+	snapURL   string
+	client    *http.Client
+}
+
+// NewMidtransProvider creates a Midtrans provider for the Core API at apiURL and Snap at snapURL
+func NewMidtransProvider(serverKey, apiURL, snapURL string, timeout time.Duration) *MidtransProvider {
+	return &MidtransProvider{
+		serverKey: serverKey,
+		apiURL:    strings.TrimSuffix(apiURL, "/"),
+		snapURL:   strings.TrimSuffix(snapURL, "/"),
+		client:    &http.Client{Timeout: timeout},
+	}
+}
+
+// snapPayments limits a Snap charge to the channels of the donation's payment method
+var snapPayments = map[string][]string{
+	"transfer": {"bca_va", "bni_va", "bri_va", "permata_va", "echannel", "other_va"},
+	"ewallet":  {"gopay", "shopeepay"},
+}
+
+// MidtransNotification is the body of Midtrans payment notifications and status responses
+type MidtransNotification struct {
+	OrderID           string `json:"order_id"`
+	TransactionID     string `json:"transaction_id"`
+	TransactionStatus string `json:"transaction_status"` // capture, settlement, pending, deny, cancel, expire, failure
+	FraudStatus       string `json:"fraud_status,omitempty"`
+	StatusCode        string `json:"status_code"`
+	GrossAmount       string `json:"gross_amount"` // e.g. "150000.00"
+	PaymentType       string `json:"payment_type"`
+	SignatureKey      string `json:"signature_key"`
+}
+
+// MidtransSignature signs a notification: SHA-512 of order ID, status code, gross amount and server key
+func MidtransSignature(orderID, statusCode, grossAmount, serverKey string) string {
+	sum := sha512.Sum512([]byte(orderID + statusCode + grossAmount + serverKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// Name identifies Midtrans in donation records
+func (p *MidtransProvider) Name() string {
+	return "midtrans"
+}
+
+// CreateCharge opens a Snap transaction for the donation
+func (p *MidtransProvider) CreateCharge(donation *models.Donation) (*PaymentCharge, error) {
+	customer := map[string]interface{}{
+		"first_name": donation.DonorName,
+		"phone":      donation.DonorPhone,
+	}
+	if donation.DonorEmail.Valid {
+		customer["email"] = donation.DonorEmail.String
+	}
+	body := map[string]interface{}{
+		"transaction_details": map[string]interface{}{
+			"order_id":     donation.ID,
+			"gross_amount": int64(math.Round(donation.Amount)), // Whole rupiah
+		},
+		"customer_details": customer,
+	}
+	if payments, ok := snapPayments[donation.PaymentMethod]; ok {
+		body["enabled_payments"] = payments
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal charge: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.snapURL+"/snap/v1/transactions", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create charge request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var charge struct {
+		Token       string `json:"token"`
+		RedirectURL string `json:"redirect_url"`
+	}
+	if err := p.do(req, &charge); err != nil {
+		return nil, err
+	}
+	return &PaymentCharge{Token: charge.Token, RedirectURL: charge.RedirectURL}, nil
+}
+
+// GetStatus queries the Core API for the status of an order
+func (p *MidtransProvider) GetStatus(orderID string) (*PaymentNotification, error) {
+	req, err := http.NewRequest(http.MethodGet, p.apiURL+"/v2/"+url.PathEscape(orderID)+"/status", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create status request: %w", err)
+	}
+
+	var status MidtransNotification
+	if err := p.do(req, &status); err != nil {
+		return nil, err
+	}
+	if status.StatusCode == "404" {
+		return nil, newNotFoundError("no payment found for order %s", orderID)
+	}
+	return p.verify(status)
+}
+
+// ParseWebhook verifies and decodes an HTTP notification
+func (p *MidtransProvider) ParseWebhook(body []byte, header http.Header) (*PaymentNotification, error) {
+	var notification MidtransNotification
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return nil, fmt.Errorf("failed to decode payment notification: %w", err)
+	}
+	return p.verify(notification)
+}
+
+func (p *MidtransProvider) verify(notification MidtransNotification) (*PaymentNotification, error) {
+	expected := MidtransSignature(notification.OrderID, notification.StatusCode, notification.GrossAmount, p.serverKey)
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(strings.ToLower(notification.SignatureKey))) != 1 {
+		return nil, ErrInvalidSignature
+	}
+
+	amount, err := strconv.ParseFloat(notification.GrossAmount, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gross amount %q: %w", notification.GrossAmount, err)
+	}
+
+	return &PaymentNotification{
+		OrderID:   notification.OrderID,
+		Reference: notification.TransactionID,
+		Status:    midtransStatus(notification.TransactionStatus, notification.FraudStatus),
+		Amount:    amount,
+		Method:    notification.PaymentType,
+	}, nil
+}
+
+// midtransStatus maps a Midtrans transaction status to a payment status. Card captures only
+// count as paid once the fraud check accepts them.
+func midtransStatus(transactionStatus, fraudStatus string) string {
+	switch transactionStatus {
+	case "settlement":
+		return PaymentPaid
+	case "capture":
+		if fraudStatus == "" || fraudStatus == "accept" {
+			return PaymentPaid
+		}
+		return PaymentPending
+	case "expire":
+		return PaymentExpired
+	case "deny", "cancel", "failure":
+		return PaymentFailed
+	default:
+		return PaymentPending
+	}
+}
+
+// do sends an authenticated API request and decodes its JSON response into out
+func (p *MidtransProvider) do(req *http.Request, out interface{}) error {
+	req.SetBasicAuth(p.serverKey, "")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("midtrans request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read midtrans response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("midtrans returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode midtrans response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testServerKey = "SB-Mid-server-key"
+
+func TestMidtransSignature(t *testing.T) {
+	// sha512("ZKT-YDSF-MLG-202503-0001" + "200" + "150000.00" + "SB-Mid-server-key")
+	const expected = "0e045227149d4929c5380780e66d037ac3d390cfd3df43381f3a7472da59c73c115d5e0c471dbe514c450009eced5087297f67f255bce9a5df57915347460989"
+	require.Equal(t, expected, MidtransSignature("ZKT-YDSF-MLG-202503-0001", "200", "150000.00", testServerKey))
+}
+
+func TestMidtransParseWebhook(t *testing.T) {
+	signed := func(notification MidtransNotification) MidtransNotification {
+		notification.SignatureKey = MidtransSignature(notification.OrderID, notification.StatusCode, notification.GrossAmount, testServerKey)
+		return notification
+	}
+	settlement := MidtransNotification{
+		OrderID:           "ZKT-YDSF-MLG-202503-0001",
+		TransactionID:     "9aed5972-5b6a-401e-894b-a32c91ed1a3a",
+		TransactionStatus: "settlement",
+		StatusCode:        "200",
+		GrossAmount:       "150000.00",
+		PaymentType:       "bank_transfer",
+	}
+
+	testCases := []struct {
+		name         string
+		notification MidtransNotification
+		modify       func(n *MidtransNotification)
+		expectedErr  error
+		expected     *PaymentNotification
+	}{
+		{
+			name:         "Valid",
+			notification: signed(settlement),
+			expected: &PaymentNotification{
+				OrderID:   settlement.OrderID,
+				Reference: settlement.TransactionID,
+				Status:    PaymentPaid,
+				Amount:    150000,
+				Method:    "bank_transfer",
+			},
+		},
+		{
+			name:         "UppercaseSignature",
+			notification: signed(settlement),
+			modify:       func(n *MidtransNotification) { n.SignatureKey = strings.ToUpper(n.SignatureKey) },
+			expected: &PaymentNotification{
+				OrderID:   settlement.OrderID,
+				Reference: settlement.TransactionID,
+				Status:    PaymentPaid,
+				Amount:    150000,
+				Method:    "bank_transfer",
+			},
+		},
+		{
+			name:         "TamperedAmount",
+			notification: signed(settlement),
+			modify:       func(n *MidtransNotification) { n.GrossAmount = "1500000.00" },
+			expectedErr:  ErrInvalidSignature,
+		},
+		{
+			name:         "TamperedOrder",
+			notification: signed(settlement),
+			modify:       func(n *MidtransNotification) { n.OrderID = "ZKT-YDSF-MLG-202503-0002" },
+			expectedErr:  ErrInvalidSignature,
+		},
+		{
+			name:         "WrongServerKey",
+			notification: settlement,
+			modify: func(n *MidtransNotification) {
+				n.SignatureKey = MidtransSignature(n.OrderID, n.StatusCode, n.GrossAmount, "another-key")
+			},
+			expectedErr: ErrInvalidSignature,
+		},
+		{
+			name:         "Unsigned",
+			notification: settlement,
+			expectedErr:  ErrInvalidSignature,
+		},
+	}
+
+	provider := NewMidtransProvider(testServerKey, "http://localhost:3010", "http://localhost:3010", 0)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notification := tc.notification
+			if tc.modify != nil {
+				tc.modify(&notification)
+			}
+			body, err := json.Marshal(notification)
+			require.NoError(t, err)
+
+			parsed, err := provider.ParseWebhook(body, nil)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, parsed)
+		})
+	}
+
+	t.Run("MalformedBody", func(t *testing.T) {
+		_, err := provider.ParseWebhook([]byte("{"), nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to decode payment notification")
+	})
+}
+
+func TestMidtransStatus(t *testing.T) {
+	testCases := []struct {
+		transactionStatus string
+		fraudStatus       string
+		expected          string
+	}{
+		{"settlement", "", PaymentPaid},
+		{"capture", "", PaymentPaid},
+		{"capture", "accept", PaymentPaid},
+		{"capture", "challenge", PaymentPending},
+		{"pending", "", PaymentPending},
+		{"expire", "", PaymentExpired},
+		{"deny", "", PaymentFailed},
+		{"cancel", "", PaymentFailed},
+		{"failure", "", PaymentFailed},
+		{"refund", "", PaymentPending},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.transactionStatus+"/"+tc.fraudStatus, func(t *testing.T) {
+			require.Equal(t, tc.expected, midtransStatus(tc.transactionStatus, tc.fraudStatus))
+		})
+	}
+}
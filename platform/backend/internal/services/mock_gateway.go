@@ -0,0 +1,190 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MockGateway is a local stand-in for Midtrans. It accepts Snap charges, settles each one after
+// a delay and then posts a signed notification to the backend's webhook, like the real gateway.
+// It also simulates e-wallets paying dynamic QRIS codes.
+type MockGateway struct {
+	serverKey       string
+	publicURL       string
+	notificationURL string
+	delay           time.Duration
+	client          *http.Client
+
+	mu     sync.Mutex
+	orders map[string]*MidtransNotification
+}
+
+// NewMockGateway creates a mock gateway reachable at publicURL that settles charges after delay
+// and notifies notificationURL, signing with serverKey
+func NewMockGateway(serverKey, publicURL, notificationURL string, delay time.Duration) *MockGateway {
+	return &MockGateway{
+		serverKey:       serverKey,
+		publicURL:       publicURL,
+		notificationURL: notificationURL,
+		delay:           delay,
+		client:          &http.Client{Timeout: 10 * time.Second},
+		orders:          make(map[string]*MidtransNotification),
+	}
+}
+
+// Handler serves the Snap charge and Core API status endpoints, and POST /qris/pay to pay a
+// dynamic QRIS payload ({"payload": "000201..."})
+func (g *MockGateway) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /snap/v1/transactions", g.handleCharge)
+	mux.HandleFunc("GET /v2/{orderID}/status", g.handleStatus)
+	mux.HandleFunc("POST /qris/pay", g.handleQRISPay)
+	mux.HandleFunc("GET /pay/{token}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "Mock payment %s will settle automatically within %s\n", r.PathValue("token"), g.delay)
+	})
+	return mux
+}
+
+func (g *MockGateway) handleCharge(w http.ResponseWriter, r *http.Request) {
+	if user, _, ok := r.BasicAuth(); !ok || user != g.serverKey {
+		writeMidtransJSON(w, http.StatusUnauthorized, map[string]interface{}{"error_messages": []string{"Access denied"}})
+		return
+	}
+
+	var charge struct {
+		TransactionDetails struct {
+			OrderID     string `json:"order_id"`
+			GrossAmount int64  `json:"gross_amount"`
+		} `json:"transaction_details"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&charge); err != nil || charge.TransactionDetails.OrderID == "" {
+		writeMidtransJSON(w, http.StatusBadRequest, map[string]interface{}{"error_messages": []string{"transaction_details.order_id is required"}})
+		return
+	}
+	orderID := charge.TransactionDetails.OrderID
+
+	g.mu.Lock()
+	if _, exists := g.orders[orderID]; exists {
+		g.mu.Unlock()
+		writeMidtransJSON(w, http.StatusBadRequest, map[string]interface{}{"error_messages": []string{"transaction_details.order_id sudah digunakan"}})
+		return
+	}
+	order := &MidtransNotification{
+		OrderID:           orderID,
+		TransactionID:     "MOCK-" + uuid.New().String(),
+		TransactionStatus: "pending",
+		StatusCode:        "201",
+		GrossAmount:       strconv.FormatInt(charge.TransactionDetails.GrossAmount, 10) + ".00",
+		PaymentType:       "bank_transfer",
+	}
+	g.orders[orderID] = order
+	g.mu.Unlock()
+
+	token := uuid.New().String()
+	log.Printf("💳 Mock charge %s opened for order %s (Rp %s)", token, orderID, order.GrossAmount)
+	time.AfterFunc(g.delay, func() { g.settle(orderID) })
+
+	writeMidtransJSON(w, http.StatusCreated, map[string]string{
+		"token":        token,
+		"redirect_url": g.publicURL + "/pay/" + token,
+	})
+}
+
+func (g *MockGateway) handleStatus(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	order, ok := g.orders[r.PathValue("orderID")]
+	var status MidtransNotification
+	if ok {
+		status = g.signed(*order)
+	}
+	g.mu.Unlock()
+
+	if !ok {
+		writeMidtransJSON(w, http.StatusOK, map[string]string{"status_code": "404", "status_message": "Transaction doesn't exist."})
+		return
+	}
+	writeMidtransJSON(w, http.StatusOK, status)
+}
+
+// handleQRISPay pays a dynamic QRIS payload the way an e-wallet would: the payload's CRC is
+// checked, and its amount is settled at once under its reference label as the order ID
+func (g *MockGateway) handleQRISPay(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Payload string `json:"payload"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeMidtransJSON(w, http.StatusBadRequest, map[string]interface{}{"error_messages": []string{"payload is required"}})
+		return
+	}
+	payment, err := ParseQRISPayment(req.Payload)
+	if err != nil {
+		writeMidtransJSON(w, http.StatusBadRequest, map[string]interface{}{"error_messages": []string{err.Error()}})
+		return
+	}
+
+	g.mu.Lock()
+	if order, exists := g.orders[payment.Reference]; exists && order.TransactionStatus == "settlement" {
+		g.mu.Unlock()
+		writeMidtransJSON(w, http.StatusConflict, map[string]interface{}{"error_messages": []string{"QRIS already paid"}})
+		return
+	}
+	g.orders[payment.Reference] = &MidtransNotification{
+		OrderID:           payment.Reference,
+		TransactionID:     "MOCK-QRIS-" + uuid.New().String(),
+		TransactionStatus: "pending",
+		GrossAmount:       strconv.FormatFloat(payment.Amount, 'f', 2, 64),
+		PaymentType:       "qris",
+	}
+	g.mu.Unlock()
+
+	log.Printf("💳 Mock e-wallet paid QRIS of %s (Rp %.0f to %s)", payment.Reference, payment.Amount, payment.MerchantName)
+	notification, webhookStatus := g.settle(payment.Reference)
+	writeMidtransJSON(w, http.StatusOK, map[string]interface{}{
+		"transaction":    notification,
+		"webhook_status": webhookStatus,
+	})
+}
+
+// settle marks an order paid and notifies the backend, returning the notification and the
+// webhook's response status
+func (g *MockGateway) settle(orderID string) (MidtransNotification, string) {
+	g.mu.Lock()
+	order := g.orders[orderID]
+	order.TransactionStatus = "settlement"
+	order.StatusCode = "200"
+	notification := g.signed(*order)
+	g.mu.Unlock()
+
+	body, err := json.Marshal(notification)
+	if err != nil {
+		log.Printf("❌ Failed to marshal mock notification for %s: %v", orderID, err)
+		return notification, ""
+	}
+	resp, err := g.client.Post(g.notificationURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Printf("❌ Failed to notify settlement of %s: %v", orderID, err)
+		return notification, ""
+	}
+	resp.Body.Close()
+	log.Printf("✅ Mock payment %s settled, webhook answered %s", orderID, resp.Status)
+	return notification, resp.Status
+}
+
+func (g *MockGateway) signed(notification MidtransNotification) MidtransNotification {
+	notification.SignatureKey = MidtransSignature(notification.OrderID, notification.StatusCode, notification.GrossAmount, g.serverKey)
+	return notification
+}
+
+func writeMidtransJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/models"
+)
+
+// Network health states reported on the admin dashboard
+const (
+	NetworkHealthy = "healthy"
+	NetworkWarning = "warning"
+	NetworkError   = "error"
+)
+
+// NetworkService reports ledger and node health: chain info from the query system chaincode
+// (qscc) and node health from the peers' and orderers' operations endpoints (/healthz)
+type NetworkService struct {
+	qscc      *gateway.Contract
+	contract  *gateway.Contract
+	channel   string
+	endpoints []string
+	client    *http.Client
+}
+
+// NewNetworkService creates a new network service. qscc is the query system chaincode and
+// contract the zakat chaincode on channel; endpoints are operations base URLs.
+func NewNetworkService(qscc, contract *gateway.Contract, channel string, endpoints []string, timeout time.Duration) *NetworkService {
+	return &NetworkService{
+		qscc:      qscc,
+		contract:  contract,
+		channel:   channel,
+		endpoints: endpoints,
+		client:    &http.Client{Timeout: timeout},
+	}
+}
+
+// GetChainInfo returns the channel's block height and current block hash
+func (s *NetworkService) GetChainInfo() (*models.ChainInfo, error) {
+	result, err := s.qscc.EvaluateTransaction("GetChainInfo", s.channel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query chain info of %s: %w", s.channel, err)
+	}
+
+	var info common.BlockchainInfo
+	if err := proto.Unmarshal(result, &info); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal chain info of %s: %w", s.channel, err)
+	}
+
+	return &models.ChainInfo{
+		Channel:           s.channel,
+		Height:            info.Height,
+		CurrentBlockHash:  hex.EncodeToString(info.CurrentBlockHash),
+		PreviousBlockHash: hex.EncodeToString(info.PreviousBlockHash),
+	}, nil
+}
+
+// ChaincodeReady reports whether the zakat chaincode answers queries. Every contract API
+// chaincode serves its metadata, so this needs no ledger data.
+func (s *NetworkService) ChaincodeReady() bool {
+	_, err := s.contract.EvaluateTransaction("org.hyperledger.fabric:GetMetadata")
+	return err == nil
+}
+
+// CheckNodes queries every operations endpoint's /healthz concurrently
+func (s *NetworkService) CheckNodes() []models.NodeHealth {
+	nodes := make([]models.NodeHealth, len(s.endpoints))
+	var wg sync.WaitGroup
+	for i, endpoint := range s.endpoints {
+		wg.Add(1)
+		go func(i int, endpoint string) {
+			defer wg.Done()
+			nodes[i] = s.checkNode(endpoint)
+		}(i, endpoint)
+	}
+	wg.Wait()
+	return nodes
+}
+
+func (s *NetworkService) checkNode(endpoint string) models.NodeHealth {
+	node := models.NodeHealth{Endpoint: endpoint, Status: NetworkError}
+
+	resp, err := s.client.Get(strings.TrimSuffix(endpoint, "/") + "/healthz")
+	if err != nil {
+		node.Error = err.Error()
+		return node
+	}
+	defer resp.Body.Close()
+
+	// /healthz answers 200 {"status":"OK"} or 503 with the failed checks
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		node.Error = fmt.Sprintf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+		return node
+	}
+
+	node.Status = NetworkHealthy
+	return node
+}
+
+// OverallHealth combines node and ledger health: error when the ledger cannot be queried or
+// no node is healthy, warning when some node is not
+func OverallHealth(nodes []models.NodeHealth, ledgerOK bool) string {
+	if !ledgerOK {
+		return NetworkError
+	}
+
+	healthy := 0
+	for _, node := range nodes {
+		if node.Status == NetworkHealthy {
+			healthy++
+		}
+	}
+	switch {
+	case len(nodes) > 0 && healthy == 0:
+		return NetworkError
+	case healthy < len(nodes):
+		return NetworkWarning
+	default:
+		return NetworkHealthy
+	}
+}
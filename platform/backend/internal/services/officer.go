@@ -0,0 +1,62 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// defaultOrganization is used for officers whose user record has no organization
+const defaultOrganization = "YDSF Malang"
+
+// OfficerService serves officer referral analytics from the ledger
+type OfficerService struct {
+	fabricService *FabricService
+	userService   *UserService
+}
+
+// NewOfficerService creates a new officer service
+func NewOfficerService(fabricService *FabricService, userService *UserService) *OfficerService {
+	return &OfficerService{
+		fabricService: fabricService,
+		userService:   userService,
+	}
+}
+
+// GetLeaderboard ranks an organization's officers by the referrals they brought in
+// between startDate and endDate (YYYY-MM-DD, both inclusive)
+func (s *OfficerService) GetLeaderboard(organization, startDate, endDate string) ([]OfficerPerformance, error) {
+	return s.fabricService.GetOfficerLeaderboard(organization, startDate, endDate)
+}
+
+// GetPerformance returns one officer's referral performance and rank within an organization
+func (s *OfficerService) GetPerformance(officerID, organization, startDate, endDate string) (*OfficerPerformance, error) {
+	return s.fabricService.GetOfficerPerformance(officerID, organization, startDate, endDate)
+}
+
+// GetUserPerformance returns the performance of the officer a user account belongs to, in the
+// user's own organization. The ledger officer is found through the user's referral code.
+func (s *OfficerService) GetUserPerformance(userID, startDate, endDate string) (*OfficerPerformance, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID %s: %w", userID, err)
+	}
+	user, err := s.userService.GetUserByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !user.ReferralCode.Valid || user.ReferralCode.String == "" {
+		return nil, newNotFoundError("user %s has no officer referral code", userID)
+	}
+
+	officer, err := s.fabricService.GetOfficerByReferral(user.ReferralCode.String)
+	if err != nil {
+		return nil, err
+	}
+
+	organization := defaultOrganization
+	if user.Organization.Valid && user.Organization.String != "" {
+		organization = user.Organization.String
+	}
+	return s.fabricService.GetOfficerPerformance(officer.ID, organization, startDate, endDate)
+}
@@ -0,0 +1,54 @@
+package services
+
+// Organization is a branch donations can be made to, with the payment methods it accepts.
+// Names are the organizations the chaincode accepts.
+type Organization struct {
+	Name           string   `json:"name"`
+	Code           string   `json:"code"`            // Used in zakat IDs
+	PaymentMethods []string `json:"payment_methods"` // The first is the default
+}
+
+// organizations lists the branches that take donations. qris donations get a dynamic QRIS code,
+// transfer and ewallet a gateway charge; cash is paid at the branch office and validated by an
+// admin.
+var organizations = []Organization{
+	{Name: "YDSF Malang", Code: "MLG", PaymentMethods: []string{"qris", "transfer", "ewallet", "cash"}},
+	{Name: "YDSF Jatim", Code: "JTM", PaymentMethods: []string{"qris", "transfer", "ewallet"}},
+}
+
+// Organizations returns the branches that take donations
+func Organizations() []Organization {
+	return organizations
+}
+
+// findOrganization returns the organization with a name, or nil
+func findOrganization(name string) *Organization {
+	for i := range organizations {
+		if organizations[i].Name == name {
+			return &organizations[i]
+		}
+	}
+	return nil
+}
+
+// resolvePaymentOptions fills in the default organization and payment method and checks that
+// the organization accepts the method
+func resolvePaymentOptions(organization, paymentMethod string) (string, string, error) {
+	if organization == "" {
+		organization = defaultOrganization
+	}
+	org := findOrganization(organization)
+	if org == nil {
+		return "", "", newInvalidInputError("organization", "unknown organization %q", organization)
+	}
+
+	if paymentMethod == "" {
+		return org.Name, org.PaymentMethods[0], nil
+	}
+	for _, method := range org.PaymentMethods {
+		if method == paymentMethod {
+			return org.Name, paymentMethod, nil
+		}
+	}
+	return "", "", newInvalidInputError("payment_method", "%s does not accept payment method %q", org.Name, paymentMethod)
+}
@@ -0,0 +1,283 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/models"
+	"github.com/izzuddinafif/fabric/platform/backend/pkg/database"
+)
+
+var (
+	// ErrOTPCooldown is returned when a code is requested again before the resend cooldown ends
+	ErrOTPCooldown = errors.New("a code was sent recently; wait before requesting another")
+	// ErrOTPInvalid is returned for a wrong, expired or already used code
+	ErrOTPInvalid = errors.New("invalid or expired code")
+	// ErrOTPAttemptsExceeded is returned once a code was entered wrongly too often; it is discarded
+	ErrOTPAttemptsExceeded = errors.New("too many incorrect codes; request a new one")
+)
+
+// OTPCooldownError is ErrOTPCooldown with the time left until a code can be sent again
+type OTPCooldownError struct {
+	RetryAfter time.Duration
+}
+
+func (e *OTPCooldownError) Error() string {
+	return ErrOTPCooldown.Error()
+}
+
+func (e *OTPCooldownError) Unwrap() error {
+	return ErrOTPCooldown
+}
+
+// otpCodeLength is the number of digits in a code
+const otpCodeLength = 6
+
+var (
+	phonePattern = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+	emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+)
+
+// otpAttempt counts an attempt at a code, returning -1 when there is no code. A plain HINCRBY
+// would recreate an expired code's hash without a TTL.
+var otpAttempt = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+    return -1
+end
+return redis.call('HINCRBY', KEYS[1], 'attempts', 1)`)
+
+// OTPOptions tune one-time login codes
+type OTPOptions struct {
+	TTL            time.Duration // How long a code is valid
+	MaxAttempts    int           // Wrong entries before a code is discarded
+	ResendCooldown time.Duration // Minimum time between codes sent to one phone or email
+}
+
+// OTPDelivery describes where a code was sent
+type OTPDelivery struct {
+	Channel     string `json:"channel"`
+	Destination string `json:"destination"` // Masked
+	ExpiresIn   int    `json:"expires_in"`  // Seconds
+}
+
+// OTPService signs donors in with one-time codes sent to their phone or email. Only a hash of
+// each code is kept, in Redis under otp:{phone|email} for the code's TTL, together with the
+// number of attempts at it.
+type OTPService struct {
+	redis       *redis.Client
+	userService *UserService
+	options     OTPOptions
+	senders     map[string]OTPSender
+}
+
+// NewOTPService creates a new OTP service
+func NewOTPService(redis *redis.Client, userService *UserService, options OTPOptions) *OTPService {
+	return &OTPService{
+		redis:       redis,
+		userService: userService,
+		options:     options,
+		senders:     make(map[string]OTPSender),
+	}
+}
+
+// SetSender sets the sender delivering codes over a channel (sms, whatsapp or email)
+func (s *OTPService) SetSender(channel string, sender OTPSender) {
+	s.senders[channel] = sender
+}
+
+// SendCode sends a new code to a phone (over sms or whatsapp, the default) or to an email
+// address. Exactly one of phone and email is given.
+func (s *OTPService) SendCode(phone, email, channel string) (*OTPDelivery, error) {
+	destination, channel, err := otpDestination(phone, email, channel)
+	if err != nil {
+		return nil, err
+	}
+	sender, ok := s.senders[channel]
+	if !ok {
+		return nil, newInvalidInputError("channel", "codes cannot be sent by %s", channel)
+	}
+
+	cooldownKey := "otp_cooldown:" + destination
+	acquired, err := s.redis.SetNX(database.Ctx, cooldownKey, 1, s.options.ResendCooldown).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check OTP cooldown: %w", err)
+	}
+	if !acquired {
+		retryAfter, err := s.redis.TTL(database.Ctx, cooldownKey).Result()
+		if err != nil || retryAfter < 0 {
+			retryAfter = s.options.ResendCooldown
+		}
+		return nil, &OTPCooldownError{RetryAfter: retryAfter}
+	}
+
+	code, err := newOTPCode()
+	if err != nil {
+		return nil, err
+	}
+	key := "otp:" + destination
+	_, err = s.redis.TxPipelined(database.Ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(database.Ctx, key)
+		pipe.HSet(database.Ctx, key, "code_hash", otpHash(destination, code), "attempts", 0)
+		pipe.Expire(database.Ctx, key, s.options.TTL)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store OTP: %w", err)
+	}
+
+	message := fmt.Sprintf("Kode masuk YDSF Anda: %s. Berlaku %d menit. Jangan berikan kode ini kepada siapa pun.",
+		code, int(s.options.TTL.Minutes()))
+	if err := sender.Send(channel, destination, message); err != nil {
+		// Let the donor ask again right away
+		s.redis.Del(database.Ctx, key, cooldownKey)
+		return nil, fmt.Errorf("failed to send OTP: %w", err)
+	}
+
+	log.Printf("🔑 Sent login code by %s to %s", channel, maskDestination(destination))
+	return &OTPDelivery{
+		Channel:     channel,
+		Destination: maskDestination(destination),
+		ExpiresIn:   int(s.options.TTL.Seconds()),
+	}, nil
+}
+
+// VerifyCode checks a code sent to a phone or email and returns the donor it signs in,
+// registering a donor account on first sign-in. A code can only be used once.
+func (s *OTPService) VerifyCode(phone, email, code string) (*models.User, error) {
+	destination, _, err := otpDestination(phone, email, "")
+	if err != nil {
+		return nil, err
+	}
+	key := "otp:" + destination
+
+	attempts, err := otpAttempt.Run(database.Ctx, s.redis, []string{key}).Int()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check OTP: %w", err)
+	}
+	if attempts < 0 {
+		return nil, ErrOTPInvalid
+	}
+	if attempts > s.options.MaxAttempts {
+		s.redis.Del(database.Ctx, key)
+		return nil, ErrOTPAttemptsExceeded
+	}
+
+	stored, err := s.redis.HGet(database.Ctx, key, "code_hash").Result()
+	if err == redis.Nil {
+		return nil, ErrOTPInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check OTP: %w", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(stored), []byte(otpHash(destination, code))) != 1 {
+		return nil, ErrOTPInvalid
+	}
+
+	// Whoever deletes the code uses it
+	deleted, err := s.redis.Del(database.Ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to use OTP: %w", err)
+	}
+	if deleted == 0 {
+		return nil, ErrOTPInvalid
+	}
+
+	if strings.Contains(destination, "@") {
+		return s.userService.FindOrCreateDonor("", destination)
+	}
+	return s.userService.FindOrCreateDonor(destination, "")
+}
+
+// otpDestination validates and normalizes the phone or email a code is for, and the channel
+// it is sent over
+func otpDestination(phone, email, channel string) (string, string, error) {
+	if (phone == "") == (email == "") {
+		return "", "", newInvalidInputError("phone", "give either a phone number or an email address")
+	}
+
+	if email != "" {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if !emailPattern.MatchString(email) {
+			return "", "", newInvalidInputError("email", "invalid email address")
+		}
+		if channel != "" && channel != OTPChannelEmail {
+			return "", "", newInvalidInputError("channel", "codes for an email address are sent by email")
+		}
+		return email, OTPChannelEmail, nil
+	}
+
+	normalized, ok := NormalizePhone(phone)
+	if !ok {
+		return "", "", newInvalidInputError("phone", "invalid phone number")
+	}
+	switch channel {
+	case "":
+		channel = OTPChannelWhatsApp
+	case OTPChannelSMS, OTPChannelWhatsApp:
+	default:
+		return "", "", newInvalidInputError("channel", "codes for a phone number are sent by sms or whatsapp")
+	}
+	return normalized, channel, nil
+}
+
+// NormalizePhone converts an Indonesian phone number as donors write it (0812-3456-7890,
+// 62812..., +62 812...) to +62812...; other numbers must already have a + country code
+func NormalizePhone(phone string) (string, bool) {
+	phone = strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' || r == '(' || r == ')' || r == '.' {
+			return -1
+		}
+		return r
+	}, phone)
+
+	switch {
+	case strings.HasPrefix(phone, "0"):
+		phone = "+62" + phone[1:]
+	case strings.HasPrefix(phone, "62"):
+		phone = "+" + phone
+	}
+	return phone, phonePattern.MatchString(phone)
+}
+
+// newOTPCode returns a random numeric code
+func newOTPCode() (string, error) {
+	limit := big.NewInt(1)
+	for i := 0; i < otpCodeLength; i++ {
+		limit.Mul(limit, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate OTP: %w", err)
+	}
+	return fmt.Sprintf("%0*d", otpCodeLength, n.Int64()), nil
+}
+
+// otpHash hashes a code together with its destination
+func otpHash(destination, code string) string {
+	sum := sha256.Sum256([]byte(destination + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
+
+// maskDestination hides most of a phone number or email address for responses and logs
+func maskDestination(destination string) string {
+	if at := strings.Index(destination, "@"); at >= 0 {
+		if at <= 1 {
+			return "*" + destination[at:]
+		}
+		return destination[:1] + strings.Repeat("*", at-1) + destination[at:]
+	}
+	if len(destination) <= 7 {
+		return destination
+	}
+	return destination[:5] + strings.Repeat("*", len(destination)-7) + destination[len(destination)-2:]
+}
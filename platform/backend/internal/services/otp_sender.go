@@ -0,0 +1,100 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OTP delivery channels
+const (
+	OTPChannelSMS      = "sms"
+	OTPChannelWhatsApp = "whatsapp"
+	OTPChannelEmail    = "email"
+)
+
+// OTPSender delivers one-time login codes to donors over one or more channels
+type OTPSender interface {
+	Send(channel, destination, message string) error
+}
+
+// LogOTPSender only logs codes, for development. It must not be used in production, where
+// anyone with access to the logs could sign in as any donor.
+type LogOTPSender struct{}
+
+// Send logs the message instead of delivering it
+func (LogOTPSender) Send(channel, destination, message string) error {
+	log.Printf("📨 [%s to %s] %s", channel, destination, message)
+	return nil
+}
+
+// EmailOTPSender delivers codes by email
+type EmailOTPSender struct {
+	emailService *EmailService
+}
+
+// NewEmailOTPSender creates a sender delivering codes through the email service
+func NewEmailOTPSender(emailService *EmailService) *EmailOTPSender {
+	return &EmailOTPSender{emailService: emailService}
+}
+
+// Send emails the message
+func (s *EmailOTPSender) Send(channel, destination, message string) error {
+	if !s.emailService.Configured() {
+		return errors.New("email is not configured")
+	}
+	return s.emailService.SendEmail(destination, "Kode Masuk YDSF Platform", message)
+}
+
+// GatewayOTPSender delivers SMS or WhatsApp codes through an HTTP messaging gateway, which is
+// sent {"channel", "to", "message"} as JSON with the token as bearer authorization
+type GatewayOTPSender struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewGatewayOTPSender creates a sender posting codes to the gateway at url
+func NewGatewayOTPSender(url, token string, timeout time.Duration) *GatewayOTPSender {
+	return &GatewayOTPSender{
+		url:    url,
+		token:  token,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Send posts the message to the gateway
+func (s *GatewayOTPSender) Send(channel, destination, message string) error {
+	payload, err := json.Marshal(map[string]string{
+		"channel": channel,
+		"to":      destination,
+		"message": message,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s message: %w", channel, err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %w", channel, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send %s message: %w", channel, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("messaging gateway returned %s for %s message", strings.TrimSpace(resp.Status), channel)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	testCases := []struct {
+		phone         string
+		expected      string
+		expectedValid bool
+	}{
+		{"081234567890", "+6281234567890", true},
+		{"0812-3456-7890", "+6281234567890", true},
+		{"(0812) 3456.7890", "+6281234567890", true},
+		{"6281234567890", "+6281234567890", true},
+		{"+62 812 3456 7890", "+6281234567890", true},
+		{"+60123456789", "+60123456789", true},
+		{"81234567890", "81234567890", false},
+		{"0812", "+62812", false},
+		{"+0812345678", "+0812345678", false},
+		{"0812-3456-789O", "+628123456789O", false},
+		{"", "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.phone, func(t *testing.T) {
+			normalized, ok := NormalizePhone(tc.phone)
+			require.Equal(t, tc.expectedValid, ok)
+			require.Equal(t, tc.expected, normalized)
+		})
+	}
+}
+
+func TestOTPDestination(t *testing.T) {
+	testCases := []struct {
+		name                string
+		phone               string
+		email               string
+		channel             string
+		expectedDestination string
+		expectedChannel     string
+		expectedField       string
+	}{
+		{name: "PhoneDefaultsToWhatsApp", phone: "0812-3456-7890", expectedDestination: "+6281234567890", expectedChannel: OTPChannelWhatsApp},
+		{name: "PhoneOverSMS", phone: "6281234567890", channel: OTPChannelSMS, expectedDestination: "+6281234567890", expectedChannel: OTPChannelSMS},
+		{name: "EmailNormalized", email: "  Donor@Example.COM ", expectedDestination: "donor@example.com", expectedChannel: OTPChannelEmail},
+		{name: "EmailOverEmail", email: "donor@example.com", channel: OTPChannelEmail, expectedDestination: "donor@example.com", expectedChannel: OTPChannelEmail},
+		{name: "Neither", expectedField: "phone"},
+		{name: "Both", phone: "081234567890", email: "donor@example.com", expectedField: "phone"},
+		{name: "InvalidPhone", phone: "12345", expectedField: "phone"},
+		{name: "InvalidEmail", email: "donor@example", expectedField: "email"},
+		{name: "EmailOverSMS", email: "donor@example.com", channel: OTPChannelSMS, expectedField: "channel"},
+		{name: "PhoneOverEmail", phone: "081234567890", channel: OTPChannelEmail, expectedField: "channel"},
+		{name: "UnknownChannel", phone: "081234567890", channel: "telegram", expectedField: "channel"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			destination, channel, err := otpDestination(tc.phone, tc.email, tc.channel)
+			if tc.expectedField != "" {
+				var contractErr *ContractError
+				require.ErrorAs(t, err, &contractErr)
+				require.Equal(t, CodeInvalidInput, contractErr.Code)
+				require.Equal(t, tc.expectedField, contractErr.Field)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedDestination, destination)
+			require.Equal(t, tc.expectedChannel, channel)
+		})
+	}
+}
+
+func TestMaskDestination(t *testing.T) {
+	testCases := []struct {
+		destination string
+		expected    string
+	}{
+		{"+6281234567890", "+6281*******90"},
+		{"+628123", "+628123"},
+		{"donor@example.com", "d****@example.com"},
+		{"d@example.com", "*@example.com"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.destination, func(t *testing.T) {
+			require.Equal(t, tc.expected, maskDestination(tc.destination))
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Outbox entry statuses
+const (
+	OutboxPending = "pending"
+	OutboxDone    = "done"
+	OutboxFailed  = "failed"
+)
+
+// ErrSyncPending is returned when a ledger step could not be completed right away. It stays in
+// the outbox and is retried by the worker; the aggregate keeps sync_status "pending_sync".
+var ErrSyncPending = errors.New("ledger submission is pending and will be retried")
+
+// OutboxHandler carries out the ledger step of one outbox operation
+type OutboxHandler struct {
+	// Apply submits the step to Fabric and records its result in tx. It is retried until it
+	// succeeds, so it must recognise a step an earlier attempt already committed to the ledger.
+	Apply func(tx *gorm.DB, entry *models.OutboxEntry) error
+	// Compensate undoes the intent in tx after the ledger rejected the step
+	Compensate func(tx *gorm.DB, entry *models.OutboxEntry, cause error) error
+	// Fail records in tx that the step ran out of attempts and needs an operator
+	Fail func(tx *gorm.DB, entry *models.OutboxEntry) error
+	// Done runs after a successful step is committed, for side effects outside Postgres
+	Done func(entry *models.OutboxEntry)
+}
+
+// OutboxOptions tune outbox retries
+type OutboxOptions struct {
+	MaxAttempts int           // Attempts before an entry is failed for an operator to look at
+	Backoff     time.Duration // Delay before the first retry; doubles with every attempt
+	MaxBackoff  time.Duration
+}
+
+// OutboxService keeps Postgres and the ledger consistent. Intents are written to the outbox in
+// the same transaction as the records they affect; the ledger step is then carried out from the
+// outbox, right away and by a worker until it succeeds, is rejected or runs out of attempts.
+type OutboxService struct {
+	db       *gorm.DB
+	options  OutboxOptions
+	handlers map[string]OutboxHandler
+}
+
+// NewOutboxService creates a new outbox service
+func NewOutboxService(db *gorm.DB, options OutboxOptions) *OutboxService {
+	return &OutboxService{
+		db:       db,
+		options:  options,
+		handlers: make(map[string]OutboxHandler),
+	}
+}
+
+// Register sets the handler of an operation
+func (s *OutboxService) Register(operation string, handler OutboxHandler) {
+	s.handlers[operation] = handler
+}
+
+// Add writes an intent to the outbox within the caller's transaction. The caller makes the first
+// attempt with Process once it committed; the worker only picks the entry up after Backoff.
+func (s *OutboxService) Add(tx *gorm.DB, aggregateType, aggregateID, operation string, payload interface{}) (*models.OutboxEntry, error) {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s intent: %w", operation, err)
+	}
+
+	now := time.Now()
+	entry := &models.OutboxEntry{
+		ID:            uuid.New(),
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Operation:     operation,
+		Payload:       string(payloadJSON),
+		Status:        OutboxPending,
+		NextAttemptAt: now.Add(s.options.Backoff),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := tx.Create(entry).Error; err != nil {
+		return nil, fmt.Errorf("failed to write %s intent: %w", operation, err)
+	}
+	return entry, nil
+}
+
+// Process carries out a pending entry now. It returns nil once the step is done, the ledger's
+// error when it rejected the step (after compensating), and ErrSyncPending when the step is
+// left to the worker. An entry another worker is processing is reported as pending too.
+func (s *OutboxService) Process(id uuid.UUID) error {
+	var result error
+	var done *models.OutboxEntry
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var entry models.OutboxEntry
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("id = ? AND status = ?", id, OutboxPending).First(&entry).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			result = ErrSyncPending
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to lock outbox entry %s: %w", id, err)
+		}
+
+		result, err = s.attempt(tx, &entry)
+		if err == nil && result == nil {
+			done = &entry
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if done != nil {
+		s.done(done)
+	}
+	return result
+}
+
+// Start processes due entries every interval
+func (s *OutboxService) Start(interval time.Duration) {
+	log.Printf("🕒 Outbox worker polling every %v", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			s.ProcessDue()
+		}
+	}()
+}
+
+// ProcessDue processes every pending entry that is due, oldest first
+func (s *OutboxService) ProcessDue() {
+	for {
+		var done *models.OutboxEntry
+		found := false
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			var entry models.OutboxEntry
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("status = ? AND next_attempt_at <= ?", OutboxPending, time.Now()).
+				Order("created_at").First(&entry).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to claim outbox entry: %w", err)
+			}
+			found = true
+
+			result, err := s.attempt(tx, &entry)
+			if err == nil && result == nil {
+				done = &entry
+			}
+			return err
+		})
+		if err != nil {
+			log.Printf("❌ Outbox worker: %v", err)
+			return
+		}
+		if done != nil {
+			s.done(done)
+		}
+		if !found {
+			return
+		}
+	}
+}
+
+// attempt runs one attempt of a locked entry and saves its outcome in tx. The returned result is
+// nil when the step is done; err is only set when the outcome could not be saved.
+func (s *OutboxService) attempt(tx *gorm.DB, entry *models.OutboxEntry) (result error, err error) {
+	handler, ok := s.handlers[entry.Operation]
+	now := time.Now()
+	entry.Attempts++
+	entry.UpdatedAt = now
+
+	var applyErr error
+	if !ok {
+		applyErr = fmt.Errorf("no outbox handler registered for %s", entry.Operation)
+	} else {
+		// A savepoint keeps a failed attempt's writes from aborting the transaction
+		applyErr = tx.Transaction(func(tx *gorm.DB) error {
+			return handler.Apply(tx, entry)
+		})
+	}
+
+	var contractErr *ContractError
+	switch {
+	case applyErr == nil:
+		entry.Status = OutboxDone
+		entry.LastError = ""
+		entry.CompletedAt = &now
+		log.Printf("✅ Outbox %s of %s %s done", entry.Operation, entry.AggregateType, entry.AggregateID)
+
+	case errors.As(applyErr, &contractErr):
+		// The ledger rejected the step; retrying cannot help
+		log.Printf("↩️ Ledger rejected %s of %s %s, compensating: %v", entry.Operation, entry.AggregateType, entry.AggregateID, applyErr)
+		if handler.Compensate != nil {
+			if err := handler.Compensate(tx, entry, applyErr); err != nil {
+				return nil, fmt.Errorf("failed to compensate %s of %s: %w", entry.Operation, entry.AggregateID, err)
+			}
+		}
+		entry.Status = OutboxFailed
+		entry.LastError = applyErr.Error()
+		result = applyErr
+
+	case entry.Attempts >= s.options.MaxAttempts || !ok:
+		log.Printf("💀 Outbox %s of %s %s failed %d times: %v", entry.Operation, entry.AggregateType, entry.AggregateID, entry.Attempts, applyErr)
+		if ok && handler.Fail != nil {
+			if err := handler.Fail(tx, entry); err != nil {
+				return nil, fmt.Errorf("failed to record failure of %s of %s: %w", entry.Operation, entry.AggregateID, err)
+			}
+		}
+		entry.Status = OutboxFailed
+		entry.LastError = applyErr.Error()
+		result = fmt.Errorf("%w: %v", ErrSyncPending, applyErr)
+
+	default:
+		entry.LastError = applyErr.Error()
+		entry.NextAttemptAt = now.Add(s.backoff(entry.Attempts))
+		log.Printf("⚠️ Outbox %s of %s %s failed (attempt %d of %d), retrying at %s: %v",
+			entry.Operation, entry.AggregateType, entry.AggregateID, entry.Attempts, s.options.MaxAttempts,
+			entry.NextAttemptAt.Format(time.RFC3339), applyErr)
+		result = fmt.Errorf("%w: %v", ErrSyncPending, applyErr)
+	}
+
+	if err := tx.Save(entry).Error; err != nil {
+		return nil, fmt.Errorf("failed to save outbox entry %s: %w", entry.ID, err)
+	}
+	return result, nil
+}
+
+func (s *OutboxService) done(entry *models.OutboxEntry) {
+	if handler, ok := s.handlers[entry.Operation]; ok && handler.Done != nil {
+		handler.Done(entry)
+	}
+}
+
+// backoff is Backoff doubled for every attempt after the first, capped at MaxBackoff
+func (s *OutboxService) backoff(attempts int) time.Duration {
+	backoff := float64(s.options.Backoff) * math.Pow(2, float64(attempts-1))
+	if backoff > float64(s.options.MaxBackoff) {
+		return s.options.MaxBackoff
+	}
+	return time.Duration(backoff)
+}
+
+// ListEntries returns the entries in a status, oldest first, up to limit
+func (s *OutboxService) ListEntries(status string, limit int) ([]models.OutboxEntry, error) {
+	if status != OutboxPending && status != OutboxDone && status != OutboxFailed {
+		return nil, newInvalidInputError("status", "status must be one of: pending, done, failed")
+	}
+
+	entries := []models.OutboxEntry{}
+	if err := s.db.Where("status = ?", status).Order("created_at").Limit(limit).Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to list %s outbox entries: %w", status, err)
+	}
+	return entries, nil
+}
+
+// Retry returns a failed entry to the queue with fresh attempts. Only entries that ran out of
+// attempts can be retried; rejected ones were compensated.
+func (s *OutboxService) Retry(id uuid.UUID) (*models.OutboxEntry, error) {
+	var entry models.OutboxEntry
+	if err := s.db.Where("id = ?", id).First(&entry).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, newNotFoundError("outbox entry %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get outbox entry %s: %w", id, err)
+	}
+	if entry.Status != OutboxFailed || entry.Attempts < s.options.MaxAttempts {
+		return nil, newInvalidStateError("outbox entry %s is %s and cannot be retried", id, entry.Status)
+	}
+
+	now := time.Now()
+	entry.Status = OutboxPending
+	entry.Attempts = 0
+	entry.NextAttemptAt = now
+	entry.UpdatedAt = now
+	if err := s.db.Save(&entry).Error; err != nil {
+		return nil, fmt.Errorf("failed to requeue outbox entry %s: %w", id, err)
+	}
+
+	log.Printf("🔁 Requeued outbox entry %s (%s of %s)", id, entry.Operation, entry.AggregateID)
+	return &entry, nil
+}
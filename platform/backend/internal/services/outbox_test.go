@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/models"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// recordingConn is a database connection that accepts every statement and records it, so
+// outbox outcomes can be checked without Postgres
+type recordingConn struct {
+	mu         sync.Mutex
+	statements []string
+}
+
+func (c *recordingConn) record(statement string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.statements = append(c.statements, statement)
+}
+
+func (c *recordingConn) recorded() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.statements...)
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepared statements are not supported: %s", query)
+}
+
+func (c *recordingConn) Close() error { return nil }
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	c.record("BEGIN")
+	return c, nil
+}
+
+func (c *recordingConn) Commit() error {
+	c.record("COMMIT")
+	return nil
+}
+
+func (c *recordingConn) Rollback() error {
+	c.record("ROLLBACK")
+	return nil
+}
+
+func (c *recordingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.record(query)
+	return driver.RowsAffected(1), nil
+}
+
+func (c *recordingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.record(query)
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string              { return []string{} }
+func (emptyRows) Close() error                   { return nil }
+func (emptyRows) Next(dest []driver.Value) error { return io.EOF }
+
+type recordingConnector struct {
+	conn *recordingConn
+}
+
+func (c recordingConnector) Connect(context.Context) (driver.Conn, error) { return c.conn, nil }
+func (c recordingConnector) Driver() driver.Driver                        { return nil }
+
+// newRecordingDB opens a gorm database on a recordingConn
+func newRecordingDB(t *testing.T) (*gorm.DB, *recordingConn) {
+	conn := &recordingConn{}
+	sqlDB := sql.OpenDB(recordingConnector{conn: conn})
+	sqlDB.SetMaxOpenConns(1)
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	return db, conn
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	outbox := NewOutboxService(nil, OutboxOptions{Backoff: 5 * time.Second, MaxBackoff: 10 * time.Minute})
+
+	testCases := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{1, 5 * time.Second},
+		{2, 10 * time.Second},
+		{3, 20 * time.Second},
+		{7, 320 * time.Second},
+		{8, 10 * time.Minute}, // 640s, capped
+		{40, 10 * time.Minute},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("Attempt%d", tc.attempts), func(t *testing.T) {
+			require.Equal(t, tc.expected, outbox.backoff(tc.attempts))
+		})
+	}
+}
+
+func TestOutboxAttempt(t *testing.T) {
+	errLedgerDown := errors.New("connection refused")
+	rejected := &ContractError{Code: CodeInvalidState, Message: "zakat is not pending"}
+
+	testCases := []struct {
+		name          string
+		operation     string
+		attempts      int // Before this attempt
+		applyErr      error
+		compensateErr error
+		expectedErr   string // Outcome could not be saved
+		expectedCalls []string
+		check         func(t *testing.T, entry *models.OutboxEntry, result error, start time.Time)
+	}{
+		{
+			name:          "Done",
+			operation:     "validate",
+			expectedCalls: []string{"apply"},
+			check: func(t *testing.T, entry *models.OutboxEntry, result error, start time.Time) {
+				require.NoError(t, result)
+				require.Equal(t, OutboxDone, entry.Status)
+				require.Empty(t, entry.LastError)
+				require.NotNil(t, entry.CompletedAt)
+			},
+		},
+		{
+			name:          "RejectedByLedger",
+			operation:     "validate",
+			applyErr:      fmt.Errorf("failed to validate payment: %w", rejected),
+			expectedCalls: []string{"apply", "compensate"},
+			check: func(t *testing.T, entry *models.OutboxEntry, result error, start time.Time) {
+				var contractErr *ContractError
+				require.ErrorAs(t, result, &contractErr)
+				require.NotErrorIs(t, result, ErrSyncPending)
+				require.Equal(t, OutboxFailed, entry.Status)
+				require.Equal(t, "failed to validate payment: zakat is not pending", entry.LastError)
+				require.Nil(t, entry.CompletedAt)
+			},
+		},
+		{
+			name:          "Retried",
+			operation:     "validate",
+			attempts:      1,
+			applyErr:      errLedgerDown,
+			expectedCalls: []string{"apply"},
+			check: func(t *testing.T, entry *models.OutboxEntry, result error, start time.Time) {
+				require.ErrorIs(t, result, ErrSyncPending)
+				require.Equal(t, OutboxPending, entry.Status)
+				require.Equal(t, errLedgerDown.Error(), entry.LastError)
+				// Second attempt: Backoff doubled once
+				require.WithinDuration(t, start.Add(10*time.Second), entry.NextAttemptAt, time.Second)
+			},
+		},
+		{
+			name:          "OutOfAttempts",
+			operation:     "validate",
+			attempts:      2,
+			applyErr:      errLedgerDown,
+			expectedCalls: []string{"apply", "fail"},
+			check: func(t *testing.T, entry *models.OutboxEntry, result error, start time.Time) {
+				require.ErrorIs(t, result, ErrSyncPending)
+				require.Equal(t, OutboxFailed, entry.Status)
+				require.Equal(t, errLedgerDown.Error(), entry.LastError)
+			},
+		},
+		{
+			name:      "NoHandler",
+			operation: "unknown",
+			check: func(t *testing.T, entry *models.OutboxEntry, result error, start time.Time) {
+				require.ErrorIs(t, result, ErrSyncPending)
+				require.Equal(t, OutboxFailed, entry.Status)
+				require.Equal(t, "no outbox handler registered for unknown", entry.LastError)
+			},
+		},
+		{
+			name:          "CompensationFails",
+			operation:     "validate",
+			applyErr:      rejected,
+			compensateErr: errors.New("database unavailable"),
+			expectedErr:   "failed to compensate validate of ZKT-YDSF-MLG-202503-0001: database unavailable",
+			expectedCalls: []string{"apply", "compensate"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, conn := newRecordingDB(t)
+			outbox := NewOutboxService(db, OutboxOptions{MaxAttempts: 3, Backoff: 5 * time.Second, MaxBackoff: time.Minute})
+
+			var calls []string
+			outbox.Register("validate", OutboxHandler{
+				Apply: func(tx *gorm.DB, entry *models.OutboxEntry) error {
+					calls = append(calls, "apply")
+					return tc.applyErr
+				},
+				Compensate: func(tx *gorm.DB, entry *models.OutboxEntry, cause error) error {
+					calls = append(calls, "compensate")
+					require.Equal(t, tc.applyErr, cause)
+					return tc.compensateErr
+				},
+				Fail: func(tx *gorm.DB, entry *models.OutboxEntry) error {
+					calls = append(calls, "fail")
+					return nil
+				},
+			})
+
+			start := time.Now()
+			entry := &models.OutboxEntry{
+				ID:            uuid.New(),
+				AggregateType: "donation",
+				AggregateID:   "ZKT-YDSF-MLG-202503-0001",
+				Operation:     tc.operation,
+				Status:        OutboxPending,
+				Attempts:      tc.attempts,
+				NextAttemptAt: start,
+				CreatedAt:     start,
+			}
+
+			var result error
+			err := db.Transaction(func(tx *gorm.DB) error {
+				var err error
+				result, err = outbox.attempt(tx, entry)
+				return err
+			})
+			require.Equal(t, tc.expectedCalls, calls)
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.attempts+1, entry.Attempts)
+			tc.check(t, entry, result, start)
+
+			// The outcome is saved in the caller's transaction, after the attempt's savepoint
+			statements := conn.recorded()
+			require.Equal(t, "BEGIN", statements[0])
+			require.Equal(t, "COMMIT", statements[len(statements)-1])
+			require.True(t, strings.HasPrefix(statements[len(statements)-2], `UPDATE "outbox_entries"`), statements)
+			if tc.operation == "validate" {
+				require.True(t, strings.HasPrefix(statements[1], "SAVEPOINT"), statements)
+				if tc.applyErr != nil {
+					require.Contains(t, statements, strings.Replace(statements[1], "SAVEPOINT", "ROLLBACK TO SAVEPOINT", 1))
+				}
+			}
+		})
+	}
+}
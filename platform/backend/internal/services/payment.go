@@ -0,0 +1,179 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/izzuddinafif/fabric/platform/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// Payment statuses reported by providers
+const (
+	PaymentPending = "pending"
+	PaymentPaid    = "paid"
+	PaymentFailed  = "failed"
+	PaymentExpired = "expired"
+)
+
+// ErrInvalidSignature is returned for payment notifications whose signature does not verify
+var ErrInvalidSignature = errors.New("invalid payment notification signature")
+
+// PaymentProvider is a payment gateway donations are paid through
+type PaymentProvider interface {
+	// Name identifies the provider in donation records
+	Name() string
+	// CreateCharge opens a payment for a donation, using the donation ID as the order ID
+	CreateCharge(donation *models.Donation) (*PaymentCharge, error)
+	// GetStatus queries the current status of an order
+	GetStatus(orderID string) (*PaymentNotification, error)
+	// ParseWebhook verifies and decodes a payment notification, returning
+	// ErrInvalidSignature if it was not signed by the gateway
+	ParseWebhook(body []byte, header http.Header) (*PaymentNotification, error)
+}
+
+// PaymentCharge is an opened payment the donor completes at RedirectURL
+type PaymentCharge struct {
+	Token       string
+	RedirectURL string
+}
+
+// PaymentNotification is a verified payment status of an order
+type PaymentNotification struct {
+	OrderID   string  // Donation ID
+	Reference string  // Gateway transaction ID, recorded as the payment reference
+	Status    string  // pending, paid, failed, expired
+	Amount    float64 // IDR
+	Method    string  // e.g. bank_transfer, qris, gopay
+}
+
+// PaymentService opens gateway charges for donations and validates donations whose payment
+// the gateway confirms
+type PaymentService struct {
+	provider          PaymentProvider
+	validationService *ValidationService
+	qrisService       *QRISService
+	db                *gorm.DB
+}
+
+// NewPaymentService creates a new payment service
+func NewPaymentService(provider PaymentProvider, validationService *ValidationService, db *gorm.DB) *PaymentService {
+	return &PaymentService{
+		provider:          provider,
+		validationService: validationService,
+		db:                db,
+	}
+}
+
+// SetQRISService sets the service generating dynamic QRIS codes for qris donations
+func (s *PaymentService) SetQRISService(qrisService *QRISService) {
+	s.qrisService = qrisService
+}
+
+// CreateCharge opens a charge for a new donation and records the provider and payment URL on it.
+// QRIS donations get a dynamic QRIS code instead; the acquirer notifies the same webhook with the
+// code's reference label (the donation ID) as the order ID. Cash donations get no charge; they
+// are paid at the branch office and validated by an admin.
+func (s *PaymentService) CreateCharge(donation *models.Donation) error {
+	if donation.PaymentMethod == "cash" {
+		return nil
+	}
+	if donation.PaymentMethod == "qris" && s.qrisService != nil {
+		return s.createQRIS(donation)
+	}
+
+	charge, err := s.provider.CreateCharge(donation)
+	if err != nil {
+		return fmt.Errorf("failed to create %s charge for donation %s: %w", s.provider.Name(), donation.ID, err)
+	}
+
+	donation.PaymentProvider = sql.NullString{String: s.provider.Name(), Valid: true}
+	donation.PaymentURL = sql.NullString{String: charge.RedirectURL, Valid: charge.RedirectURL != ""}
+	err = s.db.Model(&models.Donation{}).Where("id = ?", donation.ID).Updates(map[string]interface{}{
+		"payment_provider": donation.PaymentProvider,
+		"payment_url":      donation.PaymentURL,
+		"updated_at":       time.Now(),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to record charge for donation %s: %w", donation.ID, err)
+	}
+
+	log.Printf("💳 Opened %s charge for donation %s", s.provider.Name(), donation.ID)
+	return nil
+}
+
+func (s *PaymentService) createQRIS(donation *models.Donation) error {
+	payload, err := s.qrisService.Generate(donation.ID, donation.Amount)
+	if err != nil {
+		return fmt.Errorf("failed to generate QRIS for donation %s: %w", donation.ID, err)
+	}
+
+	donation.PaymentProvider = sql.NullString{String: s.provider.Name(), Valid: true}
+	donation.QRISPayload = sql.NullString{String: payload, Valid: true}
+	err = s.db.Model(&models.Donation{}).Where("id = ?", donation.ID).Updates(map[string]interface{}{
+		"payment_provider": donation.PaymentProvider,
+		"qris_payload":     donation.QRISPayload,
+		"updated_at":       time.Now(),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to record QRIS for donation %s: %w", donation.ID, err)
+	}
+
+	log.Printf("💳 Generated QRIS for donation %s", donation.ID)
+	return nil
+}
+
+// HandleWebhook verifies a payment notification and queues the donation's validation once it is paid
+func (s *PaymentService) HandleWebhook(body []byte, header http.Header) error {
+	notification, err := s.provider.ParseWebhook(body, header)
+	if err != nil {
+		return err
+	}
+	return s.applyNotification(notification)
+}
+
+// RefreshPayment queries the gateway for a donation's payment status, validating the donation
+// if a paid notification was missed, and returns the status
+func (s *PaymentService) RefreshPayment(donationID string) (*PaymentNotification, error) {
+	notification, err := s.provider.GetStatus(donationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment status of donation %s: %w", donationID, err)
+	}
+	if err := s.applyNotification(notification); err != nil {
+		return nil, err
+	}
+	return notification, nil
+}
+
+func (s *PaymentService) applyNotification(notification *PaymentNotification) error {
+	log.Printf("💳 Payment of donation %s is %s (%s)", notification.OrderID, notification.Status, notification.Reference)
+	if notification.Status != PaymentPaid {
+		return nil
+	}
+
+	var donation models.Donation
+	if err := s.db.Where("id = ?", notification.OrderID).First(&donation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return newNotFoundError("donation %s not found", notification.OrderID)
+		}
+		return fmt.Errorf("failed to get donation %s: %w", notification.OrderID, err)
+	}
+
+	// Gateways retry notifications until acknowledged, so repeats are not errors
+	if donation.BlockchainStatus != "pending" {
+		log.Printf("🔁 Donation %s is already %s, ignoring payment notification", donation.ID, donation.BlockchainStatus)
+		return nil
+	}
+
+	// Gateways charge whole rupiah
+	if math.Abs(notification.Amount-donation.Amount) >= 1 {
+		return newInvalidStateError("paid amount %.2f does not match donation %s amount %.2f", notification.Amount, donation.ID, donation.Amount)
+	}
+
+	return s.validationService.EnqueueAutoValidation(donation.ID, notification.Reference)
+}
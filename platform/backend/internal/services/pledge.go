@@ -0,0 +1,411 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// RamadanStart is 1 Ramadan of a Hijri year, as set by the Indonesian government (sidang isbat)
+type RamadanStart struct {
+	HijriYear int
+	Start     string // YYYY-MM-DD
+}
+
+// ParseRamadanStarts parses 1 Ramadan dates given as HIJRI_YEAR:YYYY-MM-DD. The years must follow
+// each other without gaps, since ramadan pledges are due once every year.
+func ParseRamadanStarts(entries []string) ([]RamadanStart, error) {
+	starts := make([]RamadanStart, 0, len(entries))
+	for _, entry := range entries {
+		year, date, found := strings.Cut(entry, ":")
+		hijriYear, err := strconv.Atoi(year)
+		if !found || err != nil {
+			return nil, fmt.Errorf("invalid Ramadan start %q, expected HIJRI_YEAR:YYYY-MM-DD", entry)
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, fmt.Errorf("invalid Ramadan start date %q: %w", entry, err)
+		}
+		if n := len(starts); n > 0 && (hijriYear != starts[n-1].HijriYear+1 || date <= starts[n-1].Start) {
+			return nil, fmt.Errorf("Ramadan start %q does not follow %dH", entry, starts[n-1].HijriYear)
+		}
+		starts = append(starts, RamadanStart{HijriYear: hijriYear, Start: date})
+	}
+	return starts, nil
+}
+
+// PledgeService handles recurring zakat pledges
+type PledgeService struct {
+	fabricService   *FabricService
+	donationService *DonationService
+	db              *gorm.DB
+	emailService    *EmailService
+	userService     *UserService
+	ramadanStarts   []RamadanStart
+}
+
+// NewPledgeService creates a new pledge service
+func NewPledgeService(fabricService *FabricService, donationService *DonationService, db *gorm.DB, emailService *EmailService, userService *UserService, ramadanStarts []RamadanStart) *PledgeService {
+	return &PledgeService{
+		fabricService:   fabricService,
+		donationService: donationService,
+		db:              db,
+		emailService:    emailService,
+		userService:     userService,
+		ramadanStarts:   ramadanStarts,
+	}
+}
+
+// CreatePledge registers a recurring pledge starting at startDate
+func (s *PledgeService) CreatePledge(req models.CreatePledgeRequest, startDate time.Time) (*models.Pledge, error) {
+	log.Printf("🎯 Creating %s pledge for: %s, Amount: %.2f, Type: %s", req.Schedule, req.Name, req.Amount, req.Type)
+
+	// Zakat fitrah is due per soul at each year's rate, which a fixed amount per period cannot follow
+	if req.Type == "fitrah" {
+		return nil, newInvalidInputError("type", "zakat fitrah cannot be pledged, only zakat maal")
+	}
+
+	firstDue, _, ok := pledgeOccurrence(req.Schedule, startDate, 0, s.ramadanStarts)
+	if !ok {
+		return nil, newInvalidStateError("no Ramadan start date is known after %s", startDate.Format("2006-01-02"))
+	}
+
+	organization, paymentMethod, err := resolvePaymentOptions(req.Organization, req.PaymentMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	// As with donations, anonymous pledges reach the ledger under their display alias only
+	muzakki := req.Name
+	displayName := ""
+	if req.Anonymous {
+		displayName = req.DisplayName
+		if displayName == "" {
+			displayName = models.DefaultAnonymousAlias
+		}
+		muzakki = displayName
+	}
+
+	pledgeID, err := s.fabricService.CreatePledge(muzakki, req.Amount, req.Type, req.Schedule, req.ProgramID, organization, startDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit pledge to blockchain: %w", err)
+	}
+
+	now := time.Now()
+	pledge := &models.Pledge{
+		ID:            pledgeID,
+		DonorID:       uuid.NullUUID{UUID: req.DonorID, Valid: req.DonorID != uuid.Nil},
+		DonorName:     req.Name,
+		DonorPhone:    req.Phone,
+		DonorEmail:    sql.NullString{String: req.Email, Valid: req.Email != ""},
+		IsAnonymous:   req.Anonymous,
+		DisplayName:   sql.NullString{String: displayName, Valid: displayName != ""},
+		Amount:        req.Amount,
+		Type:          req.Type,
+		Schedule:      req.Schedule,
+		ProgramID:     sql.NullString{String: req.ProgramID, Valid: req.ProgramID != ""},
+		ReferralCode:  sql.NullString{String: req.ReferralCode, Valid: req.ReferralCode != ""},
+		Organization:  organization,
+		PaymentMethod: paymentMethod,
+		Status:        "active",
+		StartDate:     startDate,
+		NextDueAt:     sql.NullTime{Time: firstDue, Valid: true},
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	if err := s.db.Create(pledge).Error; err != nil {
+		return nil, fmt.Errorf("failed to insert pledge: %w", err)
+	}
+
+	log.Printf("✅ Pledge created successfully: %s (first due %s)", pledgeID, firstDue.Format("2006-01-02"))
+
+	if s.emailService != nil && req.Email != "" {
+		go func() {
+			if err := s.emailService.SendPledgeCreatedEmail(req.Email, req.Name, pledgeID, req.Amount, req.Schedule, firstDue); err != nil {
+				log.Printf("❌ Failed to send pledge confirmation email for %s: %v", pledgeID, err)
+			}
+		}()
+	}
+
+	return pledge, nil
+}
+
+// GetPledge retrieves a pledge by ID
+func (s *PledgeService) GetPledge(id string) (*models.Pledge, error) {
+	var pledge models.Pledge
+	if err := s.db.Where("id = ?", id).First(&pledge).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, newNotFoundError("pledge %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get pledge: %w", err)
+	}
+	return &pledge, nil
+}
+
+// GetPledges retrieves pledges with pagination, optionally filtered by status
+func (s *PledgeService) GetPledges(status string, limit, offset int) ([]*models.Pledge, error) {
+	query := s.db.Order("created_at DESC").Limit(limit).Offset(offset)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var pledges []*models.Pledge
+	if err := query.Find(&pledges).Error; err != nil {
+		return nil, fmt.Errorf("failed to get pledges: %w", err)
+	}
+	return pledges, nil
+}
+
+// CancelPledge stops an active pledge on behalf of a signed-in user: an admin, or the donor who
+// owns the pledge. Pledges of other donors are not found.
+func (s *PledgeService) CancelPledge(id string, userID uuid.UUID, role, reason string) (*models.Pledge, error) {
+	pledge, err := s.GetPledge(id)
+	if err != nil {
+		return nil, err
+	}
+	switch role {
+	case "officer", "org_admin", "super_admin":
+	case "donor":
+		donor, err := s.userService.GetUserByID(userID)
+		if err != nil {
+			return nil, err
+		}
+		if !ownsPledge(donor, pledge) {
+			return nil, newNotFoundError("pledge %s not found", id)
+		}
+	default:
+		return nil, newUnauthorizedError("only the pledge's donor or an admin can cancel it")
+	}
+	if pledge.Status != "active" {
+		return nil, newInvalidStateError("pledge %s is not active", id)
+	}
+
+	if err := s.fabricService.CancelPledge(id, reason); err != nil {
+		return nil, fmt.Errorf("failed to cancel pledge on blockchain: %w", err)
+	}
+
+	now := time.Now()
+	err = s.db.Model(&models.Pledge{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":        "cancelled",
+		"next_due_at":   nil,
+		"cancelled_at":  now,
+		"cancel_reason": sql.NullString{String: reason, Valid: reason != ""},
+		"updated_at":    now,
+	}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to update pledge record: %w", err)
+	}
+
+	pledge.Status = "cancelled"
+	pledge.NextDueAt = sql.NullTime{}
+	pledge.CancelledAt = sql.NullTime{Time: now, Valid: true}
+	pledge.CancelReason = sql.NullString{String: reason, Valid: reason != ""}
+	pledge.UpdatedAt = now
+
+	log.Printf("✅ Pledge cancelled: %s by %s %s", id, role, userID)
+	return pledge, nil
+}
+
+// Start runs the pledge scheduler in the background, checking for due pledges every interval
+func (s *PledgeService) Start(interval time.Duration) {
+	log.Printf("🕒 Pledge scheduler checking for due pledges every %v", interval)
+
+	go func() {
+		s.ProcessDuePledges(time.Now())
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			s.ProcessDuePledges(now)
+		}
+	}()
+}
+
+// ProcessDuePledges creates the pending donation for every active pledge that is due at now.
+// A pledge that is several periods behind catches up one period per run.
+func (s *PledgeService) ProcessDuePledges(now time.Time) {
+	var pledges []models.Pledge
+	if err := s.db.Where("status = ? AND next_due_at <= ?", "active", now).Find(&pledges).Error; err != nil {
+		log.Printf("❌ Failed to load due pledges: %v", err)
+		return
+	}
+
+	for i := range pledges {
+		if err := s.schedulePayment(&pledges[i]); err != nil {
+			log.Printf("❌ Failed to schedule payment for pledge %s: %v", pledges[i].ID, err)
+		}
+	}
+}
+
+// schedulePayment creates the donation for the pledge's next period and reminds the donor
+func (s *PledgeService) schedulePayment(pledge *models.Pledge) error {
+	_, period, _ := pledgeOccurrence(pledge.Schedule, pledge.StartDate, pledge.ScheduledCount, s.ramadanStarts)
+
+	nextDue := sql.NullTime{}
+	if next, _, ok := pledgeOccurrence(pledge.Schedule, pledge.StartDate, pledge.ScheduledCount+1, s.ramadanStarts); ok {
+		nextDue = sql.NullTime{Time: next, Valid: true}
+	} else {
+		log.Printf("⚠️ No further due date known for pledge %s after %s", pledge.ID, period)
+	}
+
+	// Claim the period first so concurrent scheduler runs cannot create it twice
+	result := s.db.Model(&models.Pledge{}).
+		Where("id = ? AND scheduled_count = ?", pledge.ID, pledge.ScheduledCount).
+		Updates(map[string]interface{}{
+			"scheduled_count": pledge.ScheduledCount + 1,
+			"next_due_at":     nextDue,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to claim pledge period %s: %w", period, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil // Another run already scheduled this period
+	}
+
+	// The pledge and period form the ledger request key, so a retried period reuses its zakat
+	donation, err := s.donationService.CreatePledgeDonation(models.CreateDonationRequest{
+		Name:          pledge.DonorName,
+		Phone:         pledge.DonorPhone,
+		Email:         pledge.DonorEmail.String,
+		Anonymous:     pledge.IsAnonymous,
+		DisplayName:   pledge.DisplayName.String,
+		Amount:        pledge.Amount,
+		Type:          pledge.Type,
+		ProgramID:     pledge.ProgramID.String,
+		ReferralCode:  pledge.ReferralCode.String,
+		Organization:  pledge.Organization,
+		PaymentMethod: pledge.PaymentMethod,
+	}, pledge.ID, period)
+	if err != nil {
+		if !canRetryPeriod(err) {
+			// The donation would be rejected the same way on every run, so the period is skipped
+			return fmt.Errorf("skipped period %s, its donation was rejected: %w", period, err)
+		}
+		// Release the claim so the period is retried on the next run
+		s.db.Model(&models.Pledge{}).
+			Where("id = ? AND scheduled_count = ?", pledge.ID, pledge.ScheduledCount+1).
+			Updates(map[string]interface{}{
+				"scheduled_count": pledge.ScheduledCount,
+				"next_due_at":     pledge.NextDueAt,
+			})
+		return fmt.Errorf("failed to create donation for period %s: %w", period, err)
+	}
+
+	log.Printf("✅ Created donation %s for pledge %s period %s", donation.ID, pledge.ID, period)
+
+	if s.emailService != nil && pledge.DonorEmail.Valid && pledge.DonorEmail.String != "" {
+		if err := s.emailService.SendPledgeReminderEmail(pledge.DonorEmail.String, pledge.DonorName, pledge.ID, period, donation.ID, pledge.Amount); err != nil {
+			log.Printf("❌ Failed to send pledge reminder for %s: %v", pledge.ID, err)
+		} else {
+			log.Printf("📧 Pledge reminder sent for %s period %s", pledge.ID, period)
+		}
+	}
+
+	return nil
+}
+
+// canRetryPeriod reports whether creating a pledge period's donation failed for a reason that
+// may pass on a later run, such as the database being unavailable, rather than the donation
+// itself being invalid
+func canRetryPeriod(err error) bool {
+	return !errors.Is(err, ErrInvalidInput) &&
+		!errors.Is(err, ErrInvalidState) &&
+		!errors.Is(err, ErrNotFound) &&
+		!errors.Is(err, ErrInvalidFitrahDonation) &&
+		!errors.Is(err, ErrInvalidDenomination)
+}
+
+// RecordFulfilment records a validated donation against the pledge period it was created for,
+// on the blockchain and in the database. Donations that do not belong to a pledge are ignored.
+func (s *PledgeService) RecordFulfilment(donationID string) error {
+	donation, err := s.donationService.GetDonation(donationID)
+	if err != nil {
+		return err
+	}
+	if !donation.PledgeID.Valid || donation.PledgeID.String == "" {
+		return nil
+	}
+
+	pledgeID := donation.PledgeID.String
+	if err := s.fabricService.RecordPledgeFulfilment(pledgeID, donationID, donation.PledgePeriod.String); err != nil {
+		return fmt.Errorf("failed to record pledge fulfilment on blockchain: %w", err)
+	}
+
+	err = s.db.Model(&models.Pledge{}).Where("id = ?", pledgeID).Updates(map[string]interface{}{
+		"fulfilled_count": gorm.Expr("fulfilled_count + 1"),
+		"total_fulfilled": gorm.Expr("total_fulfilled + ?", donation.Amount),
+		"updated_at":      time.Now(),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update pledge record: %w", err)
+	}
+
+	log.Printf("✅ Donation %s recorded as period %s of pledge %s", donationID, donation.PledgePeriod.String, pledgeID)
+	return nil
+}
+
+// ownsPledge reports whether a pledge is the donor's: made while they were signed in, or as a
+// guest with the phone number or email address they verified when signing in
+func ownsPledge(donor *models.User, pledge *models.Pledge) bool {
+	if pledge.DonorID.Valid {
+		return pledge.DonorID.UUID == donor.ID
+	}
+	if phone, ok := NormalizePhone(donor.Phone.String); donor.Phone.Valid && ok {
+		if pledgePhone, ok := NormalizePhone(pledge.DonorPhone); ok && pledgePhone == phone {
+			return true
+		}
+	}
+	return donor.Email.Valid && donor.Email.String != "" && pledge.DonorEmail.Valid &&
+		strings.EqualFold(pledge.DonorEmail.String, donor.Email.String)
+}
+
+// pledgeOccurrence returns the due date and period label of the n-th (0-based) payment of a pledge.
+// ok is false when the date is unknown, i.e. a Ramadan beyond ramadanStarts.
+func pledgeOccurrence(schedule string, start time.Time, n int, ramadanStarts []RamadanStart) (time.Time, string, bool) {
+	switch schedule {
+	case "monthly":
+		due := addMonthsClamped(start, n)
+		return due, due.Format("2006-01"), true
+	case "yearly":
+		due := addMonthsClamped(start, 12*n)
+		return due, due.Format("2006"), true
+	case "ramadan":
+		startDay := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+		for i, ramadan := range ramadanStarts {
+			first, err := time.ParseInLocation("2006-01-02", ramadan.Start, start.Location())
+			if err != nil || first.Before(startDay) {
+				continue
+			}
+			if i+n >= len(ramadanStarts) {
+				return time.Time{}, "", false
+			}
+			target := ramadanStarts[i+n]
+			due, err := time.ParseInLocation("2006-01-02", target.Start, start.Location())
+			if err != nil {
+				return time.Time{}, "", false
+			}
+			return due, fmt.Sprintf("%dH", target.HijriYear), true
+		}
+	}
+	return time.Time{}, "", false
+}
+
+// addMonthsClamped adds months to t, keeping its day of month where possible.
+// Days that do not exist in the target month are clamped to its last day (31 Jan + 1 month = 28/29 Feb).
+func addMonthsClamped(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	firstOfMonth := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(firstOfMonth.Year(), firstOfMonth.Month(), day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
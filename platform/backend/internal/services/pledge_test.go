@@ -0,0 +1,112 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/izzuddinafif/fabric/platform/backend/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRamadanStarts(t *testing.T) {
+	testCases := []struct {
+		name        string
+		entries     []string
+		expected    []RamadanStart
+		expectedErr string
+	}{
+		{name: "None", entries: nil, expected: []RamadanStart{}},
+		{
+			name:     "Consecutive",
+			entries:  []string{"1446:2025-03-01", "1447:2026-02-18"},
+			expected: []RamadanStart{{HijriYear: 1446, Start: "2025-03-01"}, {HijriYear: 1447, Start: "2026-02-18"}},
+		},
+		{name: "MissingYear", entries: []string{"2025-03-01"}, expectedErr: "expected HIJRI_YEAR:YYYY-MM-DD"},
+		{name: "NonNumericYear", entries: []string{"H1446:2025-03-01"}, expectedErr: "expected HIJRI_YEAR:YYYY-MM-DD"},
+		{name: "BadDate", entries: []string{"1446:2025-02-30"}, expectedErr: "invalid Ramadan start date"},
+		{name: "SkippedYear", entries: []string{"1446:2025-03-01", "1448:2027-02-08"}, expectedErr: "does not follow 1446H"},
+		{name: "DateNotIncreasing", entries: []string{"1446:2025-03-01", "1447:2025-03-01"}, expectedErr: "does not follow 1446H"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			starts, err := ParseRamadanStarts(tc.entries)
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, starts)
+		})
+	}
+}
+
+func TestPledgeOccurrence(t *testing.T) {
+	ramadanStarts := []RamadanStart{{HijriYear: 1446, Start: "2025-03-01"}, {HijriYear: 1447, Start: "2026-02-18"}}
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	testCases := []struct {
+		name           string
+		schedule       string
+		start          time.Time
+		n              int
+		expectedDue    time.Time
+		expectedPeriod string
+		expectedOK     bool
+	}{
+		{name: "MonthlyFirst", schedule: "monthly", start: day(2025, time.January, 15), expectedDue: day(2025, time.January, 15), expectedPeriod: "2025-01", expectedOK: true},
+		{name: "MonthlyClamped", schedule: "monthly", start: day(2025, time.January, 31), n: 1, expectedDue: day(2025, time.February, 28), expectedPeriod: "2025-02", expectedOK: true},
+		{name: "YearlyLeapDay", schedule: "yearly", start: day(2024, time.February, 29), n: 1, expectedDue: day(2025, time.February, 28), expectedPeriod: "2025", expectedOK: true},
+		{name: "RamadanUpcoming", schedule: "ramadan", start: day(2025, time.January, 10), expectedDue: day(2025, time.March, 1), expectedPeriod: "1446H", expectedOK: true},
+		{name: "RamadanOnStartDay", schedule: "ramadan", start: time.Date(2025, time.March, 1, 10, 0, 0, 0, time.UTC), expectedDue: day(2025, time.March, 1), expectedPeriod: "1446H", expectedOK: true},
+		{name: "RamadanAfterStart", schedule: "ramadan", start: day(2025, time.March, 2), expectedDue: day(2026, time.February, 18), expectedPeriod: "1447H", expectedOK: true},
+		{name: "RamadanNext", schedule: "ramadan", start: day(2025, time.January, 10), n: 1, expectedDue: day(2026, time.February, 18), expectedPeriod: "1447H", expectedOK: true},
+		{name: "RamadanNotConfigured", schedule: "ramadan", start: day(2025, time.January, 10), n: 2},
+		{name: "RamadanPastLastConfigured", schedule: "ramadan", start: day(2026, time.March, 1)},
+		{name: "UnknownSchedule", schedule: "weekly", start: day(2025, time.January, 10)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			due, period, ok := pledgeOccurrence(tc.schedule, tc.start, tc.n, ramadanStarts)
+			require.Equal(t, tc.expectedOK, ok)
+			require.True(t, tc.expectedDue.Equal(due), "due %s, expected %s", due, tc.expectedDue)
+			require.Equal(t, tc.expectedPeriod, period)
+		})
+	}
+}
+
+func TestCreatePledgeRejectsFitrah(t *testing.T) {
+	s := &PledgeService{}
+	_, err := s.CreatePledge(models.CreatePledgeRequest{Name: "Ahmad", Phone: "081234567890", Amount: 45000, Type: "fitrah", Schedule: "ramadan"}, time.Now())
+
+	var contractErr *ContractError
+	require.ErrorAs(t, err, &contractErr)
+	require.Equal(t, CodeInvalidInput, contractErr.Code)
+	require.Equal(t, "type", contractErr.Field)
+}
+
+func TestCanRetryPeriod(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "DatabaseUnavailable", err: errors.New("failed to insert donation: connection refused"), expected: true},
+		{name: "Conflict", err: newConflictError("zakat ZKT-YDSF-MLG-202503-000001 already exists"), expected: true},
+		{name: "UnknownPaymentMethod", err: newInvalidInputError("payment_method", "YDSF Jatim does not accept payment method \"cash\"")},
+		{name: "OffRateFitrah", err: fmt.Errorf("%w: amount must be a multiple of Rp 45000 per soul for MALANG 1446H", ErrInvalidFitrahDonation)},
+		{name: "NoExchangeRate", err: fmt.Errorf("%w: no rate published for USD", ErrInvalidDenomination)},
+		{name: "ProgramNotFound", err: newNotFoundError("program PROG-2025-0001 not found")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, canRetryPeriod(tc.err))
+		})
+	}
+}
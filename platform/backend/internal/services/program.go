@@ -0,0 +1,92 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/models"
+	"github.com/izzuddinafif/fabric/platform/backend/pkg/database"
+	"gorm.io/gorm"
+)
+
+// ProgramService serves program analytics from the ledger, cached in Redis
+type ProgramService struct {
+	fabricService *FabricService
+	db            *gorm.DB
+	redis         *redis.Client
+	cacheTTL      time.Duration
+}
+
+// NewProgramService creates a new program service caching summaries for cacheTTL
+func NewProgramService(fabricService *FabricService, db *gorm.DB, redis *redis.Client, cacheTTL time.Duration) *ProgramService {
+	return &ProgramService{
+		fabricService: fabricService,
+		db:            db,
+		redis:         redis,
+		cacheTTL:      cacheTTL,
+	}
+}
+
+func programSummaryRedisKey(programID string) string {
+	return "program-summary:" + programID
+}
+
+// GetProgramSummary returns a program's campaign progress. Summaries are cached until a
+// transaction affecting the program invalidates them, or for at most the cache TTL so that
+// days remaining stay current.
+func (s *ProgramService) GetProgramSummary(programID string) (*ProgramSummary, error) {
+	redisKey := programSummaryRedisKey(programID)
+	if data, err := s.redis.Get(database.Ctx, redisKey).Bytes(); err == nil {
+		var summary ProgramSummary
+		if err := json.Unmarshal(data, &summary); err == nil {
+			return &summary, nil
+		}
+	} else if err != redis.Nil {
+		log.Printf("❌ Failed to read cached summary of program %s: %v", programID, err)
+	}
+
+	summary, err := s.fabricService.GetProgramSummary(programID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal program summary: %w", err)
+	}
+	if err := s.redis.Set(database.Ctx, redisKey, data, s.cacheTTL).Err(); err != nil {
+		log.Printf("❌ Failed to cache summary of program %s: %v", programID, err)
+	}
+
+	return summary, nil
+}
+
+// InvalidateSummary drops the cached summaries of the given programs. Empty IDs are ignored.
+func (s *ProgramService) InvalidateSummary(programIDs ...string) {
+	var keys []string
+	for _, programID := range programIDs {
+		if programID != "" {
+			keys = append(keys, programSummaryRedisKey(programID))
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	if err := s.redis.Del(database.Ctx, keys...).Err(); err != nil {
+		log.Printf("❌ Failed to invalidate program summaries %v: %v", programIDs, err)
+	}
+}
+
+// InvalidateDonationProgram drops the cached summary of the program a donation belongs to
+func (s *ProgramService) InvalidateDonationProgram(donationID string) {
+	var donation models.Donation
+	if err := s.db.Select("program_id").Where("id = ?", donationID).First(&donation).Error; err != nil {
+		log.Printf("❌ Failed to look up program of donation %s: %v", donationID, err)
+		return
+	}
+	s.InvalidateSummary(donation.ProgramID.String)
+}
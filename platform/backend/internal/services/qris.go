@@ -0,0 +1,198 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// QRIS (EMVCo merchant-presented mode) tags
+const (
+	qrisTagPointOfInitiation = "01"
+	qrisTagAmount            = "54"
+	qrisTagMerchantName      = "59"
+	qrisTagAdditionalData    = "62"
+	qrisTagCRC               = "63"
+
+	qrisDynamic = "12" // Static codes are "11"
+
+	// Subtag of the additional data field carrying the donation ID
+	qrisSubtagReferenceLabel = "05"
+)
+
+// ErrInvalidQRIS is returned for QRIS payloads that cannot be parsed or whose CRC does not match
+var ErrInvalidQRIS = errors.New("invalid QRIS payload")
+
+// qrisField is one tag-length-value field of a QRIS payload
+type qrisField struct {
+	Tag   string
+	Value string
+}
+
+// QRISPayment is what a dynamic QRIS payload asks the payer for
+type QRISPayment struct {
+	MerchantName string
+	Amount       float64 // IDR
+	Reference    string  // Donation ID
+}
+
+// QRISService generates dynamic QRIS payloads for donations from the merchant's static QRIS
+type QRISService struct {
+	merchant []qrisField
+}
+
+// NewQRISService creates a QRIS service from the static QRIS payload issued to the merchant
+func NewQRISService(staticPayload string) (*QRISService, error) {
+	fields, err := parseQRIS(staticPayload)
+	if err != nil {
+		return nil, err
+	}
+	return &QRISService{merchant: fields}, nil
+}
+
+// Generate returns a dynamic payload charging amount (whole rupiah) with the donation ID as its reference label
+func (s *QRISService) Generate(donationID string, amount float64) (string, error) {
+	if amount < 1 {
+		return "", fmt.Errorf("%w: amount must be at least Rp 1", ErrInvalidQRIS)
+	}
+	additionalData, err := encodeQRIS([]qrisField{{Tag: qrisSubtagReferenceLabel, Value: donationID}})
+	if err != nil {
+		return "", err
+	}
+
+	// Keep the merchant's fields in tag order, replacing the per-payment ones
+	fields := []qrisField{}
+	for _, field := range s.merchant {
+		switch field.Tag {
+		case qrisTagPointOfInitiation, qrisTagAmount, qrisTagAdditionalData, qrisTagCRC:
+			continue
+		}
+		fields = append(fields, field)
+	}
+	fields = append(fields,
+		qrisField{Tag: qrisTagPointOfInitiation, Value: qrisDynamic},
+		qrisField{Tag: qrisTagAmount, Value: strconv.FormatInt(int64(math.Round(amount)), 10)},
+		qrisField{Tag: qrisTagAdditionalData, Value: additionalData},
+	)
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].Tag < fields[j].Tag })
+
+	payload, err := encodeQRIS(fields)
+	if err != nil {
+		return "", err
+	}
+	payload += qrisTagCRC + "04"
+	return payload + qrisCRC(payload), nil
+}
+
+// QRISImage renders a QRIS payload as a PNG
+func QRISImage(payload string) ([]byte, error) {
+	png, err := qrcode.Encode(payload, qrcode.Medium, 320)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate QRIS image: %w", err)
+	}
+	return png, nil
+}
+
+// ParseQRISPayment decodes what a dynamic QRIS payload charges, checking its CRC
+func ParseQRISPayment(payload string) (*QRISPayment, error) {
+	fields, err := parseQRIS(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	payment := &QRISPayment{}
+	dynamic := false
+	for _, field := range fields {
+		switch field.Tag {
+		case qrisTagPointOfInitiation:
+			dynamic = field.Value == qrisDynamic
+		case qrisTagMerchantName:
+			payment.MerchantName = field.Value
+		case qrisTagAmount:
+			if payment.Amount, err = strconv.ParseFloat(field.Value, 64); err != nil {
+				return nil, fmt.Errorf("%w: amount %q", ErrInvalidQRIS, field.Value)
+			}
+		case qrisTagAdditionalData:
+			subfields, err := parseQRISFields(field.Value)
+			if err != nil {
+				return nil, err
+			}
+			for _, subfield := range subfields {
+				if subfield.Tag == qrisSubtagReferenceLabel {
+					payment.Reference = subfield.Value
+				}
+			}
+		}
+	}
+	if !dynamic || payment.Amount <= 0 || payment.Reference == "" {
+		return nil, fmt.Errorf("%w: not a dynamic payload with an amount and reference", ErrInvalidQRIS)
+	}
+	return payment, nil
+}
+
+// parseQRIS parses a complete payload, which must end in a matching CRC field
+func parseQRIS(payload string) ([]qrisField, error) {
+	if len(payload) < 8 || payload[len(payload)-8:len(payload)-4] != qrisTagCRC+"04" {
+		return nil, fmt.Errorf("%w: missing CRC", ErrInvalidQRIS)
+	}
+	if crc := qrisCRC(payload[:len(payload)-4]); !strings.EqualFold(crc, payload[len(payload)-4:]) {
+		return nil, fmt.Errorf("%w: CRC mismatch", ErrInvalidQRIS)
+	}
+
+	fields, err := parseQRISFields(payload)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 || fields[0].Tag != "00" || fields[0].Value != "01" {
+		return nil, fmt.Errorf("%w: payload format indicator must be 01", ErrInvalidQRIS)
+	}
+	return fields, nil
+}
+
+func parseQRISFields(data string) ([]qrisField, error) {
+	var fields []qrisField
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return nil, fmt.Errorf("%w: truncated field", ErrInvalidQRIS)
+		}
+		length, err := strconv.Atoi(data[2:4])
+		if err != nil || len(data) < 4+length {
+			return nil, fmt.Errorf("%w: bad length of tag %s", ErrInvalidQRIS, data[:2])
+		}
+		fields = append(fields, qrisField{Tag: data[:2], Value: data[4 : 4+length]})
+		data = data[4+length:]
+	}
+	return fields, nil
+}
+
+func encodeQRIS(fields []qrisField) (string, error) {
+	var b strings.Builder
+	for _, field := range fields {
+		if len(field.Value) > 99 {
+			return "", fmt.Errorf("%w: tag %s is longer than 99 characters", ErrInvalidQRIS, field.Tag)
+		}
+		fmt.Fprintf(&b, "%s%02d%s", field.Tag, len(field.Value), field.Value)
+	}
+	return b.String(), nil
+}
+
+// qrisCRC is the CRC-16/CCITT-FALSE (polynomial 0x1021, initial 0xFFFF) of data as 4 uppercase hex digits
+func qrisCRC(data string) string {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(data); i++ {
+		crc ^= uint16(data[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return fmt.Sprintf("%04X", crc)
+}
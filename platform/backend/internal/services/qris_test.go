@@ -0,0 +1,181 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testStaticQRIS is a static merchant QRIS, the default QRIS_MERCHANT_PAYLOAD
+const testStaticQRIS = "00020101021126620014ID.CO.YDSF.WWW01189360000000000000010211YDSFMLG00010303UMI51440014ID.CO.QRIS.WWW0215ID10200000000010303UMI5204866153033605802ID5911YDSF MALANG6006MALANG61056511163046320"
+
+func TestQRISCRC(t *testing.T) {
+	testCases := []struct {
+		data     string
+		expected string
+	}{
+		{"", "FFFF"},
+		{"123456789", "29B1"}, // CRC-16/CCITT-FALSE check value
+		{strings.TrimSuffix(testStaticQRIS, "6320"), "6320"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.data, func(t *testing.T) {
+			require.Equal(t, tc.expected, qrisCRC(tc.data))
+		})
+	}
+}
+
+func TestParseQRISFields(t *testing.T) {
+	testCases := []struct {
+		name        string
+		data        string
+		expected    []qrisField
+		expectedErr string
+	}{
+		{name: "Empty", data: ""},
+		{
+			name:     "Fields",
+			data:     "000201010212" + "5911YDSF MALANG",
+			expected: []qrisField{{Tag: "00", Value: "01"}, {Tag: "01", Value: "12"}, {Tag: "59", Value: "YDSF MALANG"}},
+		},
+		{name: "EmptyValue", data: "6200", expected: []qrisField{{Tag: "62", Value: ""}}},
+		{name: "Truncated", data: "000201" + "59", expectedErr: "truncated field"},
+		{name: "ValueTooShort", data: "5911YDSF", expectedErr: "bad length of tag 59"},
+		{name: "NonNumericLength", data: "59XXYDSF", expectedErr: "bad length of tag 59"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fields, err := parseQRISFields(tc.data)
+			if tc.expectedErr != "" {
+				require.ErrorIs(t, err, ErrInvalidQRIS)
+				require.Contains(t, err.Error(), tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, fields)
+		})
+	}
+}
+
+func TestEncodeQRIS(t *testing.T) {
+	encoded, err := encodeQRIS([]qrisField{{Tag: "00", Value: "01"}, {Tag: "54", Value: "150000"}, {Tag: "62", Value: ""}})
+	require.NoError(t, err)
+	require.Equal(t, "000201"+"5406150000"+"6200", encoded)
+
+	_, err = encodeQRIS([]qrisField{{Tag: "05", Value: strings.Repeat("x", 100)}})
+	require.ErrorIs(t, err, ErrInvalidQRIS)
+	require.Contains(t, err.Error(), "tag 05 is longer than 99 characters")
+}
+
+func TestParseQRIS(t *testing.T) {
+	withCRC := func(data string) string {
+		data += "6304"
+		return data + qrisCRC(data)
+	}
+
+	testCases := []struct {
+		name        string
+		payload     string
+		expectedErr string
+	}{
+		{name: "Valid", payload: testStaticQRIS},
+		{name: "LowercaseCRC", payload: strings.ToLower(withCRC("000201" + "5303360"))},
+		{name: "MissingCRC", payload: "000201", expectedErr: "missing CRC"},
+		{name: "CRCMismatch", payload: strings.Replace(testStaticQRIS, "MALANG6006", "MALANX6006", 1), expectedErr: "CRC mismatch"},
+		{name: "WrongFormatIndicator", payload: withCRC("000202"), expectedErr: "payload format indicator must be 01"},
+		{name: "FormatIndicatorNotFirst", payload: withCRC("5802ID" + "000201"), expectedErr: "payload format indicator must be 01"},
+		{name: "BadField", payload: withCRC("000201" + "59991"), expectedErr: "bad length of tag 59"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseQRIS(tc.payload)
+			if tc.expectedErr != "" {
+				require.ErrorIs(t, err, ErrInvalidQRIS)
+				require.Contains(t, err.Error(), tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestQRISGenerate(t *testing.T) {
+	const donationID = "ZKT-YDSF-MLG-202503-0001"
+	qrisService, err := NewQRISService(testStaticQRIS)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name           string
+		amount         float64
+		expectedAmount float64
+	}{
+		{name: "WholeRupiah", amount: 150000, expectedAmount: 150000},
+		{name: "RoundsDown", amount: 150000.4, expectedAmount: 150000},
+		{name: "RoundsUp", amount: 150000.5, expectedAmount: 150001},
+		{name: "Minimum", amount: 1, expectedAmount: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			payload, err := qrisService.Generate(donationID, tc.amount)
+			require.NoError(t, err)
+
+			payment, err := ParseQRISPayment(payload)
+			require.NoError(t, err)
+			require.Equal(t, &QRISPayment{MerchantName: "YDSF MALANG", Amount: tc.expectedAmount, Reference: donationID}, payment)
+
+			// The merchant's fields are kept and the payload stays in tag order
+			fields, err := parseQRIS(payload)
+			require.NoError(t, err)
+			for i := 1; i < len(fields); i++ {
+				require.Less(t, fields[i-1].Tag, fields[i].Tag)
+			}
+			require.Contains(t, payload, "26620014ID.CO.YDSF.WWW")
+			require.Contains(t, payload, "010212")
+		})
+	}
+
+	t.Run("ReplacesDynamicFields", func(t *testing.T) {
+		first, err := qrisService.Generate(donationID, 150000)
+		require.NoError(t, err)
+		regenerated, err := NewQRISService(first)
+		require.NoError(t, err)
+
+		second, err := regenerated.Generate("ZKT-YDSF-MLG-202503-0002", 250000)
+		require.NoError(t, err)
+		payment, err := ParseQRISPayment(second)
+		require.NoError(t, err)
+		require.Equal(t, float64(250000), payment.Amount)
+		require.Equal(t, "ZKT-YDSF-MLG-202503-0002", payment.Reference)
+		require.Equal(t, 1, strings.Count(second, "5406250000"))
+	})
+
+	t.Run("AmountBelowOneRupiah", func(t *testing.T) {
+		_, err := qrisService.Generate(donationID, 0.5)
+		require.ErrorIs(t, err, ErrInvalidQRIS)
+	})
+
+	t.Run("ReferenceTooLong", func(t *testing.T) {
+		_, err := qrisService.Generate(strings.Repeat("Z", 96), 150000)
+		require.ErrorIs(t, err, ErrInvalidQRIS)
+	})
+}
+
+func TestParseQRISPayment(t *testing.T) {
+	t.Run("StaticPayload", func(t *testing.T) {
+		_, err := ParseQRISPayment(testStaticQRIS)
+		require.ErrorIs(t, err, ErrInvalidQRIS)
+		require.Contains(t, err.Error(), "not a dynamic payload")
+	})
+
+	t.Run("InvalidAmount", func(t *testing.T) {
+		payload := "000201" + "010212" + "5403abc" + "62080504ZKT1" + "6304"
+		_, err := ParseQRISPayment(payload + qrisCRC(payload))
+		require.ErrorIs(t, err, ErrInvalidQRIS)
+		require.Contains(t, err.Error(), `amount "abc"`)
+	})
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// RateFeedService publishes exchange rates read from a local JSON file to the ledger.
+// It stands in for a market data feed during development and must run under a Fabric
+// identity with the zakat.ratePublisher=true attribute.
+type RateFeedService struct {
+	fabricService *FabricService
+	path          string
+}
+
+// rateFeedFile is the format of the rate feed file
+type rateFeedFile struct {
+	Source string          `json:"source"` // Recorded on the ledger with every rate
+	Rates  []rateFeedEntry `json:"rates"`
+}
+
+type rateFeedEntry struct {
+	Denomination  string  `json:"denomination"`   // USD, SAR, XAU_G, ...
+	RateIDR       float64 `json:"rate_idr"`       // IDR per unit
+	EffectiveDate string  `json:"effective_date"` // YYYY-MM-DD; defaults to the day of publishing
+}
+
+// NewRateFeedService creates a rate feed publishing the rates in the file at path
+func NewRateFeedService(fabricService *FabricService, path string) *RateFeedService {
+	return &RateFeedService{
+		fabricService: fabricService,
+		path:          path,
+	}
+}
+
+// Start publishes the file's rates now and then every interval. The file is re-read each
+// time, so rates can be edited while the feed is running.
+func (s *RateFeedService) Start(interval time.Duration) {
+	log.Printf("🕒 Rate feed publishing %s every %v", s.path, interval)
+
+	go func() {
+		s.publishAndLog(time.Now())
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			s.publishAndLog(now)
+		}
+	}()
+}
+
+func (s *RateFeedService) publishAndLog(now time.Time) {
+	if _, err := s.PublishRates(now); err != nil {
+		log.Printf("❌ Rate feed: %v", err)
+	}
+}
+
+// PublishRates publishes every rate in the file, dated now unless the entry has its own
+// effective date, and returns how many were published. Publishing the same day twice replaces
+// that day's rate, so running the feed repeatedly is safe.
+func (s *RateFeedService) PublishRates(now time.Time) (int, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read rate feed file: %w", err)
+	}
+
+	var feed rateFeedFile
+	if err := json.Unmarshal(data, &feed); err != nil {
+		return 0, fmt.Errorf("failed to parse rate feed file %s: %w", s.path, err)
+	}
+	if feed.Source == "" {
+		feed.Source = "file:" + s.path
+	}
+
+	published := 0
+	for _, entry := range feed.Rates {
+		effectiveDate := entry.EffectiveDate
+		if effectiveDate == "" {
+			effectiveDate = now.Format("2006-01-02")
+		}
+
+		denomination := strings.ToUpper(entry.Denomination)
+		if err := s.fabricService.PublishExchangeRate(denomination, entry.RateIDR, effectiveDate, feed.Source); err != nil {
+			log.Printf("❌ Failed to publish %s rate: %v", denomination, err)
+			continue
+		}
+		published++
+	}
+
+	log.Printf("✅ Rate feed published %d of %d rates from %s", published, len(feed.Rates), s.path)
+	if published < len(feed.Rates) {
+		return published, fmt.Errorf("%d of %d rates could not be published", len(feed.Rates)-published, len(feed.Rates))
+	}
+	return published, nil
+}
@@ -0,0 +1,405 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/izzuddinafif/fabric/platform/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// Reconciliation run statuses and triggers
+const (
+	ReconciliationRunning   = "running"
+	ReconciliationCompleted = "completed"
+	ReconciliationFailed    = "failed"
+
+	ReconciliationScheduled = "schedule"
+	ReconciliationManual    = "manual"
+)
+
+// Reconciliation mismatch categories
+const (
+	MismatchMissingInDB       = "missing_in_db"     // On the ledger, not in donations
+	MismatchMissingOnLedger   = "missing_on_ledger" // Synced in donations, not on the ledger
+	MismatchStatus            = "status"
+	MismatchAmount            = "amount"
+	MismatchDistributedAmount = "distributed_amount"
+)
+
+// maxStoredMismatches caps the mismatches kept with a run; its counts always cover all of them
+const maxStoredMismatches = 1000
+
+// ledgerStatusRank orders zakat statuses; a donation only ever moves forward through them
+var ledgerStatusRank = map[string]int{
+	"pending":     0,
+	"collected":   1,
+	"distributed": 2,
+}
+
+// ReconciliationOptions tune reconciliation runs
+type ReconciliationOptions struct {
+	PageSize int           // Zakat read from the ledger and donations read from Postgres per page
+	Grace    time.Duration // Records this recent are skipped; the outbox or indexer may still be syncing them
+}
+
+// ReconciliationService compares the zakat on the ledger with the donations table and records
+// the differences by category. Donations whose status is behind the ledger are moved forward;
+// every other mismatch is only reported, as fixing it needs an operator to decide which side
+// is wrong.
+type ReconciliationService struct {
+	fabricService *FabricService
+	db            *gorm.DB
+	options       ReconciliationOptions
+
+	mu      sync.Mutex
+	running bool
+}
+
+// NewReconciliationService creates a new reconciliation service
+func NewReconciliationService(fabricService *FabricService, db *gorm.DB, options ReconciliationOptions) *ReconciliationService {
+	return &ReconciliationService{
+		fabricService: fabricService,
+		db:            db,
+		options:       options,
+	}
+}
+
+// Start runs a reconciliation every interval
+func (s *ReconciliationService) Start(interval time.Duration) {
+	log.Printf("🕒 Reconciliation scheduled every %v", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			run, err := s.begin(ReconciliationScheduled, "system")
+			if err != nil {
+				log.Printf("⚠️ Scheduled reconciliation skipped: %v", err)
+				continue
+			}
+			s.run(run)
+		}
+	}()
+}
+
+// Trigger starts a reconciliation in the background and returns its run, still running
+func (s *ReconciliationService) Trigger(triggeredBy string) (*models.ReconciliationRun, error) {
+	run, err := s.begin(ReconciliationManual, triggeredBy)
+	if err != nil {
+		return nil, err
+	}
+
+	started := *run
+	go s.run(run)
+	return &started, nil
+}
+
+// ListRuns returns the most recent runs, newest first, without their mismatches
+func (s *ReconciliationService) ListRuns(limit int) ([]models.ReconciliationRun, error) {
+	var runs []models.ReconciliationRun
+	err := s.db.Omit("mismatch_details").Order("started_at DESC").Limit(limit).Find(&runs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reconciliation runs: %w", err)
+	}
+	return runs, nil
+}
+
+// GetRun returns a run with its mismatches
+func (s *ReconciliationService) GetRun(id uuid.UUID) (*models.ReconciliationRun, error) {
+	var run models.ReconciliationRun
+	err := s.db.Where("id = ?", id).First(&run).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, newNotFoundError("reconciliation run %s not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reconciliation run: %w", err)
+	}
+
+	if run.MismatchDetails != "" {
+		if err := json.Unmarshal([]byte(run.MismatchDetails), &run.Mismatches); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal reconciliation mismatches: %w", err)
+		}
+	}
+	return &run, nil
+}
+
+// LatestRun returns the most recent run with its mismatches, or nil before the first run
+func (s *ReconciliationService) LatestRun() (*models.ReconciliationRun, error) {
+	runs, err := s.ListRuns(1)
+	if err != nil || len(runs) == 0 {
+		return nil, err
+	}
+	return s.GetRun(runs[0].ID)
+}
+
+// begin records a new run. Only one run is in progress at a time.
+func (s *ReconciliationService) begin(trigger, triggeredBy string) (*models.ReconciliationRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return nil, newConflictError("a reconciliation run is already in progress")
+	}
+
+	run := &models.ReconciliationRun{
+		ID:          uuid.New(),
+		Trigger:     trigger,
+		TriggeredBy: triggeredBy,
+		Status:      ReconciliationRunning,
+		StartedAt:   time.Now(),
+
+		MismatchDetails: "[]",
+	}
+	if err := s.db.Create(run).Error; err != nil {
+		return nil, fmt.Errorf("failed to create reconciliation run: %w", err)
+	}
+
+	s.running = true
+	return run, nil
+}
+
+// run reconciles and saves the outcome of the run
+func (s *ReconciliationService) run(run *models.ReconciliationRun) {
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+	}()
+
+	log.Printf("🔍 Reconciliation %s started", run.ID)
+	reconciler := &reconciler{
+		service:    s,
+		run:        run,
+		cutoff:     run.StartedAt.Add(-s.options.Grace),
+		ledgerIDs:  make(map[string]struct{}),
+		mismatches: []models.ReconciliationMismatch{},
+	}
+
+	err := reconciler.reconcile()
+	now := time.Now()
+	run.CompletedAt = &now
+	run.Status = ReconciliationCompleted
+	if err != nil {
+		run.Status = ReconciliationFailed
+		run.Error = err.Error()
+		log.Printf("❌ Reconciliation %s failed: %v", run.ID, err)
+	}
+
+	details, jsonErr := json.Marshal(reconciler.mismatches)
+	if jsonErr != nil {
+		log.Printf("❌ Failed to marshal reconciliation mismatches: %v", jsonErr)
+		details = []byte("[]")
+	}
+	run.MismatchDetails = string(details)
+
+	if err := s.db.Save(run).Error; err != nil {
+		log.Printf("❌ Failed to save reconciliation run %s: %v", run.ID, err)
+		return
+	}
+	if run.Status == ReconciliationCompleted {
+		log.Printf("✅ Reconciliation %s: %d ledger records, %d donations, %d fixed",
+			run.ID, run.LedgerCount, run.DatabaseCount, run.FixedCount)
+	}
+}
+
+// reconciler holds the state of one run
+type reconciler struct {
+	service    *ReconciliationService
+	run        *models.ReconciliationRun
+	cutoff     time.Time // Records created after it are skipped
+	ledgerIDs  map[string]struct{}
+	mismatches []models.ReconciliationMismatch
+}
+
+// reconcile pages through the ledger, comparing each zakat with its donation, then through the
+// synced donations to find those the ledger does not have
+func (r *reconciler) reconcile() error {
+	bookmark := ""
+	for {
+		page, err := r.service.fabricService.GetZakatPage(r.service.options.PageSize, bookmark)
+		if err != nil {
+			return err
+		}
+		if err := r.compareLedgerPage(page.Records); err != nil {
+			return err
+		}
+		if page.Bookmark == "" {
+			break
+		}
+		bookmark = page.Bookmark
+	}
+
+	lastID := ""
+	for {
+		var donations []models.Donation
+		err := r.service.db.Select("id", "created_at").
+			Where("id > ? AND sync_status = ? AND created_at < ?", lastID, "synced", r.cutoff).
+			Order("id").Limit(r.service.options.PageSize).Find(&donations).Error
+		if err != nil {
+			return fmt.Errorf("failed to read donations: %w", err)
+		}
+
+		for _, donation := range donations {
+			r.run.DatabaseCount++
+			if _, ok := r.ledgerIDs[donation.ID]; !ok {
+				r.record(donation.ID, MismatchMissingOnLedger, "", "synced", false)
+			}
+		}
+		if len(donations) < r.service.options.PageSize {
+			return nil
+		}
+		lastID = donations[len(donations)-1].ID
+	}
+}
+
+// compareLedgerPage compares one page of ledger zakat with their donations
+func (r *reconciler) compareLedgerPage(records []json.RawMessage) error {
+	zakats := make([]ledgerZakat, 0, len(records))
+	ids := make([]string, 0, len(records))
+	for _, record := range records {
+		var zakat ledgerZakat
+		if err := json.Unmarshal(record, &zakat); err != nil {
+			return fmt.Errorf("failed to unmarshal ledger zakat: %w", err)
+		}
+		r.run.LedgerCount++
+		r.ledgerIDs[zakat.ID] = struct{}{}
+		zakats = append(zakats, zakat)
+		ids = append(ids, zakat.ID)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var donations []models.Donation
+	if err := r.service.db.Where("id IN ?", ids).Find(&donations).Error; err != nil {
+		return fmt.Errorf("failed to read donations: %w", err)
+	}
+	byID := make(map[string]*models.Donation, len(donations))
+	for i := range donations {
+		byID[donations[i].ID] = &donations[i]
+	}
+
+	for _, zakat := range zakats {
+		donation, ok := byID[zakat.ID]
+		if !ok {
+			// Zakat created elsewhere reach Postgres through the indexer, which may be behind
+			if createdAt := ledgerTime(zakat.Timestamp); createdAt == nil || createdAt.Before(r.cutoff) {
+				r.record(zakat.ID, MismatchMissingInDB, zakat.Status, "", false)
+			}
+			continue
+		}
+		// A ledger step is still in the outbox, so the two sides are expected to differ
+		if donation.SyncStatus == "pending_sync" {
+			continue
+		}
+		if err := r.compare(zakat, donation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compare records the differences between a zakat and its donation
+func (r *reconciler) compare(zakat ledgerZakat, donation *models.Donation) error {
+	if !amountsEqual(zakat.Amount, donation.Amount) {
+		r.record(zakat.ID, MismatchAmount, formatAmount(zakat.Amount), formatAmount(donation.Amount), false)
+	}
+	if !amountsEqual(zakat.Distribution, donation.DistributedAmount) {
+		r.record(zakat.ID, MismatchDistributedAmount,
+			formatAmount(zakat.Distribution), formatAmount(donation.DistributedAmount), false)
+	}
+
+	if zakat.Status == donation.BlockchainStatus {
+		return nil
+	}
+	ledgerRank, known := ledgerStatusRank[zakat.Status]
+	if !known || ledgerRank < ledgerStatusRank[donation.BlockchainStatus] {
+		r.record(zakat.ID, MismatchStatus, zakat.Status, donation.BlockchainStatus, false)
+		return nil
+	}
+
+	fixed, err := r.advanceStatus(zakat, donation)
+	if err != nil {
+		return err
+	}
+	r.record(zakat.ID, MismatchStatus, zakat.Status, donation.BlockchainStatus, fixed)
+	return nil
+}
+
+// advanceStatus moves a donation whose status is behind the ledger up to it, filling in the
+// ledger's validation and distribution details it is missing. It reports false when the
+// donation changed since it was read.
+func (r *reconciler) advanceStatus(zakat ledgerZakat, donation *models.Donation) (bool, error) {
+	result := r.service.db.Exec(`
+UPDATE donations SET
+    blockchain_status = @status,
+    payment_reference = COALESCE(payment_reference, NULLIF(@receipt_number, '')),
+    validated_at = COALESCE(validated_at, @validated_at),
+    validated_by = COALESCE(validated_by, NULLIF(@validated_by, '')),
+    blockchain_tx_id = COALESCE(blockchain_tx_id, NULLIF(@validation_tx_id, '')),
+    distributed_at = COALESCE(distributed_at, @distributed_at),
+    distributed_by = COALESCE(distributed_by, NULLIF(@distributed_by, '')),
+    updated_at = NOW()
+WHERE id = @id AND blockchain_status = @old_status AND sync_status = 'synced'`,
+		map[string]interface{}{
+			"id":               zakat.ID,
+			"status":           zakat.Status,
+			"old_status":       donation.BlockchainStatus,
+			"receipt_number":   zakat.ReceiptNumber,
+			"validated_at":     ledgerTime(zakat.ValidationDate),
+			"validated_by":     zakat.ValidatedBy,
+			"validation_tx_id": zakat.ValidationTxID,
+			"distributed_at":   ledgerTime(zakat.DistributedAt),
+			"distributed_by":   zakat.DistributedBy,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to update status of donation %s: %w", zakat.ID, result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// record counts a mismatch and keeps it, up to maxStoredMismatches
+func (r *reconciler) record(donationID, category, ledger, database string, fixed bool) {
+	switch category {
+	case MismatchMissingInDB:
+		r.run.MissingInDB++
+	case MismatchMissingOnLedger:
+		r.run.MissingOnLedger++
+	case MismatchStatus:
+		r.run.StatusMismatches++
+	case MismatchAmount:
+		r.run.AmountMismatches++
+	case MismatchDistributedAmount:
+		r.run.DistributedMismatches++
+	}
+	if fixed {
+		r.run.FixedCount++
+	}
+
+	if len(r.mismatches) < maxStoredMismatches {
+		r.mismatches = append(r.mismatches, models.ReconciliationMismatch{
+			DonationID: donationID,
+			Category:   category,
+			Ledger:     ledger,
+			Database:   database,
+			Fixed:      fixed,
+		})
+	}
+}
+
+// amountsEqual compares IDR amounts to the cent
+func amountsEqual(a, b float64) bool {
+	return math.Abs(a-b) < 0.005
+}
+
+// formatAmount formats an IDR amount for a mismatch
+func formatAmount(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
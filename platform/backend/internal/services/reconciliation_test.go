@@ -0,0 +1,109 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/izzuddinafif/fabric/platform/backend/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcilerCompare(t *testing.T) {
+	const donationID = "ZKT-YDSF-MLG-202503-0001"
+
+	testCases := []struct {
+		name          string
+		zakat         ledgerZakat
+		donation      models.Donation
+		expected      []models.ReconciliationMismatch
+		expectedFixed int
+		expectUpdate  bool
+	}{
+		{
+			name:     "InSync",
+			zakat:    ledgerZakat{ID: donationID, Amount: 150000, Status: "collected"},
+			donation: models.Donation{ID: donationID, Amount: 150000, BlockchainStatus: "collected"},
+		},
+		{
+			name:     "AmountWithinACent",
+			zakat:    ledgerZakat{ID: donationID, Amount: 150000.004, Status: "pending"},
+			donation: models.Donation{ID: donationID, Amount: 150000, BlockchainStatus: "pending"},
+		},
+		{
+			name:     "Amount",
+			zakat:    ledgerZakat{ID: donationID, Amount: 150000, Status: "pending"},
+			donation: models.Donation{ID: donationID, Amount: 15000, BlockchainStatus: "pending"},
+			expected: []models.ReconciliationMismatch{
+				{DonationID: donationID, Category: MismatchAmount, Ledger: "150000.00", Database: "15000.00"},
+			},
+		},
+		{
+			name:     "DistributedAmount",
+			zakat:    ledgerZakat{ID: donationID, Amount: 150000, Distribution: 100000, Status: "distributed"},
+			donation: models.Donation{ID: donationID, Amount: 150000, DistributedAmount: 50000, BlockchainStatus: "distributed"},
+			expected: []models.ReconciliationMismatch{
+				{DonationID: donationID, Category: MismatchDistributedAmount, Ledger: "100000.00", Database: "50000.00"},
+			},
+		},
+		{
+			name:     "DatabaseBehindLedger",
+			zakat:    ledgerZakat{ID: donationID, Amount: 150000, Status: "collected", ReceiptNumber: "RCPT-1"},
+			donation: models.Donation{ID: donationID, Amount: 150000, BlockchainStatus: "pending"},
+			expected: []models.ReconciliationMismatch{
+				{DonationID: donationID, Category: MismatchStatus, Ledger: "collected", Database: "pending", Fixed: true},
+			},
+			expectedFixed: 1,
+			expectUpdate:  true,
+		},
+		{
+			name:     "DatabaseAheadOfLedger",
+			zakat:    ledgerZakat{ID: donationID, Amount: 150000, Status: "pending"},
+			donation: models.Donation{ID: donationID, Amount: 150000, BlockchainStatus: "collected"},
+			expected: []models.ReconciliationMismatch{
+				{DonationID: donationID, Category: MismatchStatus, Ledger: "pending", Database: "collected"},
+			},
+		},
+		{
+			name:     "UnknownLedgerStatus",
+			zakat:    ledgerZakat{ID: donationID, Amount: 150000, Status: "refunded"},
+			donation: models.Donation{ID: donationID, Amount: 150000, BlockchainStatus: "pending"},
+			expected: []models.ReconciliationMismatch{
+				{DonationID: donationID, Category: MismatchStatus, Ledger: "refunded", Database: "pending"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, conn := newRecordingDB(t)
+			r := &reconciler{
+				service: NewReconciliationService(nil, db, ReconciliationOptions{}),
+				run:     &models.ReconciliationRun{},
+			}
+
+			require.NoError(t, r.compare(tc.zakat, &tc.donation))
+			require.Equal(t, tc.expected, r.mismatches)
+			require.Equal(t, tc.expectedFixed, r.run.FixedCount)
+
+			updated := false
+			for _, statement := range conn.recorded() {
+				updated = updated || strings.Contains(statement, "UPDATE donations SET")
+			}
+			require.Equal(t, tc.expectUpdate, updated)
+		})
+	}
+}
+
+func TestReconcilerRecord(t *testing.T) {
+	r := &reconciler{run: &models.ReconciliationRun{}}
+	for i := 0; i < maxStoredMismatches+5; i++ {
+		r.record("ZKT-YDSF-MLG-202503-0001", MismatchMissingInDB, "pending", "", false)
+	}
+	r.record("ZKT-YDSF-MLG-202503-0002", MismatchStatus, "collected", "pending", true)
+
+	// Counts cover every mismatch; only the first maxStoredMismatches are kept
+	require.Equal(t, maxStoredMismatches+5, r.run.MissingInDB)
+	require.Equal(t, 1, r.run.StatusMismatches)
+	require.Equal(t, 1, r.run.FixedCount)
+	require.Len(t, r.mismatches, maxStoredMismatches)
+}
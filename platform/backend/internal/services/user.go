@@ -3,6 +3,7 @@ package services
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
@@ -66,7 +67,7 @@ func (s *UserService) GetUserByID(id uuid.UUID) (*models.User, error) {
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user not found")
+			return nil, newNotFoundError("user %s not found", id)
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -74,6 +75,73 @@ func (s *UserService) GetUserByID(id uuid.UUID) (*models.User, error) {
 	return user, nil
 }
 
+// FindOrCreateDonor returns the user with a phone number or email address (exactly one is given),
+// registering them as a donor when there is none. The name of a new donor is taken from their
+// latest donation. Staff accounts cannot be signed in as donors.
+func (s *UserService) FindOrCreateDonor(phone, email string) (*models.User, error) {
+	user, err := s.findByPhoneOrEmail(phone, email)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		name := "Donatur"
+		err := s.db.QueryRow(`SELECT donor_name FROM donations
+			  WHERE ($1 <> '' AND donor_phone = $1) OR ($2 <> '' AND LOWER(donor_email) = $2)
+			  ORDER BY created_at DESC LIMIT 1`, phone, email).Scan(&name)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to get donor name: %w", err)
+		}
+
+		// A concurrent sign-in may register the donor first
+		now := time.Now()
+		_, err = s.db.Exec(`INSERT INTO users (id, phone, email, name, role, created_at, updated_at)
+			  VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, 'donor', $5, $5)
+			  ON CONFLICT DO NOTHING`, uuid.New(), phone, email, name, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create donor: %w", err)
+		}
+
+		if user, err = s.findByPhoneOrEmail(phone, email); err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, fmt.Errorf("failed to create donor")
+		}
+	}
+
+	if user.Role != "donor" {
+		return nil, newUnauthorizedError("staff accounts sign in with a password")
+	}
+	return user, nil
+}
+
+// findByPhoneOrEmail returns the user with a phone number or email address, or nil
+func (s *UserService) findByPhoneOrEmail(phone, email string) (*models.User, error) {
+	query := `SELECT id, phone, email, name, role, referral_code, organization, created_at, updated_at
+			  FROM users WHERE phone = $1 ORDER BY created_at LIMIT 1`
+	value := phone
+	if email != "" {
+		// Staff accounts first, so a donor account can never shadow one
+		query = `SELECT id, phone, email, name, role, referral_code, organization, created_at, updated_at
+			  FROM users WHERE LOWER(email) = $1 ORDER BY role = 'donor', created_at LIMIT 1`
+		value = email
+	}
+
+	user := &models.User{}
+	err := s.db.QueryRow(query, value).Scan(
+		&user.ID, &user.Phone, &user.Email, &user.Name, &user.Role,
+		&user.ReferralCode, &user.Organization, &user.CreatedAt, &user.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
 // CreateUser creates a new user
 func (s *UserService) CreateUser(user *models.User, password string) error {
 	// Hash password
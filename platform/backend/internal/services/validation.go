@@ -1,6 +1,8 @@
 package services
 
 import (
+	"encoding/json"
+	"errors"
 "fmt"
 "log"
 "time"
@@ -9,47 +11,130 @@ import (
 "gorm.io/gorm"
 )
 
-// ValidationService handles auto-validation of donations
+// ValidationService validates donations on the ledger once their payment is confirmed
 type ValidationService struct {
 fabricContract *gateway.Contract
 db             *gorm.DB
 emailService   *EmailService
-delayDuration  time.Duration
+	pledgeService  *PledgeService
+	programService *ProgramService
+	jobQueue       *JobQueue
 }
 
 // NewValidationService creates a new validation service
-func NewValidationService(fabricContract *gateway.Contract, db *gorm.DB, emailService *EmailService, delay time.Duration) *ValidationService {
+func NewValidationService(fabricContract *gateway.Contract, db *gorm.DB, emailService *EmailService) *ValidationService {
 return &ValidationService{
 fabricContract: fabricContract,
 db:             db,
 emailService:   emailService,
-delayDuration:  delay,
 }
 }
 
-// ScheduleAutoValidation schedules automatic validation for a donation after the configured delay
-func (vs *ValidationService) ScheduleAutoValidation(donationID string) {
-log.Printf("🕒 Scheduling auto-validation for donation %s in %v", donationID, vs.delayDuration)
+// SetPledgeService sets the pledge service used to record fulfilment of pledge donations
+func (vs *ValidationService) SetPledgeService(pledgeService *PledgeService) {
+	vs.pledgeService = pledgeService
+}
+
+// SetProgramService sets the program service whose cached summaries validations invalidate
+func (vs *ValidationService) SetProgramService(programService *ProgramService) {
+	vs.programService = programService
+}
+
+// AutoValidateJob is the job type validating a donation whose payment the gateway confirmed
+const AutoValidateJob = "auto_validate"
+
+type autoValidatePayload struct {
+	DonationID string `json:"donation_id"`
+	PaymentRef string `json:"payment_ref"`
+}
+
+// SetJobQueue sets the queue auto-validations run on and registers their handler
+func (vs *ValidationService) SetJobQueue(jobQueue *JobQueue) {
+	vs.jobQueue = jobQueue
+	jobQueue.Register(AutoValidateJob, func(payload json.RawMessage) error {
+		var job autoValidatePayload
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return fmt.Errorf("failed to unmarshal auto-validation job: %w", err)
+		}
+		return vs.AutoValidate(job.DonationID, job.PaymentRef)
+	})
+}
 
-go func() {
-// Wait for the mock payment delay
-time.Sleep(vs.delayDuration)
+// EnqueueAutoValidation queues the validation of a paid donation, so it survives restarts and is
+// retried on ledger errors. Without a job queue the donation is validated at once.
+func (vs *ValidationService) EnqueueAutoValidation(donationID, paymentRef string) error {
+	if vs.jobQueue == nil {
+		return vs.AutoValidate(donationID, paymentRef)
+	}
+	return vs.jobQueue.Enqueue("auto-validate:"+donationID, AutoValidateJob, autoValidatePayload{
+		DonationID: donationID,
+		PaymentRef: paymentRef,
+	}, 0)
+}
 
-log.Printf("⚡ Starting auto-validation for donation %s", donationID)
+// AutoValidate validates a donation whose payment the gateway confirmed, recording the
+// gateway's reference as its receipt number. It is idempotent: when an earlier attempt already
+// validated the zakat on the ledger, only the database record and notifications are completed.
+func (vs *ValidationService) AutoValidate(donationID, paymentRef string) error {
+	log.Printf("⚡ Auto-validating donation %s with payment %s", donationID, paymentRef)
 
-// Call the chaincode AutoValidatePayment function
-paymentRef := fmt.Sprintf("MOCK-PAYMENT-%d", time.Now().UnixNano())
 _, err := vs.fabricContract.SubmitTransaction("AutoValidatePayment", donationID, paymentRef)
+	submitted := err == nil
 if err != nil {
-log.Printf("❌ Failed to auto-validate donation %s: %v", donationID, err)
-return
+		err = parseContractError(err)
+		if !errors.Is(err, ErrInvalidState) {
+			return fmt.Errorf("failed to auto-validate donation %s: %w", donationID, err)
+		}
+		status, queryErr := vs.ledgerStatus(donationID)
+		if queryErr != nil {
+			return fmt.Errorf("failed to auto-validate donation %s: %w", donationID, queryErr)
+		}
+		if status == "pending" {
+			return fmt.Errorf("failed to auto-validate donation %s: %w", donationID, err)
+		}
+		log.Printf("🔁 Donation %s is already %s on the ledger", donationID, status)
+	}
+
+	// The attempt that validated the zakat sends the notifications, even if the block indexer
+	// recorded the validation first. A rerun only sends them if it moves the record out of pending.
+	now := time.Now()
+	result := vs.db.Table("donations").Where("id = ? AND blockchain_status = ?", donationID, "pending").Updates(map[string]interface{}{
+		"blockchain_status": "collected",
+		"payment_reference": paymentRef,
+		"validated_by":      "system-auto",
+		"validated_at":      now,
+		"updated_at":        now,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update donation record for %s: %w", donationID, result.Error)
+	}
+	if result.RowsAffected == 0 && !submitted {
+		log.Printf("🔁 Donation %s was already recorded as validated", donationID)
+		return nil
 }
 
 log.Printf("✅ Successfully auto-validated donation %s", donationID)
 
 // Send validation confirmation email
 vs.sendValidationEmail(donationID)
-}()
+	vs.invalidateProgramSummary(donationID)
+	vs.recordPledgeFulfilment(donationID)
+	return nil
+}
+
+// ledgerStatus returns a zakat's status on the ledger
+func (vs *ValidationService) ledgerStatus(donationID string) (string, error) {
+	result, err := vs.fabricContract.EvaluateTransaction("QueryZakat", donationID)
+	if err != nil {
+		return "", fmt.Errorf("failed to query zakat %s: %w", donationID, parseContractError(err))
+	}
+	var zakat struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(result, &zakat); err != nil {
+		return "", fmt.Errorf("failed to unmarshal zakat %s: %w", donationID, err)
+	}
+	return zakat.Status, nil
 }
 
 // sendValidationEmail sends email notification after successful validation
@@ -72,13 +157,10 @@ func (vs *ValidationService) sendValidationEmail(donationID string) {
 		return
 	}
 
-	// Generate receipt number (mock)
-	receiptNumber := fmt.Sprintf("MOCK-PAYMENT-REF-%d", time.Now().UnixNano())
-
 // Send validation email
 err = vs.emailService.SendDonationValidatedEmail(
 donation.DonorEmail.String,
-"Donor", // We don't have donor name in this simplified struct
+		donation.DonorName, // Real name, even for anonymous donations
 donationID,
 donation.Amount,
 )
@@ -107,6 +189,7 @@ type Donation struct {
 		String string
 		Valid  bool
 	} `gorm:"column:donor_email"`
+	DonorName string  `gorm:"column:donor_name"`
 	Amount float64 `gorm:"column:amount"`
 }
 
@@ -123,10 +206,30 @@ func (vs *ValidationService) ManualValidation(donationID string, receiptNumber s
 
 	// Send validation email
 	vs.sendValidationEmail(donationID)
+	vs.invalidateProgramSummary(donationID)
+	vs.recordPledgeFulfilment(donationID)
 
 	return nil
 }
 
+// invalidateProgramSummary drops the cached summary of a validated donation's program
+func (vs *ValidationService) invalidateProgramSummary(donationID string) {
+	if vs.programService == nil {
+		return
+	}
+	vs.programService.InvalidateDonationProgram(donationID)
+}
+
+// recordPledgeFulfilment records a validated donation against its pledge, if it belongs to one
+func (vs *ValidationService) recordPledgeFulfilment(donationID string) {
+	if vs.pledgeService == nil {
+		return
+	}
+	if err := vs.pledgeService.RecordFulfilment(donationID); err != nil {
+		log.Printf("❌ Failed to record pledge fulfilment for donation %s: %v", donationID, err)
+	}
+}
+
 // GetValidationStatus checks if a donation has been validated
 func (vs *ValidationService) GetValidationStatus(donationID string) (bool, error) {
 	// Query the chaincode to get current status
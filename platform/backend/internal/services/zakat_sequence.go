@@ -0,0 +1,77 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/izzuddinafif/fabric/platform/backend/pkg/database"
+	"gorm.io/gorm"
+)
+
+// zakatNumberZone is the time zone the months of zakat numbers follow (WIB)
+var zakatNumberZone = time.FixedZone("WIB", 7*60*60)
+
+// zakatCounterTTL keeps a month's counter past the end of the month
+const zakatCounterTTL = 62 * 24 * time.Hour
+
+// ZakatSequence allocates zakat numbers from per-organization, per-month Redis counters, so
+// every replica draws from the same sequence. A counter missing from Redis (first zakat of the
+// month, or Redis lost its data) is seeded with the highest number in donations, so numbers
+// are never handed out twice; the chaincode rejects a zakat ID it already has as a last resort.
+type ZakatSequence struct {
+	redis       *redis.Client
+	db          *gorm.DB
+	idGenerator *IDGeneratorService
+}
+
+// NewZakatSequence creates a new zakat sequence
+func NewZakatSequence(redis *redis.Client, db *gorm.DB) *ZakatSequence {
+	return &ZakatSequence{
+		redis:       redis,
+		db:          db,
+		idGenerator: NewIDGeneratorService(),
+	}
+}
+
+// NextZakatID allocates the next zakat ID of an organization for the current month
+func (s *ZakatSequence) NextZakatID(organization string) (string, error) {
+	month := time.Now().In(zakatNumberZone)
+	prefix := s.idGenerator.NumberedZakatIDPrefix(organization, month)
+	key := "zakat_seq:" + prefix
+
+	exists, err := s.redis.Exists(database.Ctx, key).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to check zakat counter: %w", err)
+	}
+	if exists == 0 {
+		highest, err := s.highestNumber(prefix)
+		if err != nil {
+			return "", err
+		}
+		// Only the first replica to get here seeds the counter
+		if err := s.redis.SetNX(database.Ctx, key, highest, zakatCounterTTL).Err(); err != nil {
+			return "", fmt.Errorf("failed to seed zakat counter: %w", err)
+		}
+	}
+
+	number, err := s.redis.Incr(database.Ctx, key).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to allocate zakat number: %w", err)
+	}
+	return s.idGenerator.GenerateNumberedZakatID(organization, month, number), nil
+}
+
+// highestNumber returns the highest number in donations under a zakat ID prefix, 0 for none
+func (s *ZakatSequence) highestNumber(prefix string) (int64, error) {
+	var highest int64
+	err := s.db.Raw(`
+SELECT COALESCE(MAX(CAST(SUBSTRING(id FROM ?) AS BIGINT)), 0)
+FROM donations
+WHERE id LIKE ? AND SUBSTRING(id FROM ?) ~ '^[0-9]+$'`,
+		len(prefix)+1, prefix+"%", len(prefix)+1).Scan(&highest).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to find highest zakat number: %w", err)
+	}
+	return highest, nil
+}
@@ -84,6 +84,21 @@ func GetContract(cfg FabricConfig) *gateway.Contract {
 	return contract
 }
 
+// GetSystemContract returns a system chaincode (e.g. "qscc") on the configured channel
+func GetSystemContract(cfg FabricConfig, name string) *gateway.Contract {
+	if GW == nil {
+		log.Fatal("Fabric gateway not initialized. Call NewClient first.")
+	}
+
+	network, err := GW.GetNetwork(cfg.Channel)
+	if err != nil {
+		log.Fatalf("Failed to get network '%s': %v", cfg.Channel, err)
+	}
+
+	log.Printf("Retrieved system contract '%s' from channel '%s'", name, cfg.Channel)
+	return network.GetContract(name)
+}
+
 // Close closes the Fabric gateway connection
 func Close() {
 	if GW != nil {
@@ -0,0 +1,6 @@
+-- Anonymous ("Hamba Allah") donations
+-- The ledger only stores the display alias; the real donor identity stays in this table.
+
+ALTER TABLE donations
+    ADD COLUMN is_anonymous BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN display_name VARCHAR(255);
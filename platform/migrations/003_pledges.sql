@@ -0,0 +1,36 @@
+-- Recurring zakat pledges
+-- A pledge commits a donor to a fixed amount per period; the backend creates a pending
+-- donation for every due period and links it back through donations.pledge_id.
+
+CREATE TABLE pledges (
+    id VARCHAR(100) PRIMARY KEY, -- PLG-YDSF-MLG-{TIMESTAMP}-0001
+    donor_name VARCHAR(255) NOT NULL,
+    donor_phone VARCHAR(20) NOT NULL,
+    donor_email VARCHAR(255),
+    amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+    type VARCHAR(20) NOT NULL CHECK (type IN ('fitrah', 'maal')),
+    schedule VARCHAR(20) NOT NULL CHECK (schedule IN ('monthly', 'yearly', 'ramadan')),
+    program_id VARCHAR(100) REFERENCES programs(id) ON DELETE SET NULL,
+    referral_code VARCHAR(50),
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'cancelled')),
+    start_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    next_due_at TIMESTAMP WITH TIME ZONE, -- NULL when no further period can be scheduled
+    scheduled_count INTEGER NOT NULL DEFAULT 0,
+    fulfilled_count INTEGER NOT NULL DEFAULT 0,
+    total_fulfilled DECIMAL(15,2) NOT NULL DEFAULT 0.00,
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    cancel_reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE donations
+    ADD COLUMN pledge_id VARCHAR(100) REFERENCES pledges(id) ON DELETE SET NULL,
+    ADD COLUMN pledge_period VARCHAR(20); -- 2025-03 (monthly), 2025 (yearly), 1446H (ramadan)
+
+CREATE INDEX idx_pledges_status_next_due_at ON pledges(status, next_due_at);
+CREATE INDEX idx_pledges_donor_phone ON pledges(donor_phone);
+CREATE UNIQUE INDEX idx_donations_pledge_period ON donations(pledge_id, pledge_period) WHERE pledge_id IS NOT NULL;
+
+CREATE TRIGGER update_pledges_updated_at BEFORE UPDATE ON pledges
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,10 @@
+-- Zakat fitrah per-soul details
+-- The amount is checked on the ledger against the yearly per-soul rate; the names are
+-- kept here for receipts even when an anonymous donation leaves them off the ledger.
+
+ALTER TABLE donations
+    ADD COLUMN souls INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN soul_names JSONB;
+
+ALTER TABLE donations
+    ADD CONSTRAINT donations_souls_fitrah_only CHECK (souls = 0 OR type = 'fitrah');
@@ -0,0 +1,14 @@
+-- Donations in foreign currencies or in gold/silver
+-- amount stays the IDR equivalent used by every total; the original denomination and the
+-- exchange rate applied (published on the ledger) are kept alongside it.
+
+ALTER TABLE donations
+    ADD COLUMN denomination VARCHAR(10) NOT NULL DEFAULT 'IDR',
+    ADD COLUMN original_amount DECIMAL(20,4),
+    ADD COLUMN exchange_rate DECIMAL(20,4) NOT NULL DEFAULT 1;
+
+UPDATE donations SET original_amount = amount WHERE original_amount IS NULL;
+
+ALTER TABLE donations ALTER COLUMN original_amount SET NOT NULL;
+
+CREATE INDEX idx_donations_denomination ON donations(denomination);
@@ -0,0 +1,34 @@
+-- Distributions paid from a program's pooled funds
+-- The ledger allocates each program distribution to the program's collected donations oldest
+-- first; every allocation is mirrored as a distributions row so donors can see where their
+-- zakat went. A donation is distributed once its whole amount has been allocated.
+
+CREATE TABLE program_distributions (
+    id VARCHAR(100) PRIMARY KEY,
+    program_id VARCHAR(100) NOT NULL REFERENCES programs(id),
+    recipient_name VARCHAR(255) NOT NULL,
+    recipient_details JSONB,
+    amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+    distribution_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    distributed_by VARCHAR(255) NOT NULL,
+    blockchain_tx_id VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE distributions
+    ADD COLUMN program_distribution_id VARCHAR(100) REFERENCES program_distributions(id) ON DELETE CASCADE;
+
+ALTER TABLE donations
+    ADD COLUMN distributed_amount DECIMAL(15,2) NOT NULL DEFAULT 0;
+
+ALTER TABLE programs
+    ADD COLUMN distributed_amount DECIMAL(15,2) NOT NULL DEFAULT 0;
+
+UPDATE donations d
+SET distributed_amount = COALESCE((SELECT SUM(amount) FROM distributions WHERE donation_id = d.id), 0);
+
+UPDATE programs p
+SET distributed_amount = COALESCE((SELECT SUM(distributed_amount) FROM donations WHERE program_id = p.id), 0);
+
+CREATE INDEX idx_program_distributions_program_id ON program_distributions(program_id);
+CREATE INDEX idx_distributions_program_distribution_id ON distributions(program_distribution_id);
@@ -0,0 +1,8 @@
+-- Payment gateway charges
+-- Each donation is opened as a charge with the payment gateway (Midtrans, or cmd/mockpayment in
+-- development) under its donation ID. The gateway's signed notification validates the donation
+-- and its transaction ID becomes the payment reference.
+
+ALTER TABLE donations
+    ADD COLUMN payment_provider VARCHAR(50),
+    ADD COLUMN payment_url TEXT;
@@ -0,0 +1,7 @@
+-- QRIS payments
+-- Donations record how they are paid. QRIS donations carry a dynamic QRIS payload derived from
+-- the merchant's static code, with the donation amount and the donation ID as reference label.
+
+ALTER TABLE donations
+    ADD COLUMN payment_method VARCHAR(20) NOT NULL DEFAULT 'transfer',
+    ADD COLUMN qris_payload TEXT;
@@ -0,0 +1,30 @@
+-- Ledger outbox
+-- Donation changes that must reach the ledger are written here in the same transaction as the
+-- donation itself, then submitted to Fabric right away and retried by a worker until they
+-- succeed. Submissions the ledger rejects are compensated; ones that keep failing are marked
+-- failed and their donation's sync_status set to 'error' for an operator to retry.
+
+CREATE TABLE outbox_entries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    aggregate_type VARCHAR(50) NOT NULL,
+    aggregate_id VARCHAR(100) NOT NULL,
+    operation VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'done', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_outbox_entries_due ON outbox_entries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_outbox_entries_status ON outbox_entries(status);
+CREATE INDEX idx_outbox_entries_aggregate ON outbox_entries(aggregate_type, aggregate_id);
+
+-- Retried create requests find their donation by the caller's idempotency key
+ALTER TABLE donations
+    ADD COLUMN request_key VARCHAR(150);
+
+CREATE UNIQUE INDEX idx_donations_request_key ON donations(request_key);
@@ -0,0 +1,38 @@
+-- Ledger block indexer
+-- Committed blocks of the zakat chaincode are read in order and their write sets upserted into
+-- donations, programs, officers and distributions, so ledger changes made outside this API
+-- (auto-validations, CLI transactions, other organizations) reach Postgres too. Indexed rows
+-- record the block and transaction of the last ledger write applied to them; older writes are
+-- never applied over newer ones, so blocks can be indexed again safely.
+
+CREATE TABLE officers (
+    id VARCHAR(100) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    referral_code VARCHAR(50) NOT NULL,
+    total_referred DECIMAL(15,2) NOT NULL DEFAULT 0,
+    commission_rate DECIMAL(5,2) NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL,
+    phone VARCHAR(20),
+    email VARCHAR(255),
+    ledger_block BIGINT,
+    ledger_tx_id VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_officers_referral_code ON officers(referral_code);
+
+ALTER TABLE donations
+    ADD COLUMN ledger_block BIGINT,
+    ADD COLUMN ledger_tx_id VARCHAR(255);
+
+ALTER TABLE programs
+    ADD COLUMN ledger_block BIGINT,
+    ADD COLUMN ledger_tx_id VARCHAR(255);
+
+-- Next block to index, per channel and chaincode
+CREATE TABLE indexer_checkpoints (
+    name VARCHAR(255) PRIMARY KEY,
+    next_block BIGINT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,26 @@
+-- Ledger reconciliation
+-- Each run pages through the zakat on the ledger and the donations table and records the
+-- mismatches it found, counted per category. Donations whose status was behind the ledger are
+-- moved forward by the run (fixed_count); other mismatches are left for an operator. Only the
+-- first 1000 mismatches of a run are kept in mismatch_details.
+
+CREATE TABLE reconciliation_runs (
+    id UUID PRIMARY KEY,
+    trigger VARCHAR(20) NOT NULL,
+    triggered_by VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    ledger_count INTEGER NOT NULL DEFAULT 0,
+    database_count INTEGER NOT NULL DEFAULT 0,
+    missing_in_db INTEGER NOT NULL DEFAULT 0,
+    missing_on_ledger INTEGER NOT NULL DEFAULT 0,
+    status_mismatches INTEGER NOT NULL DEFAULT 0,
+    amount_mismatches INTEGER NOT NULL DEFAULT 0,
+    distributed_mismatches INTEGER NOT NULL DEFAULT 0,
+    fixed_count INTEGER NOT NULL DEFAULT 0,
+    mismatch_details JSONB NOT NULL DEFAULT '[]',
+    error TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_reconciliation_runs_started_at ON reconciliation_runs(started_at DESC);
@@ -0,0 +1,11 @@
+-- Donation organization
+-- Donations are made to one organization (branch), whose code is part of the zakat ID and
+-- which decides the payment methods accepted. Existing donations are assigned by their ID.
+
+ALTER TABLE donations
+    ADD COLUMN organization VARCHAR(100) NOT NULL DEFAULT 'YDSF Malang';
+
+UPDATE donations SET organization = 'YDSF Jatim' WHERE id LIKE 'ZKT-YDSF-JTM-%';
+
+CREATE INDEX idx_donations_organization ON donations(organization, created_at DESC);
+CREATE INDEX idx_donations_payment_method ON donations(payment_method);
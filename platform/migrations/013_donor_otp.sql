@@ -0,0 +1,9 @@
+-- Donor OTP sign-in
+-- Donors sign in with a one-time code sent to their phone or email and are registered on first
+-- sign-in, so a donor signed in by email may have no phone number. Each email address has at
+-- most one donor account.
+
+ALTER TABLE users ALTER COLUMN phone DROP NOT NULL;
+
+CREATE UNIQUE INDEX idx_users_donor_email ON users(LOWER(email))
+    WHERE role = 'donor' AND email IS NOT NULL;
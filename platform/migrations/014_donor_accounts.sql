@@ -0,0 +1,21 @@
+-- Donor accounts
+-- Donations made while signed in belong to the donor's account (donor_id). Guest donations are
+-- matched to an account by phone number, normalized to +62... the way OTP sign-in normalizes
+-- it, or by email address, and can then be claimed by the donor.
+
+ALTER TABLE donations ADD COLUMN donor_phone_normalized VARCHAR(20);
+
+UPDATE donations SET donor_phone_normalized = normalized
+FROM (
+    SELECT id AS donation_id,
+           CASE
+               WHEN phone LIKE '0%' THEN '+62' || SUBSTRING(phone FROM 2)
+               WHEN phone LIKE '62%' THEN '+' || phone
+               ELSE phone
+           END AS normalized
+    FROM (SELECT id, REGEXP_REPLACE(donor_phone, '[ ().-]', '', 'g') AS phone FROM donations) stripped
+) phones
+WHERE donations.id = phones.donation_id AND normalized ~ '^\+[1-9][0-9]{7,14}$';
+
+CREATE INDEX idx_donations_donor_phone_normalized ON donations(donor_phone_normalized) WHERE donor_id IS NULL;
+CREATE INDEX idx_donations_donor_email ON donations(LOWER(donor_email)) WHERE donor_id IS NULL;
@@ -0,0 +1,8 @@
+-- Pledge donors
+-- Pledges made while signed in belong to the donor's account (donor_id). Cancelling a pledge
+-- needs the donor's token, or an admin's; guest pledges are the donor's whose verified phone
+-- number or email address they were made with.
+
+ALTER TABLE pledges ADD COLUMN donor_id UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_pledges_donor_id ON pledges(donor_id);
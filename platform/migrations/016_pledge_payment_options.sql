@@ -0,0 +1,7 @@
+-- Pledge organization and payment method
+-- A pledge is made to one organization, and every donation it schedules is paid with the same
+-- payment method. Existing pledges were all made to YDSF Malang with its default method.
+
+ALTER TABLE pledges
+    ADD COLUMN organization VARCHAR(100) NOT NULL DEFAULT 'YDSF Malang',
+    ADD COLUMN payment_method VARCHAR(20) NOT NULL DEFAULT 'qris';
@@ -0,0 +1,7 @@
+-- Anonymous ("Hamba Allah") pledges
+-- As with donations, the ledger and every scheduled donation only carry the display alias; the
+-- real donor identity stays in this table.
+
+ALTER TABLE pledges
+    ADD COLUMN is_anonymous BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN display_name VARCHAR(255);
@@ -0,0 +1,113 @@
+#!/bin/bash
+# Script 22b: Package Chaincode for chaincode-as-a-service (external builder)
+# Alternative to script 22. The package only holds connection details; the chaincode
+# runs as its own service (see chaincode/zakat/Dockerfile) instead of being built by the peers.
+
+set -e # Exit on error
+
+echo "🚀 Packaging Chaincode-as-a-Service..."
+
+# Define paths and variables
+CHAINCODE_NAME="zakat"
+CHAINCODE_VERSION="2.1"
+CHAINCODE_LABEL="${CHAINCODE_NAME}_${CHAINCODE_VERSION}" # Must match scripts 23-26
+# Address the peers use to reach the chaincode service
+CHAINCODE_ADDRESS="${CHAINCODE_ADDRESS:-zakat-ccaas.fabriczakat.local:9999}"
+# TLS between peer and chaincode service (set CHAINCODE_TLS_REQUIRED=false for local debugging)
+CHAINCODE_TLS_REQUIRED="${CHAINCODE_TLS_REQUIRED:-true}"
+# CA that signed the chaincode service's TLS certificate
+CHAINCODE_TLS_ROOT_CERT="${CHAINCODE_TLS_ROOT_CERT:-$HOME/fabric/fabric-ca-client/tls-root-cert/tls-ca-cert.pem}"
+# Client key pair the peer presents when the service sets CHAINCODE_CLIENT_CA_CERT (optional)
+CHAINCODE_CLIENT_KEY="${CHAINCODE_CLIENT_KEY:-}"
+CHAINCODE_CLIENT_CERT="${CHAINCODE_CLIENT_CERT:-}"
+# Output
+CHAINCODE_PACKAGE_DIR="$HOME/fabric/chaincode-packages"
+CHAINCODE_PACKAGE_FILE="${CHAINCODE_PACKAGE_DIR}/${CHAINCODE_LABEL}.tar.gz"
+BUILD_DIR="${CHAINCODE_PACKAGE_DIR}/${CHAINCODE_LABEL}-ccaas"
+
+# Log file
+LOG_DIR="$HOME/fabric/logs"
+LOG_FILE="$LOG_DIR/22b-package-chaincode-ccaas.log"
+mkdir -p $LOG_DIR $CHAINCODE_PACKAGE_DIR
+touch $LOG_FILE
+
+# Function to log messages
+log() {
+    echo "$(date '+%Y-%m-%d %H:%M:%S') - $1" | tee -a $LOG_FILE
+}
+
+# Print a PEM file as a single JSON string value
+pem_json() {
+    awk '{printf "%s\\n", $0}' "$1"
+}
+
+log "Starting Chaincode-as-a-Service Packaging Script (22b)"
+
+CLIENT_AUTH_REQUIRED="false"
+if [ "$CHAINCODE_TLS_REQUIRED" == "true" ]; then
+    log "🔎 Checking TLS root certificate: $CHAINCODE_TLS_ROOT_CERT"
+    if [ ! -f "$CHAINCODE_TLS_ROOT_CERT" ]; then
+        log "⛔ Error: TLS root certificate not found at $CHAINCODE_TLS_ROOT_CERT"
+        exit 1
+    fi
+    if [ -n "$CHAINCODE_CLIENT_KEY" ] || [ -n "$CHAINCODE_CLIENT_CERT" ]; then
+        if [ ! -f "$CHAINCODE_CLIENT_KEY" ] || [ ! -f "$CHAINCODE_CLIENT_CERT" ]; then
+            log "⛔ Error: CHAINCODE_CLIENT_KEY and CHAINCODE_CLIENT_CERT must both point to existing files."
+            exit 1
+        fi
+        CLIENT_AUTH_REQUIRED="true"
+    fi
+    log "✅ TLS material found (client auth: $CLIENT_AUTH_REQUIRED)."
+fi
+
+rm -rf "$BUILD_DIR"
+mkdir -p "$BUILD_DIR/src"
+
+# connection.json tells the peer's ccaas builder where the chaincode service listens
+log "📝 Generating connection.json for $CHAINCODE_ADDRESS..."
+{
+    echo "{"
+    echo "  \"address\": \"$CHAINCODE_ADDRESS\","
+    echo "  \"dial_timeout\": \"10s\","
+    echo "  \"tls_required\": $CHAINCODE_TLS_REQUIRED,"
+    echo "  \"client_auth_required\": $CLIENT_AUTH_REQUIRED"
+    if [ "$CHAINCODE_TLS_REQUIRED" == "true" ]; then
+        echo "  ,\"root_cert\": \"$(pem_json "$CHAINCODE_TLS_ROOT_CERT")\""
+    fi
+    if [ "$CLIENT_AUTH_REQUIRED" == "true" ]; then
+        echo "  ,\"client_key\": \"$(pem_json "$CHAINCODE_CLIENT_KEY")\""
+        echo "  ,\"client_cert\": \"$(pem_json "$CHAINCODE_CLIENT_CERT")\""
+    fi
+    echo "}"
+} > "$BUILD_DIR/src/connection.json"
+
+# metadata.json selects the ccaas external builder
+log "📝 Generating metadata.json..."
+cat > "$BUILD_DIR/metadata.json" <<METADATA
+{
+  "type": "ccaas",
+  "label": "$CHAINCODE_LABEL"
+}
+METADATA
+
+log "📦 Packaging chaincode '$CHAINCODE_LABEL' as $CHAINCODE_PACKAGE_FILE..."
+tar -C "$BUILD_DIR/src" -czf "$BUILD_DIR/code.tar.gz" connection.json >> $LOG_FILE 2>&1
+tar -C "$BUILD_DIR" -czf "$CHAINCODE_PACKAGE_FILE" metadata.json code.tar.gz >> $LOG_FILE 2>&1
+
+if [ ! -f "$CHAINCODE_PACKAGE_FILE" ]; then
+    log "⛔ Error: Chaincode package file was not created at $CHAINCODE_PACKAGE_FILE."
+    exit 1
+fi
+ls -l "$CHAINCODE_PACKAGE_FILE" >> $LOG_FILE # Log file details
+log "✅ Chaincode packaged successfully: $CHAINCODE_PACKAGE_FILE"
+
+if command -v peer &> /dev/null; then
+    PACKAGE_ID=$(peer lifecycle chaincode calculatepackageid "$CHAINCODE_PACKAGE_FILE")
+    log "🆔 Package ID: $PACKAGE_ID"
+    log "   Start the chaincode service with CHAINCODE_ID=$PACKAGE_ID"
+fi
+
+log "🎉 Chaincode-as-a-Service packaging complete! Continue with scripts 23-26."
+log "----------------------------------------"
+
+exit 0